@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/app"
+	"codeberg.org/snonux/yoga/internal/config"
+)
+
+var diagnose = app.Diagnose
+
+// runDoctor implements `yoga doctor`. It exits with 1 when a check failed,
+// so scripts can rely on the status; warnings alone exit with 0.
+func runDoctor(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yoga doctor", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Directory containing yoga videos, optionally as label=path; repeat for several roots (default ~/Yoga)")
+	playerFlag := fs.String("player", "", "Player backend to check: vlc, mpv or a command template")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+	var findings []app.Finding
+	cfg, err := loadConfig()
+	if err != nil {
+		// Check the rest with the defaults.
+		cfg = config.Config{}
+		findings = append(findings, app.Finding{
+			Level:   app.FindingFail,
+			Check:   "config",
+			Message: err.Error(),
+			Hint:    "fix the JSON or delete the file; the checks below use the defaults",
+		})
+	}
+	roots, err := configuredRoots(rootFlags, cfg)
+	if err != nil {
+		findings = append(findings, app.Finding{
+			Level:   app.FindingFail,
+			Check:   "root",
+			Message: err.Error(),
+			Hint:    "create the directory, or pass an existing one with --root",
+		})
+		app.WriteFindings(stdout, findings)
+		return 1
+	}
+	opts := app.Options{Root: roots[0].Path, Roots: roots, Player: cfg.Player}
+	if *playerFlag != "" {
+		opts.Player = *playerFlag
+	}
+	findings = append(findings, diagnose(opts)...)
+	if app.WriteFindings(stdout, findings) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/app"
+)
+
+var listApp = app.List
+
+// runList implements `yoga list`, which prints the library without the TUI.
+func runList(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yoga list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Directory containing yoga videos, optionally as label=path; repeat for several roots (default ~/Yoga)")
+	formatFlag := fs.String("format", app.FormatTable, "Output format: table, json (one object per line) or csv")
+	columnsFlag := fs.String("columns", "", "Comma-separated table columns, as for the TUI")
+	var sel app.Selection
+	addSelectionFlags(fs, &sel)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+	format := strings.ToLower(*formatFlag)
+	if format != app.FormatTable && format != app.FormatJSON && format != app.FormatCSV {
+		fmt.Fprintf(stderr, "unknown format %q (use table, json or csv)\n", *formatFlag)
+		return 2
+	}
+	opts, err := headlessOptions(rootFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	list := app.ListOptions{Selection: sel, Format: format}
+	if *columnsFlag != "" {
+		list.Columns = strings.Split(*columnsFlag, ",")
+	}
+	if err := listApp(opts, list, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// addSelectionFlags registers the filter and sort flags shared by the
+// subcommands.
+func addSelectionFlags(fs *flag.FlagSet, sel *app.Selection) {
+	fs.StringVar(&sel.Query, "query", "", "Search query as typed into the search bar (e.g. 'tag:hips len:<30')")
+	fs.StringVar(&sel.Filter, "filter", "", "Start from the named saved filter")
+	fs.StringVar(&sel.Name, "name", "", "Only videos whose name matches")
+	fs.StringVar(&sel.Tags, "tag", "", "Tag expression (e.g. 'hips and not advanced')")
+	fs.IntVar(&sel.MinMinutes, "min", 0, "Minimum length in minutes")
+	fs.IntVar(&sel.MaxMinutes, "max", 0, "Maximum length in minutes")
+	fs.IntVar(&sel.NotPlayedDays, "not-played", 0, "Only videos not played in the last N days")
+	fs.StringVar(&sel.Height, "height", "", "Minimum vertical resolution (e.g. 1080p)")
+	fs.StringVar(&sel.Audio, "audio", "", "Audio language (e.g. en)")
+	fs.StringVar(&sel.Source, "source", "", "Library root label")
+	fs.StringVar(&sel.Sort, "sort", "name", "Sort by name, length, age, played, rating or match")
+	fs.BoolVar(&sel.Reverse, "reverse", false, "Reverse the sort order")
+}
+
+// headlessOptions resolves the library roots and the config for the
+// subcommands.
+func headlessOptions(rootFlags rootList) (app.Options, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return app.Options{}, err
+	}
+	roots, err := configuredRoots(rootFlags, cfg)
+	if err != nil {
+		return app.Options{}, err
+	}
+	return app.Options{
+		Root:           roots[0].Path,
+		Roots:          roots,
+		Player:         cfg.Player,
+		Fullscreen:     cfg.Fullscreen,
+		Volume:         cfg.Volume,
+		Columns:        cfg.Columns,
+		WeightedRandom: cfg.WeightedRandom,
+	}, nil
+}
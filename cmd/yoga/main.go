@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"codeberg.org/snonux/yoga/internal/app"
+	"codeberg.org/snonux/yoga/internal/config"
 	"codeberg.org/snonux/yoga/internal/fsutil"
 	"codeberg.org/snonux/yoga/internal/meta"
 )
@@ -15,8 +16,9 @@ import (
 const defaultRoot = "~/Yoga"
 
 var (
-	runApp = app.Run
-	exit   = os.Exit
+	runApp     = app.Run
+	exit       = os.Exit
+	configPath = config.DefaultPath
 )
 
 func main() {
@@ -24,10 +26,31 @@ func main() {
 }
 
 func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) > 0 {
+		switch args[0] {
+		case "list":
+			return runList(args[1:], stdout, stderr)
+		case "play":
+			return runPlay(args[1:], stdout, stderr)
+		case "tag":
+			return runTag(args[1:], stdout, stderr)
+		case "doctor":
+			return runDoctor(args[1:], stdout, stderr)
+		case "stats":
+			return runStats(args[1:], stdout, stderr)
+		}
+	}
 	fs := flag.NewFlagSet("yoga", flag.ContinueOnError)
 	fs.SetOutput(stderr)
-	rootFlag := fs.String("root", "", "Directory containing yoga videos (default ~/Yoga)")
-	cropFlag := fs.String("crop", "", "Optional crop aspect for VLC (e.g. 5:4)")
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Directory containing yoga videos, optionally as label=path; repeat for several roots (default ~/Yoga)")
+	cropFlag := fs.String("crop", "", "Optional crop aspect passed to the player (e.g. 5:4)")
+	playerFlag := fs.String("player", "", "Player backend: vlc, mpv or a command template such as 'celluloid {path}' (default vlc)")
+	fullscreenFlag := fs.Bool("fullscreen", false, "Start playback in fullscreen")
+	volumeFlag := fs.Int("volume", 0, "Initial playback volume in percent (0 keeps the player default)")
+	columnsFlag := fs.String("columns", "", "Comma-separated table columns: name, duration, age, rating, played, tags, resolution, codecs, audio, bitrate, source")
+	weightedFlag := fs.Bool("weighted-random", false, "Weight the random pick (x) by rating")
+	filterFlag := fs.String("filter", "", "Start with the named saved filter applied")
 	versionFlag := fs.Bool("version", false, "Print version and exit")
 	if err := fs.Parse(args); err != nil {
 		return 2
@@ -36,15 +59,120 @@ func run(args []string, stdout, stderr io.Writer) int {
 		fmt.Fprintf(stdout, "Yoga version %s\n", meta.Version)
 		return 0
 	}
-	root, err := fsutil.ResolveRootPath(*rootFlag, defaultRoot)
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	roots, err := configuredRoots(rootFlags, cfg)
 	if err != nil {
 		fmt.Fprintf(stderr, "%v\n", err)
 		return 1
 	}
-	opts := app.Options{Root: root, Crop: strings.TrimSpace(*cropFlag)}
+	opts := app.Options{
+		Root:           roots[0].Path,
+		Roots:          roots,
+		Crop:           strings.TrimSpace(*cropFlag),
+		Player:         cfg.Player,
+		Fullscreen:     cfg.Fullscreen,
+		Volume:         cfg.Volume,
+		Columns:        cfg.Columns,
+		Filter:         strings.TrimSpace(*filterFlag),
+		WeightedRandom: cfg.WeightedRandom,
+	}
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "player":
+			opts.Player = *playerFlag
+		case "fullscreen":
+			opts.Fullscreen = *fullscreenFlag
+		case "volume":
+			opts.Volume = *volumeFlag
+		case "columns":
+			opts.Columns = strings.Split(*columnsFlag, ",")
+		case "weighted-random":
+			opts.WeightedRandom = *weightedFlag
+		}
+	})
+	if opts.Volume < 0 {
+		fmt.Fprintf(stderr, "volume must not be negative: %d\n", opts.Volume)
+		return 2
+	}
 	if err := runApp(opts); err != nil {
 		fmt.Fprintf(stderr, "error: %v\n", err)
 		return 1
 	}
 	return 0
 }
+
+func loadConfig() (config.Config, error) {
+	path, err := configPath()
+	if err != nil {
+		// Without a config directory there is no config file to honour.
+		return config.Config{}, nil
+	}
+	return config.Load(path)
+}
+
+// configuredRoots resolves the --root flags, falling back to the roots of
+// the config file.
+func configuredRoots(flags rootList, cfg config.Config) ([]app.Root, error) {
+	if len(flags) == 0 {
+		for _, root := range cfg.Roots {
+			flags = append(flags, app.Root{Label: root.Label, Path: root.Path})
+		}
+	}
+	return resolveRoots(flags)
+}
+
+// rootList collects repeated --root flags. A value of the form label=path
+// names the root; the label must not contain a path separator.
+type rootList []app.Root
+
+func (r *rootList) String() string {
+	if r == nil {
+		return ""
+	}
+	paths := make([]string, 0, len(*r))
+	for _, root := range *r {
+		paths = append(paths, root.Path)
+	}
+	return strings.Join(paths, ",")
+}
+
+func (r *rootList) Set(value string) error {
+	*r = append(*r, parseRoot(value))
+	return nil
+}
+
+func parseRoot(value string) app.Root {
+	label, path, ok := strings.Cut(value, "=")
+	if !ok || label == "" || strings.ContainsAny(label, `/\~`) {
+		return app.Root{Path: value}
+	}
+	return app.Root{Label: strings.TrimSpace(label), Path: path}
+}
+
+// resolveRoots validates the library roots. The first root must exist (the
+// default is created on demand); further roots may be missing, such as an
+// unmounted network share, and are reported as unavailable by the app.
+func resolveRoots(roots []app.Root) ([]app.Root, error) {
+	if len(roots) == 0 {
+		roots = []app.Root{{}}
+	}
+	resolved := make([]app.Root, 0, len(roots))
+	for i, root := range roots {
+		var path string
+		var err error
+		if i == 0 {
+			path, err = fsutil.ResolveRootPath(root.Path, defaultRoot)
+		} else {
+			path, err = fsutil.AbsPath(root.Path)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, app.Root{Label: root.Label, Path: path})
+	}
+	return resolved, nil
+}
@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"codeberg.org/snonux/yoga/internal/app"
@@ -83,3 +85,299 @@ func TestMainUsesExit(t *testing.T) {
 		t.Fatalf("expected exit code 0, got %d", code)
 	}
 }
+
+func TestRunPlayerFlagsOverrideConfig(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"player": "mpv", "volume": 40, "fullscreen": true, "weighted_random": true}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	origConfig := configPath
+	configPath = func() (string, error) { return cfgPath, nil }
+	defer func() { configPath = origConfig }()
+	var got app.Options
+	origRun := runApp
+	runApp = func(opts app.Options) error {
+		got = opts
+		return nil
+	}
+	defer func() { runApp = origRun }()
+	code := run([]string{"--root", root, "--volume", "70"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if got.Player != "mpv" || !got.Fullscreen || got.Volume != 70 || !got.WeightedRandom {
+		t.Fatalf("unexpected options %+v", got)
+	}
+	code = run([]string{"--root", root, "--player", "vlc", "--fullscreen=false", "--weighted-random=false"}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if got.Player != "vlc" || got.Fullscreen || got.Volume != 40 || got.WeightedRandom {
+		t.Fatalf("expected flags to win over config, got %+v", got)
+	}
+}
+
+func TestRunRejectsInvalidConfig(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	origConfig := configPath
+	configPath = func() (string, error) { return cfgPath, nil }
+	defer func() { configPath = origConfig }()
+	code := run([]string{"--root", t.TempDir()}, &stdout, &stderr)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRunColumnsFlagOverridesConfig(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(cfgPath, []byte(`{"columns": ["name", "resolution"]}`), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	origConfig := configPath
+	configPath = func() (string, error) { return cfgPath, nil }
+	defer func() { configPath = origConfig }()
+	var got app.Options
+	origRun := runApp
+	runApp = func(opts app.Options) error {
+		got = opts
+		return nil
+	}
+	defer func() { runApp = origRun }()
+	root := t.TempDir()
+	if code := run([]string{"--root", root}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if len(got.Columns) != 2 || got.Columns[1] != "resolution" {
+		t.Fatalf("expected config columns, got %v", got.Columns)
+	}
+	if code := run([]string{"--root", root, "--columns", "name,audio,bitrate"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if len(got.Columns) != 3 || got.Columns[2] != "bitrate" {
+		t.Fatalf("expected flag columns, got %v", got.Columns)
+	}
+}
+
+func TestRunAcceptsSeveralLabelledRoots(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	home := t.TempDir()
+	nas := filepath.Join(t.TempDir(), "unmounted")
+	cfgPath := filepath.Join(t.TempDir(), "config.json")
+	cfg := `{"roots": [{"label": "cfg", "path": "` + home + `"}]}`
+	if err := os.WriteFile(cfgPath, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	origConfig := configPath
+	configPath = func() (string, error) { return cfgPath, nil }
+	defer func() { configPath = origConfig }()
+	var got app.Options
+	origRun := runApp
+	runApp = func(opts app.Options) error {
+		got = opts
+		return nil
+	}
+	defer func() { runApp = origRun }()
+	if code := run(nil, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if got.Root != home || len(got.Roots) != 1 || got.Roots[0].Label != "cfg" {
+		t.Fatalf("expected config roots, got %+v", got.Roots)
+	}
+	code := run([]string{"--root", "home=" + home, "--root", "nas=" + nas}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if got.Root != home || len(got.Roots) != 2 || got.Roots[1].Label != "nas" || got.Roots[1].Path != nas {
+		t.Fatalf("expected flag roots to win, got %+v", got.Roots)
+	}
+	if code := run([]string{"--root", nas}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected missing primary root to fail, got %d", code)
+	}
+}
+
+func TestRunPassesStartupFilter(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	var got app.Options
+	origRun := runApp
+	runApp = func(opts app.Options) error {
+		got = opts
+		return nil
+	}
+	defer func() { runApp = origRun }()
+	if code := run([]string{"--root", t.TempDir(), "--filter", "short mornings"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if got.Filter != "short mornings" {
+		t.Fatalf("expected filter option, got %q", got.Filter)
+	}
+}
+
+func TestRunListPassesSelection(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	var gotOpts app.Options
+	var gotList app.ListOptions
+	orig := listApp
+	listApp = func(opts app.Options, list app.ListOptions, stdout, stderr io.Writer) error {
+		gotOpts, gotList = opts, list
+		return nil
+	}
+	defer func() { listApp = orig }()
+	args := []string{"list", "--root", root, "--format", "JSON", "--tag", "hips", "--max", "30", "--sort", "length", "--reverse", "--columns", "name,tags"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if gotOpts.Root != root || gotList.Format != app.FormatJSON || gotList.Tags != "hips" || gotList.MaxMinutes != 30 {
+		t.Fatalf("unexpected options %+v %+v", gotOpts, gotList)
+	}
+	if gotList.Sort != "length" || !gotList.Reverse || len(gotList.Columns) != 2 {
+		t.Fatalf("unexpected sort or columns %+v", gotList)
+	}
+	if code := run([]string{"list", "--root", root, "--format", "xml"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2 for unknown format, got %d", code)
+	}
+	listApp = func(app.Options, app.ListOptions, io.Writer, io.Writer) error { return errors.New("boom") }
+	if code := run([]string{"list", "--root", root}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRunPlayPassesRequest(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	var gotOpts app.Options
+	var gotReq app.PlayRequest
+	orig := playApp
+	playApp = func(opts app.Options, req app.PlayRequest, stdout, stderr io.Writer) error {
+		gotOpts, gotReq = opts, req
+		return nil
+	}
+	defer func() { playApp = orig }()
+	args := []string{"play", "--root", root, "--random", "--tag", "flow", "--min", "15", "--max", "25", "--player", "mpv", "sun", "salute"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if !gotReq.Random || gotReq.Tags != "flow" || gotReq.MinMinutes != 15 || gotReq.MaxMinutes != 25 || gotReq.Name != "sun salute" {
+		t.Fatalf("unexpected request %+v", gotReq)
+	}
+	if gotOpts.Root != root || gotOpts.Player != "mpv" {
+		t.Fatalf("unexpected options %+v", gotOpts)
+	}
+	gotReq = app.PlayRequest{}
+	if code := run([]string{"play", "--root", root, "hips", "--random", "--from-start"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if gotReq.Name != "hips" || !gotReq.Random || !gotReq.FromStart {
+		t.Fatalf("expected flags after the name to apply, got %+v", gotReq)
+	}
+	playApp = func(app.Options, app.PlayRequest, io.Writer, io.Writer) error { return errors.New("no video matches") }
+	if code := run([]string{"play", "--root", root}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRunTagParsesActionAndFlags(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	var got app.TagCommand
+	orig := tagApp
+	tagApp = func(opts app.Options, cmd app.TagCommand, stdout, stderr io.Writer) error {
+		got = cmd
+		return nil
+	}
+	defer func() { tagApp = orig }()
+	if code := run([]string{"tag", "--root", root, "add", "--dry-run", "new/*.mp4", "series-a", "hips"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if got.Action != app.TagAdd || got.Pattern != "new/*.mp4" || len(got.Tags) != 2 || !got.DryRun {
+		t.Fatalf("unexpected command %+v", got)
+	}
+	// Flags after the pattern are flags, not tags; "--" ends them.
+	got = app.TagCommand{}
+	if code := run([]string{"tag", "add", "*.mp4", "hips", "--dry-run", "--root", root, "--", "-odd"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if !got.DryRun || got.Pattern != "*.mp4" || strings.Join(got.Tags, ",") != "hips,-odd" {
+		t.Fatalf("expected a dry run adding hips and -odd, got %+v", got)
+	}
+	if code := run([]string{"tag", "add", "*.mp4", "hips", "--dry-rn"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2 for an unknown trailing flag, got %d", code)
+	}
+	for _, args := range [][]string{{"tag"}, {"tag", "rename", "x"}, {"tag", "show"}} {
+		if code := run(args, &stdout, &stderr); code != 2 {
+			t.Fatalf("expected exit code 2 for %v, got %d", args, code)
+		}
+	}
+	tagApp = func(app.Options, app.TagCommand, io.Writer, io.Writer) error {
+		return errors.New("1 of 2 files failed")
+	}
+	if code := run([]string{"tag", "--root", root, "show", "x.mp4"}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
+
+func TestRunDoctorExitCodes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	var got app.Options
+	orig := diagnose
+	diagnose = func(opts app.Options) []app.Finding {
+		got = opts
+		return []app.Finding{{Level: app.FindingWarn, Check: "ffprobe", Message: "not found in PATH"}}
+	}
+	defer func() { diagnose = orig }()
+	if code := run([]string{"doctor", "--root", root, "--player", "mpv"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected warnings to exit with 0, got %d", code)
+	}
+	if got.Root != root || got.Player != "mpv" {
+		t.Fatalf("unexpected options %+v", got)
+	}
+	diagnose = func(app.Options) []app.Finding {
+		return []app.Finding{{Level: app.FindingFail, Check: "player", Message: "vlc not found in PATH"}}
+	}
+	if code := run([]string{"doctor", "--root", root}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected a failed check to exit with 1, got %d", code)
+	}
+	stdout.Reset()
+	missing := filepath.Join(t.TempDir(), "missing")
+	if code := run([]string{"doctor", "--root", missing}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected a missing root to exit with 1, got %d", code)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("FAIL  root: root path does not exist")) {
+		t.Fatalf("expected the root finding, got %s", stdout.String())
+	}
+}
+
+func TestRunStatsPassesOptions(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	root := t.TempDir()
+	var gotOpts app.Options
+	var gotReq app.StatsOptions
+	orig := statsApp
+	statsApp = func(opts app.Options, req app.StatsOptions, stdout, stderr io.Writer) error {
+		gotOpts, gotReq = opts, req
+		return nil
+	}
+	defer func() { statsApp = orig }()
+	args := []string{"stats", "--root", root, "--format", "JSON", "--tag", "flow", "--filter", "morning"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("expected exit code 0, got %d (%s)", code, stderr.String())
+	}
+	if gotOpts.Root != root || gotReq.Format != app.FormatJSON || gotReq.Tags != "flow" || gotReq.Filter != "morning" {
+		t.Fatalf("unexpected options %+v %+v", gotOpts, gotReq)
+	}
+	if code := run([]string{"stats", "--root", root, "--format", "csv"}, &stdout, &stderr); code != 2 {
+		t.Fatalf("expected exit code 2 for unknown format, got %d", code)
+	}
+	statsApp = func(app.Options, app.StatsOptions, io.Writer, io.Writer) error { return errors.New("boom") }
+	if code := run([]string{"stats", "--root", root}, &stdout, &stderr); code != 1 {
+		t.Fatalf("expected exit code 1, got %d", code)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/app"
+)
+
+var playApp = app.Play
+
+// runPlay implements `yoga play`, which launches a matching video without
+// the TUI. Positional arguments match the video name; flags may come on
+// either side of them.
+func runPlay(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yoga play", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Directory containing yoga videos, optionally as label=path; repeat for several roots (default ~/Yoga)")
+	randomFlag := fs.Bool("random", false, "Play a random matching video instead of the first one")
+	fromStartFlag := fs.Bool("from-start", false, "Ignore the stored resume position")
+	cropFlag := fs.String("crop", "", "Optional crop aspect passed to the player (e.g. 5:4)")
+	playerFlag := fs.String("player", "", "Player backend: vlc, mpv or a command template")
+	fullscreenFlag := fs.Bool("fullscreen", false, "Start playback in fullscreen")
+	volumeFlag := fs.Int("volume", 0, "Initial playback volume in percent (0 keeps the player default)")
+	weightedFlag := fs.Bool("weighted-random", false, "Weight the random pick by rating")
+	var req app.PlayRequest
+	addSelectionFlags(fs, &req.Selection)
+	positional, ok := parseInterspersed(fs, args)
+	if !ok {
+		return 2
+	}
+	if len(positional) > 0 {
+		req.Name = strings.TrimSpace(req.Name + " " + strings.Join(positional, " "))
+	}
+	req.Random = *randomFlag
+	req.FromStart = *fromStartFlag
+	opts, err := headlessOptions(rootFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	opts.Crop = strings.TrimSpace(*cropFlag)
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "player":
+			opts.Player = *playerFlag
+		case "fullscreen":
+			opts.Fullscreen = *fullscreenFlag
+		case "volume":
+			opts.Volume = *volumeFlag
+		case "weighted-random":
+			opts.WeightedRandom = *weightedFlag
+		}
+	})
+	if opts.Volume < 0 {
+		fmt.Fprintf(stderr, "volume must not be negative: %d\n", opts.Volume)
+		return 2
+	}
+	if err := playApp(opts, req, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/app"
+)
+
+var statsApp = app.Stats
+
+// runStats implements `yoga stats`, which reports library totals and
+// practice time.
+func runStats(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yoga stats", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Directory containing yoga videos, optionally as label=path; repeat for several roots (default ~/Yoga)")
+	formatFlag := fs.String("format", app.FormatText, "Output format: text or json")
+	var req app.StatsOptions
+	addSelectionFlags(fs, &req.Selection)
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "unexpected arguments: %s\n", strings.Join(fs.Args(), " "))
+		return 2
+	}
+	req.Format = strings.ToLower(*formatFlag)
+	if req.Format != app.FormatText && req.Format != app.FormatJSON {
+		fmt.Fprintf(stderr, "unknown format %q (use text or json)\n", *formatFlag)
+		return 2
+	}
+	opts, err := headlessOptions(rootFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	if err := statsApp(opts, req, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"codeberg.org/snonux/yoga/internal/app"
+)
+
+var tagApp = app.TagFiles
+
+// runTag implements `yoga tag add|remove|set|show <path-or-glob> [tags...]`.
+// Flags may come anywhere; after "--" every argument is positional.
+func runTag(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("yoga tag", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: yoga tag [--dry-run] [--root PATH] add|remove|set|show <path-or-glob> [tags...]")
+		fs.PrintDefaults()
+	}
+	var rootFlags rootList
+	fs.Var(&rootFlags, "root", "Library root whose tag policy applies (default ~/Yoga)")
+	dryRunFlag := fs.Bool("dry-run", false, "Print the changes without writing any sidecar")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	action := fs.Arg(0)
+	switch action {
+	case app.TagAdd, app.TagRemove, app.TagSet, app.TagShow:
+	default:
+		fmt.Fprintf(stderr, "unknown tag action %q\n", action)
+		fs.Usage()
+		return 2
+	}
+	positional, ok := parseInterspersed(fs, fs.Args()[1:])
+	if !ok {
+		return 2
+	}
+	if len(positional) == 0 {
+		fs.Usage()
+		return 2
+	}
+	cmd := app.TagCommand{Action: action, Pattern: positional[0], Tags: positional[1:], DryRun: *dryRunFlag}
+	opts, err := headlessOptions(rootFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "%v\n", err)
+		return 1
+	}
+	if err := tagApp(opts, cmd, stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// parseInterspersed parses the flags among args and returns the positional
+// arguments. The flag package stops at the first positional argument, so
+// parsing resumes after each one; a "--" ends the flags for good.
+func parseInterspersed(fs *flag.FlagSet, args []string) ([]string, bool) {
+	var positional []string
+	for len(args) > 0 {
+		if err := fs.Parse(args); err != nil {
+			return nil, false
+		}
+		rest := fs.Args()
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), true
+		}
+		if len(rest) == 0 {
+			break
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+	return positional, true
+}
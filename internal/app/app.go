@@ -16,12 +16,17 @@ var programFactory = func(m tea.Model) teaProgram {
 
 // Run bootstraps the Bubble Tea program with the provided options.
 func Run(opts Options) error {
-	model, err := newModel(opts)
+	m, err := newModel(opts)
 	if err != nil {
 		return fmt.Errorf("create model: %w", err)
 	}
-	program := programFactory(model)
-	if _, err := program.Run(); err != nil {
+	program := programFactory(m)
+	final, err := program.Run()
+	// The watcher is started from a command, so only the final model has it.
+	if last, ok := final.(model); ok {
+		_ = last.watcher.Close()
+	}
+	if err != nil {
 		return fmt.Errorf("run program: %w", err)
 	}
 	return nil
@@ -8,11 +8,12 @@ import (
 )
 
 type stubProgram struct {
-	err error
+	final tea.Model
+	err   error
 }
 
 func (s stubProgram) Run() (tea.Model, error) {
-	return nil, s.err
+	return s.final, s.err
 }
 
 func TestRunInvokesProgram(t *testing.T) {
@@ -36,3 +37,23 @@ func TestRunPropagatesError(t *testing.T) {
 		t.Fatalf("expected error propagation, got %v", err)
 	}
 }
+
+func TestRunClosesWatcher(t *testing.T) {
+	original := programFactory
+	defer func() { programFactory = original }()
+	closed := 0
+	programFactory = func(initial tea.Model) teaProgram {
+		m := initial.(model)
+		m.watcher = newLibraryWatcherChannels(func() error {
+			closed++
+			return nil
+		})
+		return stubProgram{final: m}
+	}
+	if err := Run(Options{Root: t.TempDir()}); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if closed != 1 {
+		t.Fatalf("expected the watcher to be closed once, got %d", closed)
+	}
+}
@@ -0,0 +1,257 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+type columnID int
+
+const (
+	colName columnID = iota
+	colDuration
+	colAge
+	colPlayed
+	colTags
+	colResolution
+	colCodecs
+	colAudio
+	colBitrate
+	colSource
+	colRating
+)
+
+type columnSpec struct {
+	title     string
+	preferred int
+	floor     int
+	cell      func(video) string
+}
+
+var columnSpecs = map[columnID]columnSpec{
+	colName:     {title: "Name", preferred: preferredNameColumnWidth, floor: nameColumnFloorWidth, cell: nameCell},
+	colDuration: {title: "Duration", preferred: preferredDurationColumnWidth, floor: durationColumnFloorWidth, cell: durationCell},
+	colAge:      {title: "Age", preferred: preferredAgeColumnWidth, floor: ageColumnFloorWidth, cell: ageCell},
+	colRating:   {title: "Rating", preferred: preferredRatingColumnWidth, floor: ratingColumnFloorWidth, cell: ratingCell},
+	colPlayed:   {title: "Played", preferred: preferredPlayedColumnWidth, floor: playedColumnFloorWidth, cell: playedCell},
+	colTags:     {title: "Tags", preferred: preferredTagsColumnWidth, floor: tagsColumnFloorWidth, cell: tagsCell},
+	// Optional media columns, enabled through Options.Columns.
+	colResolution: {title: "Resolution", preferred: preferredMediaColumnWidth, floor: mediaColumnFloorWidth, cell: resolutionCell},
+	colCodecs:     {title: "Codecs", preferred: preferredMediaColumnWidth, floor: mediaColumnFloorWidth, cell: codecsCell},
+	colAudio:      {title: "Audio", preferred: preferredMediaColumnWidth, floor: mediaColumnFloorWidth, cell: audioCell},
+	colBitrate:    {title: "Bitrate", preferred: preferredMediaColumnWidth, floor: mediaColumnFloorWidth, cell: bitrateCell},
+	colSource:     {title: "Source", preferred: preferredMediaColumnWidth, floor: mediaColumnFloorWidth, cell: sourceCell},
+}
+
+var defaultColumns = []columnID{colName, colDuration, colAge, colRating, colPlayed, colTags}
+
+// multiRootColumns are the defaults when more than one root is configured.
+var multiRootColumns = []columnID{colName, colSource, colDuration, colAge, colRating, colPlayed, colTags}
+
+// columnNames maps the names accepted by --columns to column IDs.
+var columnNames = map[string]columnID{
+	"name":       colName,
+	"duration":   colDuration,
+	"age":        colAge,
+	"rating":     colRating,
+	"played":     colPlayed,
+	"tags":       colTags,
+	"resolution": colResolution,
+	"codecs":     colCodecs,
+	"audio":      colAudio,
+	"bitrate":    colBitrate,
+	"source":     colSource,
+}
+
+// parseColumns resolves column names in display order. An empty list selects
+// the default columns.
+func parseColumns(names []string) ([]columnID, error) {
+	ids := make([]columnID, 0, len(names))
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		id, ok := columnNames[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		if columnIndex(ids, id) >= 0 {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	if len(ids) == 0 {
+		return defaultColumns, nil
+	}
+	return ids, nil
+}
+
+func makeColumns(ids []columnID, widths []int) []table.Column {
+	columns := make([]table.Column, 0, len(ids))
+	for i, id := range ids {
+		columns = append(columns, table.Column{Title: headerStyle.Render(columnSpecs[id].title), Width: widths[i]})
+	}
+	return columns
+}
+
+func preferredWidths(ids []columnID) []int {
+	widths := make([]int, len(ids))
+	for i, id := range ids {
+		widths[i] = columnSpecs[id].preferred
+	}
+	return widths
+}
+
+// fitColumnWidths distributes contentWidth across the columns. Extra space
+// goes to the name column; when space runs out the name column shrinks
+// first, then tags, then the remaining columns from right to left.
+func fitColumnWidths(ids []columnID, contentWidth int) []int {
+	widths := preferredWidths(ids)
+	preferred := 0
+	for _, w := range widths {
+		preferred += w
+	}
+	if contentWidth >= preferred {
+		if idx := columnIndex(ids, colName); idx >= 0 {
+			widths[idx] += contentWidth - preferred
+		}
+		return widths
+	}
+	deficit := preferred - contentWidth
+	for _, idx := range shrinkOrder(ids) {
+		if deficit <= 0 {
+			break
+		}
+		reduce := min(deficit, widths[idx]-columnSpecs[ids[idx]].floor)
+		widths[idx] -= reduce
+		deficit -= reduce
+	}
+	return widths
+}
+
+func shrinkOrder(ids []columnID) []int {
+	order := make([]int, 0, len(ids))
+	for _, first := range []columnID{colName, colTags} {
+		if idx := columnIndex(ids, first); idx >= 0 {
+			order = append(order, idx)
+		}
+	}
+	for i := len(ids) - 1; i >= 0; i-- {
+		if ids[i] == colName || ids[i] == colTags {
+			continue
+		}
+		order = append(order, i)
+	}
+	return order
+}
+
+func floorWidth(ids []columnID) int {
+	total := 0
+	for _, id := range ids {
+		total += columnSpecs[id].floor
+	}
+	return total
+}
+
+func columnIndex(ids []columnID, target columnID) int {
+	for i, id := range ids {
+		if id == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func videoRowFor(v video, ids []columnID) table.Row {
+	row := make(table.Row, 0, len(ids))
+	for _, id := range ids {
+		row = append(row, columnSpecs[id].cell(v))
+	}
+	return row
+}
+
+func nameCell(v video) string {
+	return highlightMatches(v.Name, v.match.positions)
+}
+
+func durationCell(v video) string {
+	if v.Err != nil {
+		return "!" + v.Err.Error()
+	}
+	if v.Duration > 0 {
+		return formatDuration(v.Duration)
+	}
+	return "(unknown)"
+}
+
+func ageCell(v video) string {
+	return humanizeAge(v.ModTime)
+}
+
+// ratingCell shows the rating as stars and marks favourites with a heart.
+func ratingCell(v video) string {
+	cell := "--"
+	if v.Details.Rating > 0 {
+		cell = strings.Repeat("★", v.Details.Rating) + strings.Repeat("☆", tags.MaxLevel-v.Details.Rating)
+	}
+	if v.Details.Favorite {
+		cell += " ♥"
+	}
+	return cell
+}
+
+func playedCell(v video) string {
+	if v.PlayCount == 0 {
+		return "never"
+	}
+	return fmt.Sprintf("%s ×%d", humanizeAge(v.LastPlayed), v.PlayCount)
+}
+
+func tagsCell(v video) string {
+	return formatTags(v.Tags)
+}
+
+func resolutionCell(v video) string {
+	if v.Width == 0 || v.Height == 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%dx%d", v.Width, v.Height)
+}
+
+func codecsCell(v video) string {
+	switch {
+	case v.VideoCodec == "" && v.AudioCodec == "":
+		return "--"
+	case v.AudioCodec == "":
+		return v.VideoCodec
+	case v.VideoCodec == "":
+		return v.AudioCodec
+	}
+	return v.VideoCodec + "/" + v.AudioCodec
+}
+
+func audioCell(v video) string {
+	if len(v.AudioLanguages) == 0 {
+		return "--"
+	}
+	return strings.Join(v.AudioLanguages, ",")
+}
+
+func bitrateCell(v video) string {
+	if v.Bitrate <= 0 {
+		return "--"
+	}
+	return fmt.Sprintf("%.1f Mb/s", float64(v.Bitrate)/1e6)
+}
+
+func sourceCell(v video) string {
+	if v.Source == "" {
+		return "--"
+	}
+	return v.Source
+}
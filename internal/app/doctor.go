@@ -0,0 +1,285 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// Levels of a Finding.
+const (
+	FindingOK   = "ok"
+	FindingWarn = "warn"
+	FindingFail = "FAIL"
+)
+
+// Finding is the result of one doctor check. Hint says how to fix a
+// problem.
+type Finding struct {
+	Level   string
+	Check   string
+	Message string
+	Hint    string
+}
+
+// doctorListLimit caps how many file names a single finding lists.
+const doctorListLimit = 5
+
+// Diagnose checks the environment and the library for problems that show up
+// as errors in the table: missing player and ffprobe binaries, roots and
+// directories that cannot be read, broken symlinks, a corrupt duration
+// cache, malformed sidecars and videos that cannot be probed. It does not
+// write anything.
+func Diagnose(opts Options) []Finding {
+	var findings []Finding
+	_, ffprobeErr := exec.LookPath("ffprobe")
+	roots := opts.libraryRoots()
+	cache, _ := loadLibraryCache(roots)
+	var needFFprobe []string
+	for i, root := range roots {
+		check := "root " + root.Label
+		if _, err := os.Stat(root.Path); err != nil {
+			finding := Finding{Level: FindingFail, Check: check, Message: err.Error(), Hint: "create the directory or pass another --root"}
+			if i > 0 {
+				finding.Level = FindingWarn
+				finding.Hint = "mount it, or remove it from --root and the config; its videos are hidden meanwhile"
+			}
+			findings = append(findings, finding)
+			continue
+		}
+		videos, skipped := scanForDoctor(root.Path)
+		findings = append(findings, Finding{Level: FindingOK, Check: check, Message: fmt.Sprintf("%s (%d videos)", root.Path, len(videos))})
+		findings = append(findings, checkCacheFile(root)...)
+		findings = append(findings, skipped...)
+		for _, path := range videos {
+			findings = append(findings, checkSidecar(path)...)
+			finding, needs := checkMedia(path, cache, ffprobeErr == nil)
+			if needs {
+				needFFprobe = append(needFFprobe, path)
+			}
+			if finding != nil {
+				findings = append(findings, *finding)
+			}
+		}
+	}
+	environment := []Finding{checkPlayer(opts.Player), checkFFprobe(ffprobeErr, needFFprobe)}
+	return append(environment, findings...)
+}
+
+// WriteFindings prints one line per finding, followed by its hint and a
+// summary, and returns the number of failed checks.
+func WriteFindings(w io.Writer, findings []Finding) int {
+	failed, warned := 0, 0
+	for _, finding := range findings {
+		fmt.Fprintf(w, "%-4s  %s: %s\n", finding.Level, finding.Check, finding.Message)
+		if finding.Hint != "" {
+			fmt.Fprintf(w, "      → %s\n", finding.Hint)
+		}
+		switch finding.Level {
+		case FindingFail:
+			failed++
+		case FindingWarn:
+			warned++
+		}
+	}
+	switch {
+	case failed > 0:
+		fmt.Fprintf(w, "\n%d problems, %d warnings\n", failed, warned)
+	case warned > 0:
+		fmt.Fprintf(w, "\nNo problems, %d warnings\n", warned)
+	default:
+		fmt.Fprintln(w, "\nNo problems found")
+	}
+	return failed
+}
+
+func checkPlayer(spec string) Finding {
+	player, err := NewPlayer(spec)
+	if err != nil {
+		return Finding{Level: FindingFail, Check: "player", Message: err.Error(), Hint: `fix --player or "player" in the config`}
+	}
+	binary, _ := player.Command("video.mp4", PlayOptions{})
+	path, err := exec.LookPath(binary)
+	if err != nil {
+		return Finding{
+			Level:   FindingFail,
+			Check:   "player",
+			Message: fmt.Sprintf("%s not found in PATH", binary),
+			Hint:    fmt.Sprintf(`install %s, or choose another player with --player or "player" in the config`, player.Name()),
+		}
+	}
+	return Finding{Level: FindingOK, Check: "player", Message: fmt.Sprintf("%s (%s)", player.Name(), path)}
+}
+
+func checkFFprobe(lookErr error, needed []string) Finding {
+	if lookErr == nil {
+		path, _ := exec.LookPath("ffprobe")
+		return Finding{Level: FindingOK, Check: "ffprobe", Message: path}
+	}
+	if len(needed) == 0 {
+		return Finding{
+			Level:   FindingWarn,
+			Check:   "ffprobe",
+			Message: "not found in PATH; every video can be read without it",
+			Hint:    "install ffmpeg to support formats without a built-in parser",
+		}
+	}
+	return Finding{
+		Level:   FindingFail,
+		Check:   "ffprobe",
+		Message: fmt.Sprintf("not found in PATH, but %d videos need it: %s", len(needed), listNames(needed)),
+		Hint:    `install ffmpeg, which provides ffprobe; until then these videos show "!exec: ffprobe: not found"`,
+	}
+}
+
+// checkCacheFile reports a duration cache that cannot be read or parsed.
+func checkCacheFile(root Root) []Finding {
+	path := filepath.Join(root.Path, durationCacheFile)
+	if _, err := readCacheFile(path); err != nil {
+		return []Finding{{
+			Level:   FindingFail,
+			Check:   "duration cache",
+			Message: fmt.Sprintf("%s: %v", path, err),
+			Hint:    "delete the file; durations are probed again on the next start",
+		}}
+	}
+	return nil
+}
+
+// scanForDoctor collects the videos below root and reports what the
+// library scan would stumble over.
+func scanForDoctor(root string) ([]string, []Finding) {
+	var videos []string
+	var findings []Finding
+	visit := collectInto(&videos)
+	visit.skip = func(path string, err error) {
+		if info, lerr := os.Lstat(path); lerr == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, _ := os.Readlink(path)
+			findings = append(findings, brokenSymlinkFinding(path, target))
+			return
+		}
+		findings = append(findings, Finding{
+			Level:   FindingFail,
+			Check:   "unreadable",
+			Message: fmt.Sprintf("%s: %v", path, unwrapPathError(err)),
+			Hint:    "fix its permissions; a directory that cannot be read hides the whole root from the library",
+		})
+	}
+	if info, err := os.Stat(root); err == nil && !info.IsDir() {
+		if isVideo(root) {
+			videos = append(videos, root)
+		}
+		return videos, nil
+	}
+	_ = walkVideoTree(root, root, make(map[string]struct{}), visit)
+	return videos, findings
+}
+
+// brokenSymlinkFinding fails for a link to a video or to what looks like a
+// directory, a target without an extension, since the library misses those.
+// Links to other files only warn: the library would not list them anyway.
+func brokenSymlinkFinding(path, target string) Finding {
+	finding := Finding{
+		Level:   FindingFail,
+		Check:   "broken symlink",
+		Message: fmt.Sprintf("%s -> %s", path, target),
+		Hint:    "restore the target or delete the link",
+	}
+	if !isVideo(path) && !isVideo(target) && filepath.Ext(target) != "" {
+		finding.Level = FindingWarn
+		finding.Hint = "delete the link; it is not a video, so the library ignores it"
+	}
+	return finding
+}
+
+func checkSidecar(path string) []Finding {
+	sidecar := tags.PathFor(path)
+	md, err := tags.LoadMetadata(path)
+	if err == nil {
+		err = md.Validate()
+	}
+	if err == nil {
+		return nil
+	}
+	return []Finding{{
+		Level:   FindingFail,
+		Check:   "sidecar",
+		Message: fmt.Sprintf("%s: %v", sidecar, err),
+		Hint:    "fix the JSON, or delete the file to drop the video's tags and details",
+	}}
+}
+
+// checkMedia probes a video that is not in the duration cache. needsFFprobe
+// reports a video only ffprobe can read when ffprobe is missing.
+func checkMedia(path string, cache *durationCache, haveFFprobe bool) (finding *Finding, needsFFprobe bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return &Finding{Level: FindingFail, Check: "video", Message: err.Error(), Hint: "check the file's permissions"}, false
+	}
+	if cachedMedia(cache, path, info).Duration > 0 {
+		return nil, false
+	}
+	media, err := probe.Probe(path)
+	if err != nil {
+		if !haveFFprobe {
+			return nil, true
+		}
+		media, err = probe.FFprobe(path)
+	}
+	switch {
+	case err != nil:
+		return &Finding{
+			Level:   FindingFail,
+			Check:   "video",
+			Message: fmt.Sprintf("%s: %s", path, probeErrorText(err)),
+			Hint:    "the file may be incomplete or damaged; re-download it or remux it with ffmpeg",
+		}, false
+	case media.Duration <= 0:
+		return &Finding{
+			Level:   FindingWarn,
+			Check:   "video",
+			Message: path + ": no duration in the file",
+			Hint:    "remux it with ffmpeg to write a duration; length filters skip it until then",
+		}, false
+	}
+	return nil, false
+}
+
+// probeErrorText prefers ffprobe's own message over its exit status.
+func probeErrorText(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if line, _, _ := strings.Cut(strings.TrimSpace(string(exitErr.Stderr)), "\n"); line != "" {
+			return line
+		}
+	}
+	return err.Error()
+}
+
+func unwrapPathError(err error) error {
+	var pathErr *os.PathError
+	if errors.As(err, &pathErr) {
+		return pathErr.Err
+	}
+	return err
+}
+
+// listNames lists the base names of paths, shortened after doctorListLimit.
+func listNames(paths []string) string {
+	names := make([]string, 0, doctorListLimit+1)
+	for i, path := range paths {
+		if i == doctorListLimit {
+			names = append(names, fmt.Sprintf("and %d more", len(paths)-i))
+			break
+		}
+		names = append(names, filepath.Base(path))
+	}
+	return strings.Join(names, ", ")
+}
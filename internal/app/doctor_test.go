@@ -0,0 +1,119 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func findingsFor(findings []Finding, check string) []Finding {
+	var out []Finding
+	for _, finding := range findings {
+		if finding.Check == check {
+			out = append(out, finding)
+		}
+	}
+	return out
+}
+
+func TestDiagnoseReportsLibraryProblems(t *testing.T) {
+	opts := writeListLibrary(t)
+	opts.Player = "sh {path}"
+	// A PATH with the player but without ffprobe.
+	bin := t.TempDir()
+	if err := os.Symlink("/bin/sh", filepath.Join(bin, "sh")); err != nil {
+		t.Skipf("symlink unsupported: %v", err)
+	}
+	t.Setenv("PATH", bin)
+	if err := os.WriteFile(filepath.Join(opts.Root, durationCacheFile), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write cache: %v", err)
+	}
+	if err := os.WriteFile(tags.PathFor(filepath.Join(opts.Root, "Long Flow.mp4")), []byte(`{"version": 1, "rating": 9}`), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(opts.Root, "gone"), filepath.Join(opts.Root, "Old Series")); err != nil {
+		t.Skipf("symlink unsupported: %v", err)
+	}
+	if err := os.Symlink(filepath.Join(opts.Root, "gone.pdf"), filepath.Join(opts.Root, "notes.pdf")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(opts.Root, "Stretch.wmv"), []byte("not a video"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+
+	findings := Diagnose(opts)
+	if player := findingsFor(findings, "player"); len(player) != 1 || player[0].Level != FindingOK {
+		t.Fatalf("expected the sh player to be found, got %+v", player)
+	}
+	if cache := findingsFor(findings, "duration cache"); len(cache) != 1 || cache[0].Level != FindingFail {
+		t.Fatalf("expected a corrupt cache finding, got %+v", cache)
+	}
+	if sidecar := findingsFor(findings, "sidecar"); len(sidecar) != 1 || !strings.Contains(sidecar[0].Message, "Long Flow.json") {
+		t.Fatalf("expected the invalid rating to be reported, got %+v", sidecar)
+	}
+	links := findingsFor(findings, "broken symlink")
+	if len(links) != 2 {
+		t.Fatalf("expected two broken symlinks, got %+v", links)
+	}
+	for _, link := range links {
+		directory := strings.Contains(link.Message, "Old Series")
+		if directory != (link.Level == FindingFail) {
+			t.Fatalf("expected only the directory link to fail, got %+v", links)
+		}
+	}
+	ffprobe := findingsFor(findings, "ffprobe")
+	if len(ffprobe) != 1 || ffprobe[0].Level != FindingFail || !strings.Contains(ffprobe[0].Message, "Stretch.wmv") {
+		t.Fatalf("expected ffprobe to be required for the wmv file, got %+v", ffprobe)
+	}
+	if videos := findingsFor(findings, "video"); len(videos) != 0 {
+		t.Fatalf("expected the mp4 clips to probe natively, got %+v", videos)
+	}
+}
+
+func TestDiagnoseReportsUnparseableVideos(t *testing.T) {
+	opts := writeListLibrary(t)
+	opts.Player = "sh {path}"
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho 'Invalid data found when processing input' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "ffprobe"), []byte(script), 0o755); err != nil {
+		t.Fatalf("write ffprobe: %v", err)
+	}
+	t.Setenv("PATH", dir+":/bin:/usr/bin")
+	if err := os.WriteFile(filepath.Join(opts.Root, "Stretch.wmv"), []byte("not a video"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	findings := Diagnose(opts)
+	if ffprobe := findingsFor(findings, "ffprobe"); ffprobe[0].Level != FindingOK {
+		t.Fatalf("expected ffprobe to be found, got %+v", ffprobe)
+	}
+	videos := findingsFor(findings, "video")
+	if len(videos) != 1 || !strings.HasSuffix(videos[0].Message, "Stretch.wmv: Invalid data found when processing input") {
+		t.Fatalf("expected ffprobe's message for the wmv file, got %+v", videos)
+	}
+
+	var out bytes.Buffer
+	if failed := WriteFindings(&out, findings); failed != 1 {
+		t.Fatalf("expected one failed check, got %d:\n%s", failed, out.String())
+	}
+	if !strings.Contains(out.String(), "FAIL  video: ") || !strings.HasSuffix(out.String(), "1 problems, 0 warnings\n") {
+		t.Fatalf("unexpected report:\n%s", out.String())
+	}
+}
+
+func TestDiagnoseMissingRoots(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nas")
+	opts := Options{Root: missing, Roots: []Root{{Label: "nas", Path: missing}}, Player: "sh"}
+	findings := Diagnose(opts)
+	if roots := findingsFor(findings, "root nas"); len(roots) != 1 || roots[0].Level != FindingFail || roots[0].Hint == "" {
+		t.Fatalf("expected a failed root with a hint, got %+v", roots)
+	}
+	home := t.TempDir()
+	opts = Options{Root: home, Roots: []Root{{Label: "home", Path: home}, {Label: "nas", Path: missing}}, Player: "sh"}
+	if roots := findingsFor(Diagnose(opts), "root nas"); len(roots) != 1 || roots[0].Level != FindingWarn {
+		t.Fatalf("expected an unavailable secondary root to warn, got %+v", roots)
+	}
+}
@@ -5,18 +5,43 @@ import (
 	"errors"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
+
+	"codeberg.org/snonux/yoga/internal/probe"
 )
 
+// cacheVersion is bumped whenever cacheEntry gains probed fields so that
+// older entries are probed again.
+const cacheVersion = 1
+
 type cacheEntry struct {
-	DurationSeconds float64 `json:"duration_seconds"`
-	ModTimeUnix     int64   `json:"mod_time_unix"`
-	Size            int64   `json:"size"`
+	Version         int      `json:"version,omitempty"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	ModTimeUnix     int64    `json:"mod_time_unix"`
+	Size            int64    `json:"size"`
+	Width           int      `json:"width,omitempty"`
+	Height          int      `json:"height,omitempty"`
+	VideoCodec      string   `json:"video_codec,omitempty"`
+	AudioCodec      string   `json:"audio_codec,omitempty"`
+	AudioLanguages  []string `json:"audio_languages,omitempty"`
+	Bitrate         int64    `json:"bitrate,omitempty"`
+}
+
+const durationCacheFile = ".video_duration_cache.json"
+
+// cacheShard is the cache file of one library root. Entries are written to
+// the shard whose root contains the video.
+type cacheShard struct {
+	root      string
+	path      string
+	available bool
 }
 
 type durationCache struct {
 	path    string
+	shards  []cacheShard
 	entries map[string]cacheEntry
 	mu      sync.Mutex
 	dirty   bool
@@ -28,43 +53,82 @@ func newDurationCache(path string) *durationCache {
 
 func loadDurationCache(path string) (*durationCache, error) {
 	cache := newDurationCache(path)
+	entries, err := readCacheFile(path)
+	if entries != nil {
+		cache.entries = entries
+	}
+	return cache, err
+}
+
+// loadLibraryCache merges the cache files of all roots. Roots that are not
+// reachable (an unmounted share) are never written on Flush, so their cache
+// survives until the root is back.
+func loadLibraryCache(roots []Root) (*durationCache, error) {
+	cache := newDurationCache("")
+	var errs []error
+	for _, root := range roots {
+		shard := cacheShard{root: root.Path, path: filepath.Join(root.Path, durationCacheFile)}
+		if info, err := os.Stat(root.Path); err == nil && info.IsDir() {
+			shard.available = true
+			entries, err := readCacheFile(shard.path)
+			if err != nil {
+				errs = append(errs, err)
+			}
+			for path, entry := range entries {
+				cache.entries[path] = entry
+			}
+		}
+		cache.shards = append(cache.shards, shard)
+	}
+	return cache, errors.Join(errs...)
+}
+
+func readCacheFile(path string) (map[string]cacheEntry, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return cache, nil
+			return nil, nil
 		}
-		return cache, err
+		return nil, err
 	}
 	if len(data) == 0 {
-		return cache, nil
+		return nil, nil
 	}
-	if err := json.Unmarshal(data, &cache.entries); err != nil {
-		cache.entries = make(map[string]cacheEntry)
-		return cache, err
+	entries := make(map[string]cacheEntry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
 	}
-	return cache, nil
+	return entries, nil
 }
 
-func (c *durationCache) Lookup(path string, info os.FileInfo) (time.Duration, bool) {
+func (c *durationCache) Lookup(path string, info os.FileInfo) (probe.Info, bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	entry, ok := c.entries[path]
 	if !ok {
-		return 0, false
+		return probe.Info{}, false
 	}
 	if entry.ModTimeUnix != info.ModTime().Unix() || entry.Size != info.Size() {
 		delete(c.entries, path)
 		c.dirty = true
-		return 0, false
+		return probe.Info{}, false
 	}
-	if entry.DurationSeconds <= 0 {
-		return 0, false
+	if entry.DurationSeconds <= 0 || entry.Version < cacheVersion {
+		return probe.Info{}, false
 	}
-	return time.Duration(entry.DurationSeconds * float64(time.Second)), true
+	return probe.Info{
+		Duration:       time.Duration(entry.DurationSeconds * float64(time.Second)),
+		Width:          entry.Width,
+		Height:         entry.Height,
+		VideoCodec:     entry.VideoCodec,
+		AudioCodec:     entry.AudioCodec,
+		AudioLanguages: entry.AudioLanguages,
+		Bitrate:        entry.Bitrate,
+	}, true
 }
 
-func (c *durationCache) Record(path string, info os.FileInfo, dur time.Duration) error {
-	if c == nil || dur <= 0 {
+func (c *durationCache) Record(path string, info os.FileInfo, media probe.Info) error {
+	if c == nil || media.Duration <= 0 {
 		return nil
 	}
 	c.mu.Lock()
@@ -73,14 +137,61 @@ func (c *durationCache) Record(path string, info os.FileInfo, dur time.Duration)
 		c.entries = make(map[string]cacheEntry)
 	}
 	c.entries[path] = cacheEntry{
-		DurationSeconds: dur.Seconds(),
+		Version:         cacheVersion,
+		DurationSeconds: media.Duration.Seconds(),
 		ModTimeUnix:     info.ModTime().Unix(),
 		Size:            info.Size(),
+		Width:           media.Width,
+		Height:          media.Height,
+		VideoCodec:      media.VideoCodec,
+		AudioCodec:      media.AudioCodec,
+		AudioLanguages:  media.AudioLanguages,
+		Bitrate:         media.Bitrate,
 	}
 	c.dirty = true
 	return nil
 }
 
+// Forget drops the entries for paths that left the library and reports
+// whether anything changed.
+func (c *durationCache) Forget(paths ...string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := false
+	for _, path := range paths {
+		if _, ok := c.entries[path]; ok {
+			delete(c.entries, path)
+			changed = true
+		}
+	}
+	if changed {
+		c.dirty = true
+	}
+	return changed
+}
+
+// Rename moves the entry of oldPath to newPath and reports whether there
+// was one. A rename keeps the size and modification time, so the entry
+// stays valid.
+func (c *durationCache) Rename(oldPath, newPath string) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[oldPath]
+	if !ok {
+		return false
+	}
+	delete(c.entries, oldPath)
+	c.entries[newPath] = entry
+	c.dirty = true
+	return true
+}
+
 func (c *durationCache) Flush() error {
 	if c == nil {
 		return nil
@@ -96,9 +207,48 @@ func (c *durationCache) Flush() error {
 	}
 	c.dirty = false
 	c.mu.Unlock()
-	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if len(c.shards) == 0 {
+		return writeCacheFile(c.path, snapshot)
+	}
+	parts := make([]map[string]cacheEntry, len(c.shards))
+	for path, entry := range snapshot {
+		idx := c.shardFor(path)
+		if parts[idx] == nil {
+			parts[idx] = make(map[string]cacheEntry)
+		}
+		parts[idx][path] = entry
+	}
+	var errs []error
+	for i, shard := range c.shards {
+		if !shard.available {
+			continue
+		}
+		if parts[i] == nil {
+			parts[i] = map[string]cacheEntry{}
+		}
+		if err := writeCacheFile(shard.path, parts[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// shardFor returns the shard of the most specific root containing path,
+// falling back to the first shard.
+func (c *durationCache) shardFor(path string) int {
+	best, bestLen := 0, -1
+	for i, shard := range c.shards {
+		if isWithinRoot(path, shard.root) && len(shard.root) > bestLen {
+			best, bestLen = i, len(shard.root)
+		}
+	}
+	return best
+}
+
+func writeCacheFile(path string, entries map[string]cacheEntry) error {
+	data, err := json.MarshalIndent(entries, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(c.path, data, 0o644)
+	return os.WriteFile(path, data, 0o644)
 }
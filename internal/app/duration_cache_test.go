@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"testing"
 	"time"
+
+	"codeberg.org/snonux/yoga/internal/probe"
 )
 
 func TestDurationCacheRecordLifecycle(t *testing.T) {
@@ -23,7 +25,7 @@ func TestDurationCacheRecordLifecycle(t *testing.T) {
 		t.Fatalf("stat video: %v", err)
 	}
 	duration := 90 * time.Second
-	if err := cache.Record(video, info, duration); err != nil {
+	if err := cache.Record(video, info, probe.Info{Duration: duration, Width: 1920, Height: 1080, AudioLanguages: []string{"eng"}}); err != nil {
 		t.Fatalf("record: %v", err)
 	}
 	if err := cache.Flush(); err != nil {
@@ -33,12 +35,15 @@ func TestDurationCacheRecordLifecycle(t *testing.T) {
 	if err != nil {
 		t.Fatalf("reload: %v", err)
 	}
-	dur, ok := cache2.Lookup(video, info)
+	media, ok := cache2.Lookup(video, info)
 	if !ok {
 		t.Fatalf("expected cached entry")
 	}
-	if dur != duration {
-		t.Fatalf("expected %v, got %v", duration, dur)
+	if media.Duration != duration {
+		t.Fatalf("expected %v, got %v", duration, media.Duration)
+	}
+	if media.Height != 1080 || len(media.AudioLanguages) != 1 {
+		t.Fatalf("expected stream details to round-trip, got %+v", media)
 	}
 }
 
@@ -50,12 +55,12 @@ func TestDurationCacheInvalidatesOnChange(t *testing.T) {
 		t.Fatalf("write: %v", err)
 	}
 	info, _ := os.Stat(video)
-	_ = cache.Record(video, info, 30*time.Second)
+	_ = cache.Record(video, info, probe.Info{Duration: 30 * time.Second})
 	if err := os.WriteFile(video, []byte("xx"), 0o644); err != nil {
 		t.Fatalf("rewrite: %v", err)
 	}
 	info, _ = os.Stat(video)
-	if dur, ok := cache.Lookup(video, info); ok || dur != 0 {
+	if media, ok := cache.Lookup(video, info); ok || media.Duration != 0 {
 		t.Fatalf("expected cache miss after change")
 	}
 }
@@ -74,3 +79,57 @@ func TestLoadDurationCacheInvalidJSON(t *testing.T) {
 		t.Fatalf("expected cache to reset entries")
 	}
 }
+
+func TestDurationCacheIgnoresEntriesWithoutVersion(t *testing.T) {
+	dir := t.TempDir()
+	video := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(video, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	info, _ := os.Stat(video)
+	cache := newDurationCache(filepath.Join(dir, "cache.json"))
+	cache.entries[video] = cacheEntry{DurationSeconds: 60, ModTimeUnix: info.ModTime().Unix(), Size: info.Size()}
+	if _, ok := cache.Lookup(video, info); ok {
+		t.Fatalf("expected legacy entry to be probed again")
+	}
+}
+
+func TestLibraryCacheWritesOneFilePerRoot(t *testing.T) {
+	home, nas := t.TempDir(), t.TempDir()
+	missing := filepath.Join(t.TempDir(), "unmounted")
+	roots := []Root{{Label: "home", Path: home}, {Label: "nas", Path: nas}, {Label: "usb", Path: missing}}
+	cache, err := loadLibraryCache(roots)
+	if err != nil {
+		t.Fatalf("load cache: %v", err)
+	}
+	for _, dir := range []string{home, nas} {
+		video := filepath.Join(dir, "video.mp4")
+		if err := os.WriteFile(video, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write video: %v", err)
+		}
+		info, _ := os.Stat(video)
+		_ = cache.Record(video, info, probe.Info{Duration: time.Minute})
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	for _, dir := range []string{home, nas} {
+		shard, err := loadDurationCache(filepath.Join(dir, durationCacheFile))
+		if err != nil {
+			t.Fatalf("load shard: %v", err)
+		}
+		if len(shard.entries) != 1 {
+			t.Fatalf("expected one entry in %s, got %d", dir, len(shard.entries))
+		}
+		if _, ok := shard.entries[filepath.Join(dir, "video.mp4")]; !ok {
+			t.Fatalf("expected entry of %s in its own cache file", dir)
+		}
+	}
+	if _, err := os.Stat(missing); err == nil {
+		t.Fatal("expected unavailable root to be left alone")
+	}
+	reloaded, err := loadLibraryCache(roots)
+	if err != nil || len(reloaded.entries) != 2 {
+		t.Fatalf("expected merged cache, got %d entries (%v)", len(reloaded.entries), err)
+	}
+}
@@ -9,6 +9,8 @@ import (
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
 )
 
 type filterState struct {
@@ -18,6 +20,76 @@ type filterState struct {
 	maxEnabled bool
 	maxMinutes int
 	tags       string
+	// tagQuery is tags compiled by parseTagQuery.
+	tagQuery tagQuery
+	// notPlayedDays hides videos played within the last N days.
+	notPlayedEnabled bool
+	notPlayedDays    int
+	// minHeight keeps videos with at least this many vertical pixels.
+	minHeight int
+	// audioLanguage keeps videos with a matching audio track language.
+	audioLanguage string
+	// source keeps videos from the library root with this label.
+	source string
+	// The remaining criteria are only reachable through the search bar.
+	minAge, maxAge   time.Duration
+	minSize, maxSize int64
+	neverPlayed      bool
+	playedWithinDays int
+	details          detailFilters
+}
+
+// detailFilters match the structured sidecar fields. Text criteria are
+// case-insensitive substrings; props "none" keeps videos without props.
+type detailFilters struct {
+	instructor string
+	style      string
+	focus      string
+	props      string
+	notes      string
+	difficulty intRange
+	intensity  intRange
+	rating     intRange
+}
+
+// intRange is an inclusive range on a 1-5 scale; zero bounds are unset.
+type intRange struct {
+	min, max int
+}
+
+func (r intRange) active() bool {
+	return r.min > 0 || r.max > 0
+}
+
+// contains treats an unset value (0) as failing any bound, like unknown
+// durations do for the length filter.
+func (r intRange) contains(value int) bool {
+	if value == 0 {
+		return !r.active()
+	}
+	return (r.min == 0 || value >= r.min) && (r.max == 0 || value <= r.max)
+}
+
+func (r intRange) String() string {
+	switch {
+	case r.min > 0 && r.min == r.max:
+		return strconv.Itoa(r.min)
+	case r.min > 0 && r.max > 0:
+		return fmt.Sprintf("%d..%d", r.min, r.max)
+	case r.min > 0:
+		return fmt.Sprintf(">=%d", r.min)
+	}
+	return fmt.Sprintf("<=%d", r.max)
+}
+
+// withSearchOnly copies the criteria the guided dialog has no field for.
+func (f filterState) withSearchOnly(from filterState) filterState {
+	f.minAge, f.maxAge = from.minAge, from.maxAge
+	f.minSize, f.maxSize = from.minSize, from.maxSize
+	f.neverPlayed = from.neverPlayed
+	f.playedWithinDays = from.playedWithinDays
+	f.details = from.details
+	return f
 }
 
 type filterInputs struct {
@@ -30,18 +102,40 @@ func (m *model) applyFilterInputs() error {
 	minText := strings.TrimSpace(m.inputs.fields[1].Value())
 	maxText := strings.TrimSpace(m.inputs.fields[2].Value())
 	tags := strings.TrimSpace(m.inputs.fields[3].Value())
+	notPlayedText := strings.TrimSpace(m.inputs.fields[4].Value())
+	heightText := strings.TrimSpace(m.inputs.fields[5].Value())
+	audioText := strings.ToLower(strings.TrimSpace(m.inputs.fields[6].Value()))
+	sourceText := strings.TrimSpace(m.inputs.fields[7].Value())
 
-	filters := filterState{name: name, tags: tags}
+	filters := filterState{name: name, tags: tags, audioLanguage: audioText, source: sourceText}
+	if err := populateTagFilter(&filters, tags); err != nil {
+		return err
+	}
 	if err := populateMinFilter(&filters, minText); err != nil {
 		return err
 	}
 	if err := populateMaxFilter(&filters, maxText); err != nil {
 		return err
 	}
+	if err := populateNotPlayedFilter(&filters, notPlayedText); err != nil {
+		return err
+	}
+	if err := populateMinHeightFilter(&filters, heightText); err != nil {
+		return err
+	}
 	if filters.minEnabled && filters.maxEnabled && filters.minMinutes > filters.maxMinutes {
 		return errors.New("min minutes cannot exceed max minutes")
 	}
-	m.filters = filters
+	m.filters = filters.withSearchOnly(m.filters)
+	return nil
+}
+
+func populateTagFilter(dst *filterState, value string) error {
+	query, err := parseTagQuery(value)
+	if err != nil {
+		return fmt.Errorf("invalid tags: %w", err)
+	}
+	dst.tagQuery = query
 	return nil
 }
 
@@ -69,7 +163,7 @@ func populateMaxFilter(dst *filterState, value string) error {
 	if err != nil {
 		return fmt.Errorf("invalid max minutes: %q", value)
 	}
-	if minutes < 0 {
+	if minutes <= 0 {
 		return errors.New("max minutes must be positive")
 	}
 	dst.maxEnabled = true
@@ -77,13 +171,68 @@ func populateMaxFilter(dst *filterState, value string) error {
 	return nil
 }
 
+func populateNotPlayedFilter(dst *filterState, value string) error {
+	if value == "" {
+		return nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("invalid not played days: %q", value)
+	}
+	if days <= 0 {
+		return errors.New("not played days must be positive")
+	}
+	dst.notPlayedEnabled = true
+	dst.notPlayedDays = days
+	return nil
+}
+
+// populateMinHeightFilter accepts plain pixel counts as well as the common
+// "1080p" spelling.
+func populateMinHeightFilter(dst *filterState, value string) error {
+	if value == "" {
+		return nil
+	}
+	height, err := strconv.Atoi(strings.TrimSuffix(strings.ToLower(value), "p"))
+	if err != nil {
+		return fmt.Errorf("invalid min height: %q", value)
+	}
+	if height <= 0 {
+		return errors.New("min height must be positive")
+	}
+	dst.minHeight = height
+	return nil
+}
+
 func (m *model) resetFilters() {
 	m.filters = filterState{}
+	m.collection = ""
 	for i := range m.inputs.fields {
 		m.inputs.fields[i].SetValue("")
 	}
 }
 
+// syncFilterInputs shows the current filters in the guided dialog.
+func (m *model) syncFilterInputs() {
+	f := m.filters
+	values := []string{f.name, "", "", f.tags, "", "", f.audioLanguage, f.source}
+	if f.minEnabled {
+		values[1] = strconv.Itoa(f.minMinutes)
+	}
+	if f.maxEnabled {
+		values[2] = strconv.Itoa(f.maxMinutes)
+	}
+	if f.notPlayedEnabled {
+		values[4] = strconv.Itoa(f.notPlayedDays)
+	}
+	if f.minHeight > 0 {
+		values[5] = strconv.Itoa(f.minHeight)
+	}
+	for i := range m.inputs.fields {
+		m.inputs.fields[i].SetValue(values[i])
+	}
+}
+
 func (m *model) updateFilterInputs(msg tea.Msg) (filterInputs, tea.Cmd) {
 	inputs := m.inputs
 	var cmds []tea.Cmd
@@ -98,10 +247,10 @@ func (m *model) updateFilterInputs(msg tea.Msg) (filterInputs, tea.Cmd) {
 func (m model) describeFilters() string {
 	parts := []string{}
 	if m.filters.name != "" {
-		parts = append(parts, fmt.Sprintf("name contains %q", m.filters.name))
+		parts = append(parts, fmt.Sprintf("name matches %q", m.filters.name))
 	}
 	if m.filters.tags != "" {
-		parts = append(parts, fmt.Sprintf("tags contain %q", m.filters.tags))
+		parts = append(parts, fmt.Sprintf("tags match %q", m.filters.tags))
 	}
 	if m.filters.minEnabled {
 		parts = append(parts, fmt.Sprintf(">=%d min", m.filters.minMinutes))
@@ -109,16 +258,64 @@ func (m model) describeFilters() string {
 	if m.filters.maxEnabled {
 		parts = append(parts, fmt.Sprintf("<=%d min", m.filters.maxMinutes))
 	}
+	if m.filters.notPlayedEnabled {
+		parts = append(parts, fmt.Sprintf("not played in %d days", m.filters.notPlayedDays))
+	}
+	if m.filters.minHeight > 0 {
+		parts = append(parts, fmt.Sprintf(">=%dp", m.filters.minHeight))
+	}
+	if m.filters.audioLanguage != "" {
+		parts = append(parts, fmt.Sprintf("audio %q", m.filters.audioLanguage))
+	}
+	if m.filters.source != "" {
+		parts = append(parts, fmt.Sprintf("source %q", m.filters.source))
+	}
+	if m.filters.maxAge > 0 {
+		parts = append(parts, "newer than "+formatSpan(m.filters.maxAge))
+	}
+	if m.filters.minAge > 0 {
+		parts = append(parts, "older than "+formatSpan(m.filters.minAge))
+	}
+	if m.filters.minSize > 0 {
+		parts = append(parts, ">="+formatSize(m.filters.minSize))
+	}
+	if m.filters.maxSize > 0 {
+		parts = append(parts, "<="+formatSize(m.filters.maxSize))
+	}
+	if m.filters.neverPlayed {
+		parts = append(parts, "never played")
+	}
+	if m.filters.playedWithinDays > 0 {
+		parts = append(parts, fmt.Sprintf("played in last %d days", m.filters.playedWithinDays))
+	}
+	parts = append(parts, m.filters.details.describe()...)
 	if len(parts) == 0 {
 		return "(none)"
 	}
 	return strings.Join(parts, ", ")
 }
 
+func (m model) filtersActive() bool {
+	return m.describeFilters() != "(none)"
+}
+
 func (m *model) passesFilters(v video) bool {
-	if m.filters.name != "" && !strings.Contains(strings.ToLower(v.Name), strings.ToLower(m.filters.name)) {
-		return false
+	_, ok := m.matchFilters(v)
+	return ok
+}
+
+// matchFilters reports whether v passes the filters, along with how its name
+// matched the name filter so callers do not have to match it again.
+func (m *model) matchFilters(v video) (nameMatch, bool) {
+	match, ok := fuzzyMatch(m.filters.name, v.Name)
+	if !ok || !m.passesOtherFilters(v) {
+		return nameMatch{}, false
 	}
+	return match, true
+}
+
+// passesOtherFilters checks every filter but the name.
+func (m *model) passesOtherFilters(v video) bool {
 	durMinutes := int(v.Duration.Round(time.Minute) / time.Minute)
 	if m.filters.minEnabled && (v.Duration == 0 || durMinutes < m.filters.minMinutes) {
 		return false
@@ -126,27 +323,135 @@ func (m *model) passesFilters(v video) bool {
 	if m.filters.maxEnabled && (v.Duration == 0 || durMinutes > m.filters.maxMinutes) {
 		return false
 	}
-	if m.filters.tags != "" {
-		query := strings.ToLower(m.filters.tags)
-		matched := false
-		for _, tag := range v.Tags {
-			if strings.Contains(strings.ToLower(tag), query) {
-				matched = true
-				break
-			}
+	if m.filters.tags != "" && !m.filters.matchesTags(v) {
+		return false
+	}
+	if m.filters.notPlayedEnabled && !v.LastPlayed.IsZero() {
+		if time.Since(v.LastPlayed) < time.Duration(m.filters.notPlayedDays)*24*time.Hour {
+			return false
 		}
-		if !matched {
+	}
+	if m.filters.minHeight > 0 && v.Height < m.filters.minHeight {
+		return false
+	}
+	if m.filters.audioLanguage != "" && !hasAudioLanguage(v, m.filters.audioLanguage) {
+		return false
+	}
+	if m.filters.source != "" && !strings.EqualFold(v.Source, m.filters.source) {
+		return false
+	}
+	return m.passesSearchOnlyFilters(v)
+}
+
+func (m *model) passesSearchOnlyFilters(v video) bool {
+	f := m.filters
+	age := time.Since(v.ModTime)
+	if f.maxAge > 0 && (v.ModTime.IsZero() || age > f.maxAge) {
+		return false
+	}
+	if f.minAge > 0 && (v.ModTime.IsZero() || age < f.minAge) {
+		return false
+	}
+	if f.minSize > 0 && v.Size < f.minSize {
+		return false
+	}
+	if f.maxSize > 0 && v.Size > f.maxSize {
+		return false
+	}
+	if f.neverPlayed && (v.PlayCount > 0 || !v.LastPlayed.IsZero()) {
+		return false
+	}
+	if f.playedWithinDays > 0 {
+		if v.LastPlayed.IsZero() || time.Since(v.LastPlayed) > time.Duration(f.playedWithinDays)*day {
 			return false
 		}
 	}
-	return true
+	return f.details.matches(v.Details)
+}
+
+func (d detailFilters) matches(v tags.Details) bool {
+	if !containsFold(v.Instructor, d.instructor) || !containsFold(v.Style, d.style) || !containsFold(v.Notes, d.notes) {
+		return false
+	}
+	if d.focus != "" && !anyContainsFold(v.Focus, d.focus) {
+		return false
+	}
+	if strings.EqualFold(d.props, "none") {
+		if len(v.Props) > 0 {
+			return false
+		}
+	} else if d.props != "" && !anyContainsFold(v.Props, d.props) {
+		return false
+	}
+	return d.difficulty.contains(v.Difficulty) && d.intensity.contains(v.Intensity) && d.rating.contains(v.Rating)
+}
+
+func (d detailFilters) describe() []string {
+	var parts []string
+	for _, text := range []struct{ label, value string }{
+		{"instructor", d.instructor}, {"style", d.style}, {"focus", d.focus}, {"props", d.props}, {"notes", d.notes},
+	} {
+		if text.value != "" {
+			parts = append(parts, fmt.Sprintf("%s %q", text.label, text.value))
+		}
+	}
+	for _, level := range []struct {
+		label string
+		value intRange
+	}{{"difficulty", d.difficulty}, {"intensity", d.intensity}, {"rating", d.rating}} {
+		if level.value.active() {
+			parts = append(parts, fmt.Sprintf("%s %s", level.label, level.value))
+		}
+	}
+	return parts
+}
+
+// containsFold reports whether value contains query, ignoring case. An
+// empty query always matches.
+func containsFold(value, query string) bool {
+	return query == "" || strings.Contains(strings.ToLower(value), strings.ToLower(query))
+}
+
+func anyContainsFold(values []string, query string) bool {
+	for _, value := range values {
+		if containsFold(value, query) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesTags evaluates the tag query. Filters built without
+// populateTagFilter fall back to a substring match when the query does not
+// parse.
+func (f filterState) matchesTags(v video) bool {
+	query := f.tagQuery
+	if query == nil {
+		var err error
+		if query, err = parseTagQuery(f.tags); err != nil || query == nil {
+			return hasTagContaining(v, f.tags)
+		}
+	}
+	return query.match(v.Tags)
+}
+
+// hasAudioLanguage matches ISO 639-2 codes exactly; two-letter queries such
+// as "en" also match codes that start with them ("eng", "en-US").
+func hasAudioLanguage(v video, query string) bool {
+	for _, lang := range v.AudioLanguages {
+		lang = strings.ToLower(lang)
+		if lang == query || (len(query) == 2 && strings.HasPrefix(lang, query)) {
+			return true
+		}
+	}
+	return false
 }
 
 func (m *model) renderFilterModal() string {
 	var b strings.Builder
 	b.WriteString("Filter videos\n")
 	b.WriteString("(Enter to apply, Esc to cancel)\n\n")
-	labels := []string{"Name contains:", "Min length (minutes):", "Max length (minutes):", "Tags contain:"}
+	labels := []string{"Name matches:", "Min length (minutes):", "Max length (minutes):", "Tags (e.g. hips AND NOT advanced):", "Not played in (days):", "Min height (e.g. 1080p):", "Audio language (e.g. eng):", "Source (root label):"}
 	for i, field := range m.inputs.fields {
 		line := fmt.Sprintf("%s %s", labels[i], field.View())
 		if i == m.inputs.focus {
@@ -155,7 +460,7 @@ func (m *model) renderFilterModal() string {
 		b.WriteString(line)
 		b.WriteString("\n")
 	}
-	if m.filters.minEnabled || m.filters.maxEnabled || m.filters.name != "" {
+	if m.filtersActive() {
 		b.WriteString("\nCurrent filter: ")
 		b.WriteString(m.describeFilters())
 		b.WriteString("\n")
@@ -33,3 +33,62 @@ func TestPopulateMaxFilterErrors(t *testing.T) {
 		t.Fatalf("expected state updated, got %+v", state)
 	}
 }
+
+func TestPopulateNotPlayedFilter(t *testing.T) {
+	var state filterState
+	if err := populateNotPlayedFilter(&state, "0"); err == nil {
+		t.Fatal("expected error for zero days")
+	}
+	if err := populateNotPlayedFilter(&state, "soon"); err == nil {
+		t.Fatal("expected error for invalid integer")
+	}
+	if err := populateNotPlayedFilter(&state, "30"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !state.notPlayedEnabled || state.notPlayedDays != 30 {
+		t.Fatalf("expected state updated, got %+v", state)
+	}
+}
+
+func TestPopulateMinHeightFilter(t *testing.T) {
+	var state filterState
+	if err := populateMinHeightFilter(&state, "tall"); err == nil {
+		t.Fatal("expected error for invalid height")
+	}
+	if err := populateMinHeightFilter(&state, "0"); err == nil {
+		t.Fatal("expected error for zero height")
+	}
+	if err := populateMinHeightFilter(&state, "1080p"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.minHeight != 1080 {
+		t.Fatalf("expected state updated, got %+v", state)
+	}
+}
+
+func TestPassesFiltersMediaCriteria(t *testing.T) {
+	hd := video{Name: "hd", Height: 1080, AudioLanguages: []string{"eng", "deu"}}
+	sd := video{Name: "sd", Height: 480, AudioLanguages: []string{"fre"}}
+	unknown := video{Name: "unknown"}
+	m := model{filters: filterState{minHeight: 720}}
+	if !m.passesFilters(hd) || m.passesFilters(sd) || m.passesFilters(unknown) {
+		t.Fatal("expected only the HD video to pass the height filter")
+	}
+	m.filters = filterState{audioLanguage: "en"}
+	if !m.passesFilters(hd) || m.passesFilters(sd) {
+		t.Fatal("expected two-letter language to match eng")
+	}
+	m.filters = filterState{audioLanguage: "deu"}
+	if !m.passesFilters(hd) || m.passesFilters(sd) {
+		t.Fatal("expected exact language match")
+	}
+}
+
+func TestPassesFiltersSource(t *testing.T) {
+	home := video{Name: "a", Source: "home"}
+	nas := video{Name: "b", Source: "nas"}
+	m := model{filters: filterState{source: "NAS"}}
+	if m.passesFilters(home) || !m.passesFilters(nas) {
+		t.Fatal("expected case-insensitive source match")
+	}
+}
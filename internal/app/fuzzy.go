@@ -0,0 +1,161 @@
+package app
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// nameMatch records how a video name matched the name filter. positions are
+// rune indices into the name, used to highlight the matched characters.
+type nameMatch struct {
+	score     int
+	positions []int
+}
+
+// Scores per query word: a match at the start of a word in the name ranks
+// above a match inside a word, which ranks above a scattered subsequence.
+const (
+	wordPrefixScore  = 100
+	substringScore   = 60
+	subsequenceScore = 20
+	// phraseBonus rewards names containing the whole query verbatim.
+	phraseBonus = 50
+	// maxSubsequenceGaps limits how many letters a subsequence match may
+	// skip. A fixed limit keeps matching monotonic: a name that fails a word
+	// also fails every longer word starting with it, which lets the quick
+	// filter narrow its previous result.
+	maxSubsequenceGaps = 6
+)
+
+// fuzzyMatch matches every whitespace separated word of query against name
+// in any order, so "yin hip" finds "Yin Yoga for Hips".
+func fuzzyMatch(query, name string) (nameMatch, bool) {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nameMatch{}, true
+	}
+	runes := lowerRunes(name)
+	var result nameMatch
+	seen := make(map[int]bool)
+	for _, word := range words {
+		score, positions, ok := matchWord([]rune(word), runes)
+		if !ok {
+			return nameMatch{}, false
+		}
+		result.score += score
+		for _, pos := range positions {
+			if !seen[pos] {
+				seen[pos] = true
+				result.positions = append(result.positions, pos)
+			}
+		}
+	}
+	if len(words) > 1 && strings.Contains(string(runes), strings.Join(words, " ")) {
+		result.score += phraseBonus
+	}
+	// Among equally good matches prefer shorter, more specific names.
+	result.score -= len(runes) / 10
+	sort.Ints(result.positions)
+	return result, true
+}
+
+func lowerRunes(s string) []rune {
+	runes := []rune(s)
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+	return runes
+}
+
+func matchWord(word, name []rune) (int, []int, bool) {
+	if idx := indexRunes(name, word, true); idx >= 0 {
+		return wordPrefixScore + 10*len(word), span(idx, len(word)), true
+	}
+	if idx := indexRunes(name, word, false); idx >= 0 {
+		return substringScore + 5*len(word), span(idx, len(word)), true
+	}
+	positions := tightestSubsequence(word, name)
+	if positions == nil {
+		return 0, nil, false
+	}
+	gaps := positions[len(positions)-1] - positions[0] + 1 - len(word)
+	return subsequenceScore + 3*len(word) - gaps, positions, true
+}
+
+// indexRunes finds word in name; with atWordStart only matches beginning a
+// word count.
+func indexRunes(name, word []rune, atWordStart bool) int {
+	for i := 0; i+len(word) <= len(name); i++ {
+		if atWordStart && i > 0 && isWordRune(name[i-1]) {
+			continue
+		}
+		if string(name[i:i+len(word)]) == string(word) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// tightestSubsequence returns the positions of the most compact in-order
+// occurrence of word's letters in name, or nil when there is none within
+// maxSubsequenceGaps.
+func tightestSubsequence(word, name []rune) []int {
+	var best []int
+	for start := range name {
+		if name[start] != word[0] {
+			continue
+		}
+		positions := []int{start}
+		j := 1
+		for i := start + 1; i < len(name) && j < len(word); i++ {
+			if name[i] == word[j] {
+				positions = append(positions, i)
+				j++
+			}
+		}
+		if j < len(word) {
+			break
+		}
+		if best == nil || positions[len(positions)-1]-positions[0] < best[len(best)-1]-best[0] {
+			best = positions
+		}
+	}
+	if best == nil || best[len(best)-1]-best[0]+1-len(word) > maxSubsequenceGaps {
+		return nil
+	}
+	return best
+}
+
+func span(start, length int) []int {
+	positions := make([]int, length)
+	for i := range positions {
+		positions[i] = start + i
+	}
+	return positions
+}
+
+// highlightMatches underlines the runes at positions with a combining low
+// line. Unlike ANSI styling it has no width, so the table's truncation and
+// the selected-row style keep working.
+func highlightMatches(name string, positions []int) string {
+	if len(positions) == 0 {
+		return name
+	}
+	var b strings.Builder
+	next := 0
+	for i, r := range []rune(name) {
+		b.WriteRune(r)
+		if next < len(positions) && positions[next] == i {
+			if !unicode.IsSpace(r) {
+				b.WriteRune('\u0332')
+			}
+			next++
+		}
+	}
+	return b.String()
+}
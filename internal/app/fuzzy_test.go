@@ -0,0 +1,78 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatchWords(t *testing.T) {
+	match, ok := fuzzyMatch("yin hip", "Yin Yoga for Hips.mp4")
+	if !ok {
+		t.Fatal("expected word tokens to match in any position")
+	}
+	if want := []int{0, 1, 2, 13, 14, 15}; !reflect.DeepEqual(match.positions, want) {
+		t.Fatalf("expected positions %v, got %v", want, match.positions)
+	}
+	if _, ok := fuzzyMatch("hip yin", "Yin Yoga for Hips.mp4"); !ok {
+		t.Fatal("expected word order not to matter")
+	}
+	if _, ok := fuzzyMatch("yin power", "Yin Yoga for Hips.mp4"); ok {
+		t.Fatal("expected every word to be required")
+	}
+	if _, ok := fuzzyMatch("vnysa", "Vinyasa Flow.mp4"); !ok {
+		t.Fatal("expected subsequence match")
+	}
+	if _, ok := fuzzyMatch("vw", "Vinyasa Flow.mp4"); ok {
+		t.Fatal("expected widely scattered letters not to match")
+	}
+}
+
+func TestFuzzyMatchRanking(t *testing.T) {
+	score := func(query, name string) int {
+		match, ok := fuzzyMatch(query, name)
+		if !ok {
+			t.Fatalf("expected %q to match %q", query, name)
+		}
+		return match.score
+	}
+	if score("flow", "Flow Morning") <= score("flow", "Overflow Morning") {
+		t.Fatal("expected word start to beat a match inside a word")
+	}
+	if score("flow", "Overflow Morning") <= score("flow", "Full Low Session") {
+		t.Fatal("expected substring to beat a subsequence")
+	}
+	if score("morning flow", "Morning Flow") <= score("morning flow", "Flow for the Morning") {
+		t.Fatal("expected the verbatim phrase to rank first")
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	if got := highlightMatches("Yin Hips", []int{0, 3, 4}); got != "Y̲in H̲ips" {
+		t.Fatalf("unexpected highlight %q", got)
+	}
+	if got := highlightMatches("plain", nil); got != "plain" {
+		t.Fatalf("expected unchanged name, got %q", got)
+	}
+}
+
+func TestSortByScoreRanksBestMatchFirst(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.videos = []video{
+		{Name: "A full hatha sequence.mp4", Path: "a"},
+		{Name: "Hatha basics.mp4", Path: "b"},
+		{Name: "Vinyasa.mp4", Path: "c"},
+	}
+	m.filters = filterState{name: "hatha"}
+	m.sortField = sortByScore
+	m.sortAscending = true
+	m.applyFiltersAndSort()
+	if len(m.filtered) != 2 || m.filtered[0].Path != "b" {
+		t.Fatalf("expected best match first, got %+v", m.filtered)
+	}
+	if row := m.table.Rows()[0]; row[0] != highlightMatches("Hatha basics.mp4", []int{0, 1, 2, 3, 4}) {
+		t.Fatalf("expected highlighted name cell, got %q", row[0])
+	}
+}
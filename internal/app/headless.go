@@ -0,0 +1,197 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// Selection picks and orders videos for the subcommands. Query uses the
+// search bar syntax; Filter names a saved filter and the remaining fields
+// refine both.
+type Selection struct {
+	Filter        string
+	Query         string
+	Name          string
+	Tags          string
+	MinMinutes    int
+	MaxMinutes    int
+	NotPlayedDays int
+	Height        string
+	Audio         string
+	Source        string
+	// Sort is one of name, length, age, played, rating or match.
+	Sort    string
+	Reverse bool
+}
+
+// sortNames maps the sort keys of Selection to sort fields.
+var sortNames = map[string]sortField{
+	"":         sortByName,
+	"name":     sortByName,
+	"length":   sortByDuration,
+	"duration": sortByDuration,
+	"age":      sortByAge,
+	"played":   sortByLastPlayed,
+	"rating":   sortByRating,
+	"match":    sortByScore,
+}
+
+// query combines the selection into one search bar query.
+func (s Selection) query(savedFilters *savedFilterStore) (string, error) {
+	var terms []string
+	if s.Filter != "" {
+		saved, ok := savedFilters.Lookup(s.Filter)
+		if !ok {
+			return "", fmt.Errorf("unknown saved filter %q", s.Filter)
+		}
+		terms = append(terms, saved)
+	}
+	if s.Query != "" {
+		terms = append(terms, s.Query)
+	}
+	if s.Name != "" {
+		terms = append(terms, "name:"+quoteSearchValue(s.Name))
+	}
+	if s.Tags != "" {
+		terms = append(terms, "tag:("+s.Tags+")")
+	}
+	if s.MinMinutes > 0 || s.MaxMinutes > 0 {
+		lo, hi := "", ""
+		if s.MinMinutes > 0 {
+			lo = fmt.Sprint(s.MinMinutes)
+		}
+		if s.MaxMinutes > 0 {
+			hi = fmt.Sprint(s.MaxMinutes)
+		}
+		terms = append(terms, "len:"+lo+".."+hi)
+	}
+	if s.NotPlayedDays > 0 {
+		terms = append(terms, fmt.Sprintf("played:>%dd", s.NotPlayedDays))
+	}
+	for _, term := range []struct{ key, value string }{{"height", s.Height}, {"audio", s.Audio}, {"source", s.Source}} {
+		if term.value != "" {
+			terms = append(terms, term.key+":"+quoteSearchValue(term.value))
+		}
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// selectVideos filters and sorts videos like the table does.
+func (s Selection) selectVideos(videos []video, savedFilters *savedFilterStore) ([]video, error) {
+	field, ok := sortNames[strings.ToLower(s.Sort)]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q (use name, length, age, played, rating or match)", s.Sort)
+	}
+	query, err := s.query(savedFilters)
+	if err != nil {
+		return nil, err
+	}
+	filters, err := parseSearchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	m := model{videos: videos, filters: filters, sortField: field, sortAscending: !s.Reverse}
+	return m.filterAndSort(), nil
+}
+
+// snapshot is the library as loaded by the subcommands.
+type snapshot struct {
+	videos       []video
+	savedFilters *savedFilterStore
+	// history is nil when the practice log could not be read, so it is not
+	// overwritten.
+	history *playHistory
+	// warnings are problems that do not prevent using the library.
+	warnings []error
+}
+
+// loadSnapshot loads the library without the TUI: it scans the roots,
+// probes durations missing from the cache, stores them and applies the play
+// history.
+func loadSnapshot(opts Options) (snapshot, error) {
+	roots := opts.libraryRoots()
+	var snap snapshot
+	cache, err := loadLibraryCache(roots)
+	if err != nil {
+		snap.warnings = append(snap.warnings, fmt.Errorf("duration cache: %w", err))
+	}
+	scan, err := loadLibrary(roots, cache, nil)
+	if err != nil {
+		return snap, err
+	}
+	for _, warning := range []error{scan.rootErr, scan.tagErr} {
+		if warning != nil {
+			snap.warnings = append(snap.warnings, warning)
+		}
+	}
+	if len(scan.pending) > 0 {
+		probePending(scan.videos, scan.pending, cache)
+		if err := cache.Flush(); err != nil {
+			snap.warnings = append(snap.warnings, fmt.Errorf("duration cache: %w", err))
+		}
+	}
+	history, err := loadPlayHistory(filepath.Join(opts.Root, ".video_history.json"))
+	if err != nil {
+		snap.warnings = append(snap.warnings, fmt.Errorf("play history: %w", err))
+		history = nil
+	}
+	m := model{videos: scan.videos, history: history}
+	m.applyHistory()
+	snap.videos, snap.history = m.videos, history
+	snap.savedFilters, err = loadSavedFilterStore(filepath.Join(opts.Root, ".video_filters.json"))
+	if err != nil {
+		snap.warnings = append(snap.warnings, fmt.Errorf("saved filters: %w", err))
+	}
+	return snap, nil
+}
+
+// probePending probes the pending videos on all CPUs and records the
+// results in cache.
+func probePending(videos []video, pending []string, cache *durationCache) {
+	index := make(map[string]int, len(videos))
+	for i, v := range videos {
+		index[v.Path] = i
+	}
+	paths := make(chan string)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for range runtime.NumCPU() {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				media, err := probeMedia(path)
+				if err == nil {
+					if info, statErr := os.Stat(path); statErr == nil {
+						_ = cache.Record(path, info, media)
+					}
+				}
+				mu.Lock()
+				if i, ok := index[path]; ok {
+					videos[i].applyMedia(media)
+					videos[i].Err = err
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, path := range pending {
+		paths <- path
+	}
+	close(paths)
+	wg.Wait()
+}
+
+// printWarnings reports non-fatal problems on w, one line each.
+func printWarnings(w io.Writer, warnings []error) {
+	for _, warning := range warnings {
+		for _, line := range strings.Split(warning.Error(), "\n") {
+			fmt.Fprintf(w, "warning: %s\n", line)
+		}
+	}
+}
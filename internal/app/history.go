@@ -0,0 +1,121 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+type historyEntry struct {
+	Path           string  `json:"path"`
+	StartedUnix    int64   `json:"started_unix"`
+	WatchedSeconds float64 `json:"watched_seconds,omitempty"`
+}
+
+// playStats summarises the history of a single video.
+type playStats struct {
+	LastPlayed time.Time
+	Count      int
+}
+
+// playHistory is the persistent practice log with one entry per launch.
+type playHistory struct {
+	path    string
+	entries []historyEntry
+	mu      sync.Mutex
+}
+
+func newPlayHistory(path string) *playHistory {
+	return &playHistory{path: path}
+}
+
+func loadPlayHistory(path string) (*playHistory, error) {
+	history := newPlayHistory(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return history, nil
+		}
+		return history, err
+	}
+	if len(data) == 0 {
+		return history, nil
+	}
+	if err := json.Unmarshal(data, &history.entries); err != nil {
+		history.entries = nil
+		return history, err
+	}
+	return history, nil
+}
+
+func (h *playHistory) RecordStart(path string, started time.Time) {
+	if h == nil {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.entries = append(h.entries, historyEntry{Path: path, StartedUnix: started.Unix()})
+}
+
+// RecordEnd stores how long the player stayed open for the launch of path
+// that began at started.
+func (h *playHistory) RecordEnd(path string, started time.Time, watched time.Duration) {
+	if h == nil || watched <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		entry := &h.entries[i]
+		if entry.Path == path && entry.StartedUnix == started.Unix() {
+			entry.WatchedSeconds = watched.Seconds()
+			return
+		}
+	}
+}
+
+func (h *playHistory) Stats() map[string]playStats {
+	stats := make(map[string]playStats)
+	if h == nil {
+		return stats
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, entry := range h.entries {
+		current := stats[entry.Path]
+		current.Count++
+		started := time.Unix(entry.StartedUnix, 0)
+		if started.After(current.LastPlayed) {
+			current.LastPlayed = started
+		}
+		stats[entry.Path] = current
+	}
+	return stats
+}
+
+// Entries returns a copy of the log in the order the launches happened.
+func (h *playHistory) Entries() []historyEntry {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]historyEntry(nil), h.entries...)
+}
+
+func (h *playHistory) Flush() error {
+	if h == nil {
+		return nil
+	}
+	h.mu.Lock()
+	snapshot := append([]historyEntry{}, h.entries...)
+	h.mu.Unlock()
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(h.path, data, 0o644)
+}
@@ -0,0 +1,136 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPlayHistoryLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	history, err := loadPlayHistory(path)
+	if err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	first := time.Now().Add(-48 * time.Hour)
+	second := time.Now().Add(-time.Hour)
+	history.RecordStart("a.mp4", first)
+	history.RecordStart("a.mp4", second)
+	history.RecordEnd("a.mp4", second, 20*time.Minute)
+	if err := history.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	reloaded, err := loadPlayHistory(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	stats := reloaded.Stats()["a.mp4"]
+	if stats.Count != 2 || stats.LastPlayed.Unix() != second.Unix() {
+		t.Fatalf("unexpected stats %+v", stats)
+	}
+	if reloaded.entries[1].WatchedSeconds != 1200 {
+		t.Fatalf("expected watched time recorded, got %+v", reloaded.entries[1])
+	}
+}
+
+func TestLoadPlayHistoryInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	history, err := loadPlayHistory(path)
+	if err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+	if len(history.entries) != 0 {
+		t.Fatal("expected empty history")
+	}
+}
+
+func TestHandleHistoryLoadedKeepsCorruptFile(t *testing.T) {
+	m := loadedModel(t, Options{Root: t.TempDir()}, []video{{Name: "a.mp4", Path: "/y/a.mp4"}})
+	if err := os.WriteFile(m.historyPath, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write history: %v", err)
+	}
+	modelAny, _ := m.Update(historyLoadedCmd(m.historyPath)())
+	m = modelAny.(model)
+	if m.history != nil || !strings.Contains(m.statusMessage, "Play history warning") {
+		t.Fatalf("expected no history and a warning, got %v %q", m.history, m.statusMessage)
+	}
+	m.recordPlayStart("/y/a.mp4", time.Now())
+	m.recordPlayEnd(playbackEndedMsg{path: "/y/a.mp4", started: time.Now(), elapsed: time.Minute})
+	if data, _ := os.ReadFile(m.historyPath); string(data) != "{" {
+		t.Fatalf("expected the history file to be kept, got %q", data)
+	}
+}
+
+func TestPlayHistoryFeedsColumnSortAndFilter(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	history := newPlayHistory(m.historyPath)
+	history.RecordStart(filepath.Join(root, "recent.mp4"), time.Now().Add(-2*24*time.Hour))
+	history.RecordStart(filepath.Join(root, "old.mp4"), time.Now().Add(-60*24*time.Hour))
+	m.videos = []video{
+		{Name: "recent.mp4", Path: filepath.Join(root, "recent.mp4")},
+		{Name: "old.mp4", Path: filepath.Join(root, "old.mp4")},
+		{Name: "fresh.mp4", Path: filepath.Join(root, "fresh.mp4")},
+	}
+	modelAny, _ := m.Update(historyLoadedMsg{history: history})
+	m = modelAny.(model)
+	modelAny, _ = m.handleKeyMsg(keyMsg("p"))
+	m = modelAny.(model)
+	order := []string{m.filtered[0].Name, m.filtered[1].Name, m.filtered[2].Name}
+	if strings.Join(order, ",") != "fresh.mp4,old.mp4,recent.mp4" {
+		t.Fatalf("expected least recently played first, got %v", order)
+	}
+	if cell := videoRow(m.filtered[0])[4]; cell != "never" {
+		t.Fatalf("expected never played cell, got %s", cell)
+	}
+	if cell := videoRow(m.filtered[2])[4]; !strings.Contains(cell, "×1") {
+		t.Fatalf("expected play count in cell, got %s", cell)
+	}
+	m.inputs.fields[4].SetValue("30")
+	if err := m.applyFilterInputs(); err != nil {
+		t.Fatalf("applyFilterInputs: %v", err)
+	}
+	m.applyFiltersAndSort()
+	if len(m.filtered) != 2 {
+		t.Fatalf("expected recently played video hidden, got %+v", m.filtered)
+	}
+	if !strings.Contains(m.describeFilters(), "not played in 30 days") {
+		t.Fatalf("unexpected description %s", m.describeFilters())
+	}
+}
+
+func TestPlayVideoRecordsHistory(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.history = newPlayHistory(m.historyPath)
+	path := filepath.Join(root, "clip.mp4")
+	m.videos = []video{{Name: "clip.mp4", Path: path}}
+	m.applyFiltersAndSort()
+	started := time.Now()
+	modelAny, _ := m.Update(playVideoMsg{path: path, started: started})
+	m = modelAny.(model)
+	if m.videos[0].PlayCount != 1 {
+		t.Fatalf("expected play count incremented, got %d", m.videos[0].PlayCount)
+	}
+	modelAny, _ = m.Update(playbackEndedMsg{path: path, started: started, elapsed: 10 * time.Minute})
+	m = modelAny.(model)
+	reloaded, err := loadPlayHistory(m.historyPath)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if len(reloaded.entries) != 1 || reloaded.entries[0].WatchedSeconds != 600 {
+		t.Fatalf("unexpected persisted history %+v", reloaded.entries)
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import "fmt"
+
+// libraryDiff counts how a re-index changed the library.
+type libraryDiff struct {
+	added    int
+	removed  int
+	modified int
+}
+
+func (d libraryDiff) String() string {
+	return fmt.Sprintf("+%d / -%d / ~%d", d.added, d.removed, d.modified)
+}
+
+// mergeReloadedVideos reconciles a fresh scan with the current videos.
+// Videos missing from the scan are dropped and returned as removed; a file
+// counts as modified when its modification time or size changed. Unchanged
+// files keep probe results that have not reached the on-disk cache yet, so
+// they are not probed again.
+func mergeReloadedVideos(current, reloaded []video, pending []string) ([]video, []string, libraryDiff, []string) {
+	previous := make(map[string]video, len(current))
+	for _, v := range current {
+		previous[v.Path] = v
+	}
+	var diff libraryDiff
+	kept := make(map[string]bool, len(pending))
+	for _, path := range pending {
+		kept[path] = true
+	}
+	merged := make([]video, 0, len(reloaded))
+	for _, v := range reloaded {
+		old, ok := previous[v.Path]
+		delete(previous, v.Path)
+		switch {
+		case !ok:
+			diff.added++
+		case !old.ModTime.Equal(v.ModTime) || old.Size != v.Size:
+			diff.modified++
+		case v.Duration == 0 && old.Duration > 0:
+			v.applyMedia(old.mediaInfo())
+			kept[v.Path] = false
+		}
+		merged = append(merged, v)
+	}
+	removed := make([]string, 0, len(previous))
+	for _, v := range current {
+		if _, gone := previous[v.Path]; gone {
+			removed = append(removed, v.Path)
+		}
+	}
+	diff.removed = len(removed)
+	remaining := make([]string, 0, len(pending))
+	for _, path := range pending {
+		if kept[path] {
+			remaining = append(remaining, path)
+		}
+	}
+	return merged, remaining, diff, removed
+}
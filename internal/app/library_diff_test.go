@@ -0,0 +1,132 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+)
+
+func TestMergeReloadedVideos(t *testing.T) {
+	now := time.Now()
+	current := []video{
+		{Path: "same", ModTime: now, Size: 1, Duration: time.Minute},
+		{Path: "probed", ModTime: now, Size: 1, Duration: 2 * time.Minute, Height: 720},
+		{Path: "changed", ModTime: now, Size: 1, Duration: time.Minute},
+		{Path: "gone", ModTime: now, Size: 1},
+	}
+	reloaded := []video{
+		{Path: "same", ModTime: now, Size: 1, Duration: time.Minute},
+		{Path: "probed", ModTime: now, Size: 1},
+		{Path: "changed", ModTime: now, Size: 2},
+		{Path: "new", ModTime: now, Size: 1},
+	}
+	merged, pending, diff, removed := mergeReloadedVideos(current, reloaded, []string{"probed", "changed", "new"})
+	if diff.String() != "+1 / -1 / ~1" {
+		t.Fatalf("unexpected diff %s", diff)
+	}
+	if len(removed) != 1 || removed[0] != "gone" {
+		t.Fatalf("unexpected removed %v", removed)
+	}
+	if len(merged) != 4 || merged[1].Duration != 2*time.Minute || merged[1].Height != 720 {
+		t.Fatalf("expected in-memory probe result to survive, got %+v", merged)
+	}
+	if len(pending) != 2 || pending[0] != "changed" || pending[1] != "new" {
+		t.Fatalf("expected only changed and new videos pending, got %v", pending)
+	}
+}
+
+func TestReindexDropsDeletedVideosAndKeepsSelection(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	paths := map[string]string{}
+	for _, name := range []string{"a.mp4", "b.mp4", "c.mp4"} {
+		paths[name] = filepath.Join(root, name)
+		if err := os.WriteFile(paths[name], []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	cachePath := filepath.Join(root, ".video_duration_cache.json")
+	cache := newDurationCache(cachePath)
+	for _, path := range paths {
+		info, _ := os.Stat(path)
+		_ = cache.Record(path, info, probe.Info{Duration: time.Minute})
+	}
+	if err := cache.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	msg := loadVideosCmd([]Root{{Path: root}}, nil)().(videosLoadedMsg)
+	modelAny, _ := m.handleVideosLoaded(msg)
+	m = modelAny.(model)
+	m.filters = filterState{name: "mp4"}
+	m.applyFiltersAndSort()
+	m.restoreSelection(paths["c.mp4"])
+
+	if err := os.Remove(paths["a.mp4"]); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if err := os.WriteFile(paths["b.mp4"], []byte("longer"), 0o644); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "d.mp4"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	msg = loadVideosCmd([]Root{{Path: root}}, nil)().(videosLoadedMsg)
+	modelAny, _ = m.handleVideosLoaded(msg)
+	m = modelAny.(model)
+
+	if len(m.videos) != 3 || m.videoIndex(paths["a.mp4"]) >= 0 {
+		t.Fatalf("expected deleted video to be dropped, got %+v", m.videos)
+	}
+	if m.currentSelectionPath() != paths["c.mp4"] || m.filters.name != "mp4" {
+		t.Fatalf("expected selection and filter to survive, got %s %+v", m.currentSelectionPath(), m.filters)
+	}
+	if m.statusMessage != "Re-indexed: +1 / -1 / ~1" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+	reloaded, err := loadDurationCache(cachePath)
+	if err != nil {
+		t.Fatalf("reload cache: %v", err)
+	}
+	if _, ok := reloaded.entries[paths["a.mp4"]]; ok {
+		t.Fatal("expected cache entry of deleted video to be pruned")
+	}
+}
+
+func TestReindexKeepsVideosOfUnavailableRoot(t *testing.T) {
+	home, nas := t.TempDir(), t.TempDir()
+	for _, path := range []string{filepath.Join(home, "a.mp4"), filepath.Join(nas, "b.mp4")} {
+		if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	roots := []Root{{Label: "home", Path: home}, {Label: "nas", Path: nas}}
+	m, err := newModel(Options{Root: home, Roots: roots})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	if columnIndex(m.visibleColumns(), colSource) < 0 {
+		t.Fatal("expected source column with several roots")
+	}
+	modelAny, _ := m.handleVideosLoaded(loadVideosCmd(m.roots, nil)().(videosLoadedMsg))
+	m = modelAny.(model)
+	if len(m.videos) != 2 {
+		t.Fatalf("expected videos of both roots, got %+v", m.videos)
+	}
+	if err := os.RemoveAll(nas); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	modelAny, _ = m.handleVideosLoaded(loadVideosCmd(m.roots, nil)().(videosLoadedMsg))
+	m = modelAny.(model)
+	if len(m.videos) != 2 {
+		t.Fatalf("expected videos of the unavailable root to be kept, got %+v", m.videos)
+	}
+	if m.statusMessage != "Re-indexed: +0 / -0 / ~0" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
@@ -0,0 +1,204 @@
+package app
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// List formats.
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatCSV   = "csv"
+)
+
+// ListOptions configures List.
+type ListOptions struct {
+	Selection
+	// Format is FormatTable, FormatJSON (one object per line) or FormatCSV.
+	Format string
+	// Columns picks the table columns like Options.Columns; the JSON and
+	// CSV formats always carry every field.
+	Columns []string
+}
+
+// listRecord is one video in the JSON and CSV output.
+type listRecord struct {
+	Name           string   `json:"name"`
+	Path           string   `json:"path"`
+	Source         string   `json:"source,omitempty"`
+	Seconds        int      `json:"duration_seconds"`
+	Size           int64    `json:"size_bytes"`
+	Modified       string   `json:"modified"`
+	Width          int      `json:"width,omitempty"`
+	Height         int      `json:"height,omitempty"`
+	VideoCodec     string   `json:"video_codec,omitempty"`
+	AudioCodec     string   `json:"audio_codec,omitempty"`
+	AudioLanguages []string `json:"audio_languages,omitempty"`
+	Bitrate        int64    `json:"bitrate,omitempty"`
+	Tags           []string `json:"tags"`
+	PlayCount      int      `json:"play_count"`
+	LastPlayed     string   `json:"last_played,omitempty"`
+	Instructor     string   `json:"instructor,omitempty"`
+	Style          string   `json:"style,omitempty"`
+	Difficulty     int      `json:"difficulty,omitempty"`
+	Focus          []string `json:"focus,omitempty"`
+	Props          []string `json:"props,omitempty"`
+	Intensity      int      `json:"intensity,omitempty"`
+	Rating         int      `json:"rating,omitempty"`
+	Favorite       bool     `json:"favorite,omitempty"`
+	Notes          string   `json:"notes,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+var csvHeader = []string{
+	"name", "path", "source", "duration_seconds", "size_bytes", "modified", "width", "height",
+	"video_codec", "audio_codec", "audio_languages", "bitrate", "tags", "play_count", "last_played",
+	"instructor", "style", "difficulty", "focus", "props", "intensity", "rating", "favorite", "notes", "error",
+}
+
+func newListRecord(v video) listRecord {
+	record := listRecord{
+		Name:           v.Name,
+		Path:           v.Path,
+		Source:         v.Source,
+		Seconds:        int(v.Duration.Round(time.Second).Seconds()),
+		Size:           v.Size,
+		Modified:       formatTimestamp(v.ModTime),
+		Width:          v.Width,
+		Height:         v.Height,
+		VideoCodec:     v.VideoCodec,
+		AudioCodec:     v.AudioCodec,
+		AudioLanguages: v.AudioLanguages,
+		Bitrate:        v.Bitrate,
+		Tags:           v.Tags,
+		PlayCount:      v.PlayCount,
+		LastPlayed:     formatTimestamp(v.LastPlayed),
+		Instructor:     v.Details.Instructor,
+		Style:          v.Details.Style,
+		Difficulty:     v.Details.Difficulty,
+		Focus:          v.Details.Focus,
+		Props:          v.Details.Props,
+		Intensity:      v.Details.Intensity,
+		Rating:         v.Details.Rating,
+		Favorite:       v.Details.Favorite,
+		Notes:          v.Details.Notes,
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+	if v.Err != nil {
+		record.Error = v.Err.Error()
+	}
+	return record
+}
+
+func (r listRecord) csvRow() []string {
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	favorite := ""
+	if r.Favorite {
+		favorite = "true"
+	}
+	return []string{
+		r.Name, r.Path, r.Source, strconv.Itoa(r.Seconds), strconv.FormatInt(r.Size, 10), r.Modified,
+		itoa(r.Width), itoa(r.Height), r.VideoCodec, r.AudioCodec, strings.Join(r.AudioLanguages, ";"),
+		strconv.FormatInt(r.Bitrate, 10), strings.Join(r.Tags, ";"), strconv.Itoa(r.PlayCount), r.LastPlayed,
+		r.Instructor, r.Style, itoa(r.Difficulty), strings.Join(r.Focus, ";"), strings.Join(r.Props, ";"),
+		itoa(r.Intensity), itoa(r.Rating), favorite, r.Notes, r.Error,
+	}
+}
+
+func formatTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// List prints the videos of the library matching the selection to stdout.
+// Warnings such as unreadable roots go to stderr.
+func List(opts Options, list ListOptions, stdout, stderr io.Writer) error {
+	columns, err := parseColumns(list.Columns)
+	if err != nil {
+		return err
+	}
+	if len(list.Columns) == 0 && len(opts.Columns) > 0 {
+		if columns, err = parseColumns(opts.Columns); err != nil {
+			return err
+		}
+	}
+	format := strings.ToLower(list.Format)
+	if format == "" {
+		format = FormatTable
+	}
+	if format != FormatTable && format != FormatJSON && format != FormatCSV {
+		return fmt.Errorf("unknown format %q (use table, json or csv)", list.Format)
+	}
+	snap, err := loadSnapshot(opts)
+	printWarnings(stderr, snap.warnings)
+	if err != nil {
+		return err
+	}
+	selected, err := list.selectVideos(snap.videos, snap.savedFilters)
+	if err != nil {
+		return err
+	}
+	switch format {
+	case FormatJSON:
+		return writeJSONLines(stdout, selected)
+	case FormatCSV:
+		return writeCSV(stdout, selected)
+	}
+	return writeTable(stdout, selected, columns)
+}
+
+func writeJSONLines(w io.Writer, videos []video) error {
+	enc := json.NewEncoder(w)
+	for _, v := range videos {
+		if err := enc.Encode(newListRecord(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, videos []video) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, v := range videos {
+		if err := out.Write(newListRecord(v).csvRow()); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// writeTable prints the same cells as the TUI table, without match
+// highlighting.
+func writeTable(w io.Writer, videos []video, columns []columnID) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	titles := make([]string, len(columns))
+	for i, id := range columns {
+		titles[i] = strings.ToUpper(columnSpecs[id].title)
+	}
+	fmt.Fprintln(tw, strings.Join(titles, "\t"))
+	for _, v := range videos {
+		v.match = nameMatch{}
+		fmt.Fprintln(tw, strings.Join(videoRowFor(v, columns), "\t"))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,125 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// writeClip writes a minimal MP4 of the given length that the native probe
+// can read, so the tests do not need ffprobe.
+func writeClip(t *testing.T, path string, seconds uint32, videoTags ...string) {
+	t.Helper()
+	mvhd := make([]byte, 108)
+	binary.BigEndian.PutUint32(mvhd[0:4], uint32(len(mvhd)))
+	copy(mvhd[4:8], "mvhd")
+	binary.BigEndian.PutUint32(mvhd[20:24], 1)
+	binary.BigEndian.PutUint32(mvhd[24:28], seconds)
+	moov := binary.BigEndian.AppendUint32(nil, uint32(8+len(mvhd)))
+	moov = append(append(moov, "moov"...), mvhd...)
+	if err := os.WriteFile(path, moov, 0o644); err != nil {
+		t.Fatalf("write clip: %v", err)
+	}
+	if len(videoTags) > 0 {
+		if err := tags.Save(path, videoTags); err != nil {
+			t.Fatalf("save tags: %v", err)
+		}
+	}
+}
+
+func writeListLibrary(t *testing.T) Options {
+	t.Helper()
+	root := t.TempDir()
+	writeClip(t, filepath.Join(root, "Short Hips.mp4"), 10*60, "hips")
+	writeClip(t, filepath.Join(root, "Medium Hips.mp4"), 25*60, "hips", "morning")
+	writeClip(t, filepath.Join(root, "Long Flow.mp4"), 40*60, "flow")
+	return Options{Root: root, Roots: []Root{{Path: root}}}
+}
+
+func TestListJSONFiltersAndSorts(t *testing.T) {
+	opts := writeListLibrary(t)
+	var stdout, stderr bytes.Buffer
+	list := ListOptions{Selection: Selection{Tags: "hips", MaxMinutes: 30, Sort: "length", Reverse: true}, Format: FormatJSON}
+	if err := List(opts, list, &stdout, &stderr); err != nil {
+		t.Fatalf("List: %v (%s)", err, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 videos, got %q", stdout.String())
+	}
+	var first listRecord
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if first.Name != "Medium Hips.mp4" || first.Seconds != 25*60 || len(first.Tags) != 2 {
+		t.Fatalf("expected the longest hips video first, got %+v", first)
+	}
+	if _, err := os.Stat(filepath.Join(opts.Root, durationCacheFile)); err != nil {
+		t.Fatalf("expected probed durations to be cached: %v", err)
+	}
+}
+
+func TestListCSVAndTable(t *testing.T) {
+	opts := writeListLibrary(t)
+	var stdout, stderr bytes.Buffer
+	list := ListOptions{Selection: Selection{Query: "flow"}, Format: FormatCSV}
+	if err := List(opts, list, &stdout, &stderr); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("read csv: %v", err)
+	}
+	if len(records) != 2 || records[0][0] != "name" || records[1][0] != "Long Flow.mp4" || records[1][3] != "2400" {
+		t.Fatalf("unexpected csv %v", records)
+	}
+
+	stdout.Reset()
+	list = ListOptions{Selection: Selection{Name: "hips"}, Columns: []string{"name", "duration", "tags"}}
+	if err := List(opts, list, &stdout, &stderr); err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "NAME") || !strings.HasPrefix(lines[1], "Medium Hips") {
+		t.Fatalf("unexpected table %q", stdout.String())
+	}
+	if !strings.Contains(lines[2], "10:00") || !strings.Contains(lines[2], "hips") {
+		t.Fatalf("expected duration and tags cells, got %q", lines[2])
+	}
+}
+
+func TestSelectionQuery(t *testing.T) {
+	store := &savedFilterStore{entries: map[string]string{"short": "len:<20"}}
+	sel := Selection{Filter: "short", Name: "sun salute", Tags: "hips OR back", MinMinutes: 5, NotPlayedDays: 7, Source: "nas"}
+	query, err := sel.query(store)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := `len:<20 name:"sun salute" tag:(hips OR back) len:5.. played:>7d source:nas`
+	if query != want {
+		t.Fatalf("expected %q, got %q", want, query)
+	}
+	if _, err := parseSearchQuery(query); err != nil {
+		t.Fatalf("query does not parse: %v", err)
+	}
+	query, err = (Selection{Name: `a"b`, Source: `c:\d "e"`}).query(store)
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	if f, err := parseSearchQuery(query); err != nil || f.name != `a"b` || f.source != `c:\d "e"` {
+		t.Fatalf("expected quotes to survive %q, got %+v (%v)", query, f, err)
+	}
+	if _, err := (Selection{Filter: "missing"}).query(store); err == nil {
+		t.Fatal("expected unknown saved filter to fail")
+	}
+	if _, err := (Selection{Sort: "colour"}).selectVideos(nil, store); err == nil {
+		t.Fatal("expected unknown sort to fail")
+	}
+}
@@ -29,6 +29,16 @@ func (p *loadProgress) SetTotal(total int) {
 	p.mu.Unlock()
 }
 
+// AddTotal grows the total when several roots are scanned one after another.
+func (p *loadProgress) AddTotal(n int) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.total += n
+	p.mu.Unlock()
+}
+
 func (p *loadProgress) Increment() {
 	if p == nil {
 		return
@@ -1,34 +1,84 @@
 package app
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"codeberg.org/snonux/yoga/internal/probe"
 	"codeberg.org/snonux/yoga/internal/tags"
 )
 
-func loadVideosCmd(root, cachePath string, progress *loadProgress) tea.Cmd {
+func loadVideosCmd(roots []Root, progress *loadProgress) tea.Cmd {
 	return func() tea.Msg {
-		cache, cacheErr := loadDurationCache(cachePath)
-		videos, pending, tagErr, err := loadVideos(root, cache, progress)
-		if progress != nil {
-			progress.MarkDone()
+		progress.Reset()
+		cache, cacheErr := loadLibraryCache(roots)
+		scan, err := loadLibrary(roots, cache, progress)
+		progress.MarkDone()
+		return videosLoadedMsg{
+			videos:      scan.videos,
+			err:         err,
+			cacheErr:    cacheErr,
+			pending:     scan.pending,
+			cache:       cache,
+			tagErr:      scan.tagErr,
+			rootErr:     scan.rootErr,
+			unavailable: scan.unavailable,
 		}
-		return videosLoadedMsg{videos: videos, err: err, cacheErr: cacheErr, pending: pending, cache: cache, tagErr: tagErr}
 	}
 }
 
+// libraryScan is the combined result of loading every library root.
+type libraryScan struct {
+	videos  []video
+	pending []string
+	tagErr  error
+	// rootErr describes roots that could not be read, such as an
+	// unmounted network share.
+	rootErr     error
+	unavailable []Root
+}
+
+// loadLibrary loads every root and labels the videos with their source. A
+// root that cannot be read is reported in rootErr; err is only set when no
+// root could be read at all.
+func loadLibrary(roots []Root, cache *durationCache, progress *loadProgress) (libraryScan, error) {
+	var scan libraryScan
+	var tagErrs, rootErrs []error
+	for _, root := range roots {
+		videos, pending, tagErr, err := loadVideos(root.Path, cache, progress)
+		if err != nil {
+			rootErrs = append(rootErrs, fmt.Errorf("%s unavailable: %w", root.Label, err))
+			scan.unavailable = append(scan.unavailable, root)
+			continue
+		}
+		for i := range videos {
+			videos[i].Source = root.Label
+		}
+		scan.videos = append(scan.videos, videos...)
+		scan.pending = append(scan.pending, pending...)
+		if tagErr != nil {
+			tagErrs = append(tagErrs, tagErr)
+		}
+	}
+	scan.tagErr = errors.Join(tagErrs...)
+	scan.rootErr = errors.Join(rootErrs...)
+	if len(rootErrs) == len(roots) && len(roots) > 0 {
+		if len(roots) == 1 {
+			return scan, errors.Unwrap(rootErrs[0])
+		}
+		return scan, scan.rootErr
+	}
+	return scan, nil
+}
+
 func progressTickerCmd(progress *loadProgress) tea.Cmd {
 	if progress == nil {
 		return nil
@@ -44,40 +94,45 @@ func loadVideos(root string, cache *durationCache, progress *loadProgress) ([]vi
 	if err != nil {
 		return nil, nil, nil, err
 	}
-	if progress != nil {
-		progress.SetTotal(len(paths))
-	}
+	progress.AddTotal(len(paths))
 	videos := make([]video, 0, len(paths))
 	pending := make([]string, 0)
 	var tagErrors []string
 	for _, path := range paths {
-		info, statErr := os.Stat(path)
-		if statErr != nil {
-			videos = append(videos, video{Name: filepath.Base(path), Path: path, Err: statErr})
-			increment(progress)
-			continue
-		}
-		dur := cachedDuration(cache, path, info)
-		if dur == 0 {
+		v, needsProbe, tagErr := loadVideo(path, cache)
+		if needsProbe {
 			pending = append(pending, path)
 		}
-		tagList, tagErr := tags.Load(path)
 		if tagErr != nil {
 			tagErrors = append(tagErrors, fmt.Sprintf("%s: %v", filepath.Base(path), tagErr))
 		}
-		videos = append(videos, video{
-			Name:     filepath.Base(path),
-			Path:     path,
-			Duration: dur,
-			ModTime:  info.ModTime(),
-			Size:     info.Size(),
-			Tags:     tagList,
-		})
+		videos = append(videos, v)
 		increment(progress)
 	}
 	return videos, pending, joinErrors(tagErrors), nil
 }
 
+// loadVideo stats path and fills in cached media details and tags.
+// needsProbe reports whether the duration still has to be probed.
+func loadVideo(path string, cache *durationCache) (video, bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return video{Name: filepath.Base(path), Path: path, Err: err}, false, nil
+	}
+	md, tagErr := tags.LoadMetadata(path)
+	v := video{
+		Name:    filepath.Base(path),
+		Path:    path,
+		ModTime: info.ModTime(),
+		Size:    info.Size(),
+		Tags:    md.Tags,
+		Details: md.Details,
+	}
+	media := cachedMedia(cache, path, info)
+	v.applyMedia(media)
+	return v, media.Duration == 0, tagErr
+}
+
 func joinErrors(messages []string) error {
 	if len(messages) == 0 {
 		return nil
@@ -91,15 +146,15 @@ func increment(progress *loadProgress) {
 	}
 }
 
-func cachedDuration(cache *durationCache, path string, info os.FileInfo) time.Duration {
+func cachedMedia(cache *durationCache, path string, info os.FileInfo) probe.Info {
 	if cache == nil {
-		return 0
+		return probe.Info{}
 	}
-	dur, ok := cache.Lookup(path, info)
+	media, ok := cache.Lookup(path, info)
 	if !ok {
-		return 0
+		return probe.Info{}
 	}
-	return dur
+	return media
 }
 
 func collectVideoPaths(root string) ([]string, error) {
@@ -122,7 +177,27 @@ func collectVideoPaths(root string) ([]string, error) {
 	return paths, nil
 }
 
+// videoTreeVisitor receives the directories and videos found by
+// walkVideoTree. dir is called with the display and resolved path of every
+// directory before its entries are read; it may be nil.
+type videoTreeVisitor struct {
+	dir   func(displayPath, resolvedPath string) error
+	video func(displayPath string)
+	// skip, when set, receives directories that cannot be read and broken
+	// symlinks, and the walk continues with the next entry instead of
+	// failing.
+	skip func(displayPath string, err error)
+}
+
+func collectInto(acc *[]string) videoTreeVisitor {
+	return videoTreeVisitor{video: func(path string) { _ = recordIfVideo(path, acc) }}
+}
+
 func traverseVideoPaths(displayPath, realPath string, visited map[string]struct{}, acc *[]string) error {
+	return walkVideoTree(displayPath, realPath, visited, collectInto(acc))
+}
+
+func walkVideoTree(displayPath, realPath string, visited map[string]struct{}, visit videoTreeVisitor) error {
 	resolved, err := filepath.EvalSymlinks(realPath)
 	if err != nil {
 		resolved = realPath
@@ -132,9 +207,18 @@ func traverseVideoPaths(displayPath, realPath string, visited map[string]struct{
 		return nil
 	}
 	visited[resolved] = struct{}{}
+	if visit.dir != nil {
+		if err := visit.dir(displayPath, resolved); err != nil {
+			return err
+		}
+	}
 
 	entries, err := os.ReadDir(resolved)
 	if err != nil {
+		if visit.skip != nil {
+			visit.skip(displayPath, err)
+			return nil
+		}
 		return err
 	}
 	for _, entry := range entries {
@@ -145,47 +229,73 @@ func traverseVideoPaths(displayPath, realPath string, visited map[string]struct{
 		if mode == fs.FileMode(0) {
 			info, err = entry.Info()
 			if err != nil {
+				if visit.skip != nil {
+					visit.skip(displayChild, err)
+					continue
+				}
 				return err
 			}
 			mode = info.Mode()
 		}
 		if mode&os.ModeSymlink != 0 {
-			if err := handleSymlink(displayChild, realChild, visited, acc); err != nil {
+			if err := walkSymlink(displayChild, realChild, visited, visit); err != nil {
 				return err
 			}
 			continue
 		}
 		if mode.IsDir() {
-			if err := traverseVideoPaths(displayChild, realChild, visited, acc); err != nil {
+			if err := walkVideoTree(displayChild, realChild, visited, visit); err != nil {
 				return err
 			}
 			continue
 		}
 		if isVideo(displayChild) {
-			*acc = append(*acc, displayChild)
+			visit.video(displayChild)
 		}
 	}
 	return nil
 }
 
 func handleSymlink(displayChild, realChild string, visited map[string]struct{}, acc *[]string) error {
+	return walkSymlink(displayChild, realChild, visited, collectInto(acc))
+}
+
+func walkSymlink(displayChild, realChild string, visited map[string]struct{}, visit videoTreeVisitor) error {
 	targetPath, err := filepath.EvalSymlinks(realChild)
 	if err != nil {
-		return recordIfVideo(displayChild, acc)
+		return brokenSymlink(displayChild, err, visit)
 	}
 	targetInfo, err := os.Stat(targetPath)
 	if err != nil {
-		return recordIfVideo(displayChild, acc)
+		return brokenSymlink(displayChild, err, visit)
 	}
 	if targetInfo.IsDir() {
-		return traverseVideoPaths(displayChild, targetPath, visited, acc)
+		return walkVideoTree(displayChild, targetPath, visited, visit)
 	}
 	if isVideo(displayChild) || isVideo(targetPath) {
-		*acc = append(*acc, displayChild)
+		visit.video(displayChild)
+	}
+	return nil
+}
+
+// brokenSymlink handles a symlink whose target is missing. Without a skip
+// callback a link named like a video is still listed, so its error shows up
+// in the table.
+func brokenSymlink(displayChild string, err error, visit videoTreeVisitor) error {
+	if visit.skip != nil {
+		visit.skip(displayChild, err)
+		return nil
 	}
+	visitIfVideo(displayChild, visit)
 	return nil
 }
 
+func visitIfVideo(path string, visit videoTreeVisitor) {
+	if isVideo(path) {
+		visit.video(path)
+	}
+}
+
 func recordIfVideo(path string, acc *[]string) error {
 	if isVideo(path) {
 		*acc = append(*acc, path)
@@ -195,54 +305,23 @@ func recordIfVideo(path string, acc *[]string) error {
 
 func probeDurationsCmd(path string, cache *durationCache) tea.Cmd {
 	return func() tea.Msg {
-		dur, err := probeDuration(path)
+		media, err := probeMedia(path)
 		if err == nil && cache != nil {
 			if info, statErr := os.Stat(path); statErr == nil {
-				_ = cache.Record(path, info, dur)
+				_ = cache.Record(path, info, media)
 			}
 		}
-		return durationUpdateMsg{path: path, duration: dur, err: err}
-	}
-}
-
-func probeDuration(path string) (time.Duration, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", path)
-	out, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-	raw := strings.TrimSpace(string(out))
-	if raw == "" {
-		return 0, errors.New("empty duration")
-	}
-	seconds, err := strconv.ParseFloat(raw, 64)
-	if err != nil {
-		return 0, err
-	}
-	return time.Duration(seconds * float64(time.Second)), nil
-}
-
-func playVideoCmd(path, crop string) tea.Cmd {
-	return func() tea.Msg {
-		args := buildVLCArgs(path, crop)
-		cmd := exec.Command("vlc", args...)
-		if err := cmd.Start(); err != nil {
-			return playVideoMsg{path: path, err: err}
-		}
-		go func() { _ = cmd.Wait() }()
-		return playVideoMsg{path: path}
+		return durationUpdateMsg{path: path, media: media, err: err}
 	}
 }
 
-func buildVLCArgs(path, crop string) []string {
-	args := []string{}
-	if crop != "" {
-		args = append(args, "--crop", crop)
+// probeMedia reads the duration and stream details from the container
+// header and only runs ffprobe when the native parser cannot handle the file.
+func probeMedia(path string) (probe.Info, error) {
+	if info, err := probe.Probe(path); err == nil {
+		return info, nil
 	}
-	return append(args, path)
+	return probe.FFprobe(path)
 }
 
 func isVideo(path string) bool {
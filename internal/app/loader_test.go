@@ -1,12 +1,14 @@
 package app
 
 import (
+	"encoding/binary"
 	"os"
 	"path/filepath"
 	"runtime"
 	"testing"
 	"time"
 
+	"codeberg.org/snonux/yoga/internal/probe"
 	"codeberg.org/snonux/yoga/internal/tags"
 )
 
@@ -69,7 +71,7 @@ func TestLoadVideosWithCache(t *testing.T) {
 	if err != nil {
 		t.Fatalf("stat: %v", err)
 	}
-	_ = cache.Record(video, info, time.Minute)
+	_ = cache.Record(video, info, probe.Info{Duration: time.Minute, Height: 720})
 	progress := &loadProgress{}
 	progress.Reset()
 	videos, pending, tagErr, err := loadVideos(dir, cache, progress)
@@ -85,6 +87,9 @@ func TestLoadVideosWithCache(t *testing.T) {
 	if videos[0].Duration != time.Minute {
 		t.Fatalf("expected cached duration")
 	}
+	if videos[0].Height != 720 {
+		t.Fatalf("expected cached stream details, got %+v", videos[0])
+	}
 }
 
 func TestLoadVideosReadsTags(t *testing.T) {
@@ -118,25 +123,52 @@ func TestProbeDurationsCmdHandlesMissingBinary(t *testing.T) {
 	}
 }
 
-func TestProbeDurationSuccess(t *testing.T) {
+func TestProbeMediaFallsBackToFFprobe(t *testing.T) {
 	dir := t.TempDir()
 	script := filepath.Join(dir, "ffprobe")
-	if err := os.WriteFile(script, []byte("#!/bin/sh\necho 5\n"), 0o755); err != nil {
+	output := `{"streams":[{"codec_type":"video","codec_name":"h264","width":1280,"height":720}],"format":{"duration":"5"}}`
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho '"+output+"'\n"), 0o755); err != nil {
 		t.Fatalf("write script: %v", err)
 	}
 	oldPath := os.Getenv("PATH")
 	t.Setenv("PATH", dir+":"+oldPath)
-	dur, err := probeDuration("dummy.mp4")
+	media, err := probeMedia("dummy.mp4")
+	if err != nil {
+		t.Fatalf("probeMedia: %v", err)
+	}
+	if media.Duration != 5*time.Second {
+		t.Fatalf("expected 5s duration, got %v", media.Duration)
+	}
+	if media.Height != 720 || media.VideoCodec != "h264" {
+		t.Fatalf("expected stream details from ffprobe, got %+v", media)
+	}
+}
+
+func TestProbeMediaReadsContainerWithoutFFprobe(t *testing.T) {
+	dir := t.TempDir()
+	mvhd := make([]byte, 108)
+	binary.BigEndian.PutUint32(mvhd[0:4], uint32(len(mvhd)))
+	copy(mvhd[4:8], "mvhd")
+	binary.BigEndian.PutUint32(mvhd[20:24], 1000)
+	binary.BigEndian.PutUint32(mvhd[24:28], 7000)
+	moov := binary.BigEndian.AppendUint32(nil, uint32(8+len(mvhd)))
+	moov = append(append(moov, "moov"...), mvhd...)
+	path := filepath.Join(dir, "clip.mp4")
+	if err := os.WriteFile(path, moov, 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	t.Setenv("PATH", dir)
+	media, err := probeMedia(path)
 	if err != nil {
-		t.Fatalf("probeDuration: %v", err)
+		t.Fatalf("probeMedia: %v", err)
 	}
-	if dur != 5*time.Second {
-		t.Fatalf("expected 5s duration, got %v", dur)
+	if media.Duration != 7*time.Second {
+		t.Fatalf("expected 7s duration, got %v", media.Duration)
 	}
 }
 
 func TestPlayVideoCmdMissingBinary(t *testing.T) {
-	cmd := playVideoCmd("/no/such/file.mp4", "")
+	cmd := playVideoCmd(vlcPlayer{}, "/no/such/file.mp4", PlayOptions{})
 	msg := cmd()
 	result := msg.(playVideoMsg)
 	if result.path != "/no/such/file.mp4" {
@@ -190,3 +222,25 @@ func TestLoadVideosHandlesStatError(t *testing.T) {
 		t.Fatalf("expected stat error recorded, got %+v", videos)
 	}
 }
+
+func TestLoadLibraryLabelsSourcesAndSkipsUnavailableRoot(t *testing.T) {
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, "flow.mp4"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "unmounted")
+	roots := []Root{{Label: "home", Path: home}, {Label: "nas", Path: missing}}
+	scan, err := loadLibrary(roots, nil, nil)
+	if err != nil {
+		t.Fatalf("expected partial load to succeed, got %v", err)
+	}
+	if len(scan.videos) != 1 || scan.videos[0].Source != "home" {
+		t.Fatalf("expected labelled video, got %+v", scan.videos)
+	}
+	if scan.rootErr == nil || len(scan.unavailable) != 1 || scan.unavailable[0].Label != "nas" {
+		t.Fatalf("expected nas to be reported unavailable, got %v %+v", scan.rootErr, scan.unavailable)
+	}
+	if _, err := loadLibrary(roots[1:], nil, nil); err == nil {
+		t.Fatal("expected error when no root is readable")
+	}
+}
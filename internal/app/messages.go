@@ -1,6 +1,12 @@
 package app
 
-import "time"
+import (
+	"time"
+
+	"codeberg.org/snonux/yoga/internal/playlist"
+	"codeberg.org/snonux/yoga/internal/probe"
+	"codeberg.org/snonux/yoga/internal/tags"
+)
 
 type videosLoadedMsg struct {
 	videos   []video
@@ -9,11 +15,35 @@ type videosLoadedMsg struct {
 	pending  []string
 	cache    *durationCache
 	tagErr   error
+	rootErr  error
+	// unavailable lists the roots that could not be read.
+	unavailable []Root
 }
 
 type playVideoMsg struct {
-	path string
-	err  error
+	path    string
+	player  string
+	started time.Time
+	err     error
+	done    <-chan playbackEndedMsg
+}
+
+type playbackEndedMsg struct {
+	path     string
+	started  time.Time
+	position time.Duration
+	elapsed  time.Duration
+	err      error
+}
+
+type positionsLoadedMsg struct {
+	store *positionStore
+	err   error
+}
+
+type historyLoadedMsg struct {
+	history *playHistory
+	err     error
 }
 
 type progressUpdateMsg struct {
@@ -23,9 +53,9 @@ type progressUpdateMsg struct {
 }
 
 type durationUpdateMsg struct {
-	path     string
-	duration time.Duration
-	err      error
+	path  string
+	media probe.Info
+	err   error
 }
 
 type tagsSavedMsg struct {
@@ -34,4 +64,51 @@ type tagsSavedMsg struct {
 	err  error
 }
 
+type metadataSavedMsg struct {
+	path     string
+	metadata tags.Metadata
+	// status replaces the default message on success.
+	status string
+	err    error
+}
+
+// tagJobDoneMsg reports the sidecars a tag edit rewrote, by video path,
+// and the ones it could not.
+type tagJobDoneMsg struct {
+	edit     tagEdit
+	updated  map[string][]string
+	failures []tagFailure
+}
+
+type tagJobProgressMsg struct{}
+
 type reindexVideosMsg struct{}
+
+type playlistSavedMsg struct {
+	name string
+	err  error
+}
+
+type playlistsListedMsg struct {
+	names []string
+	err   error
+}
+
+type playlistLoadedMsg struct {
+	playlist playlist.Playlist
+	err      error
+}
+
+type playlistExportedMsg struct {
+	paths []string
+	err   error
+}
+
+type watcherStartedMsg struct {
+	watcher *libraryWatcher
+	err     error
+}
+
+type libraryChangedMsg struct {
+	changes []libraryChange
+}
@@ -9,6 +9,9 @@ import (
 	"github.com/charmbracelet/bubbles/table"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"codeberg.org/snonux/yoga/internal/tags"
 )
 
 type sortField int
@@ -17,82 +20,180 @@ const (
 	sortByName sortField = iota
 	sortByDuration
 	sortByAge
+	sortByLastPlayed
+	sortByScore
+	sortByRating
 )
 
 const (
 	preferredNameColumnWidth     = 40
 	preferredDurationColumnWidth = 12
 	preferredAgeColumnWidth      = 14
+	preferredPlayedColumnWidth   = 16
+	preferredRatingColumnWidth   = 9
 	preferredTagsColumnWidth     = 28
 	nameColumnFloorWidth         = 16
 	durationColumnFloorWidth     = 8
 	ageColumnFloorWidth          = 10
+	playedColumnFloorWidth       = 10
+	ratingColumnFloorWidth       = 6
 	tagsColumnFloorWidth         = 12
+	preferredMediaColumnWidth    = 12
+	mediaColumnFloorWidth        = 8
 )
 
 type model struct {
 	table            table.Model
+	columns          []columnID
 	videos           []video
 	filtered         []video
 	filters          filterState
 	inputs           filterInputs
 	showFilters      bool
+	showSession      bool
+	sessionInputs    filterInputs
+	sessionPlan      *sessionPlan
+	sessionKey       string
+	sessionErr       string
+	upNext           []video
+	sequencePath     string
+	sequenceLabel    string
+	queue            []video
+	queueCursor      int
+	queueFocus       bool
+	queueName        string
+	prompt           *promptState
+	picker           *pickerState
+	quick            *quickFilterState
 	editingTags      bool
+	details          *detailsEditor
+	tagManager       *tagManagerState
+	tagJob           *tagJob
+	stats            *libraryStats
 	sortField        sortField
 	sortAscending    bool
 	statusMessage    string
 	loading          bool
 	err              error
 	root             string
+	roots            []Root
 	progress         *loadProgress
-	cachePath        string
+	watcher          *libraryWatcher
+	indexed          bool
 	cache            *durationCache
+	positionsPath    string
+	positions        *positionStore
+	historyPath      string
+	history          *playHistory
+	savedFilters     *savedFilterStore
 	pendingDurations []string
 	durationTotal    int
 	durationDone     int
 	durationInFlight int
 	cropValue        string
 	cropEnabled      bool
+	player           Player
+	fullscreen       bool
+	volume           int
 	tagInput         textinput.Model
 	tagEditPath      string
+	tagComplete      tagCompletion
+	tagPolicy        tags.Policy
 	baseStatus       string
 	showHelp         bool
 	viewportWidth    int
+	// collection names the saved filter currently applied, if any.
+	collection string
+	// weightedRandom makes x favour highly rated videos.
+	weightedRandom bool
+	// marked holds the paths of videos marked for bulk tag edits. While
+	// visual is set the rows between visualAnchor and the cursor count too.
+	marked       map[string]bool
+	visual       bool
+	visualAnchor int
+	// loadWarnings are problems with optional files read by newModel; the
+	// status line reports them once the library is loaded.
+	loadWarnings []error
 }
 
 func newModel(opts Options) (model, error) {
-	tbl := buildTable()
+	roots := opts.libraryRoots()
+	columns, err := parseColumns(opts.Columns)
+	if err != nil {
+		return model{}, err
+	}
+	if len(opts.Columns) == 0 && len(roots) > 1 {
+		columns = multiRootColumns
+	}
+	tbl := buildTable(columns)
 	inputs := buildFilterInputs()
 	inputs.fields[0].Focus()
 	tagInput := buildTagInput()
+	player, err := NewPlayer(opts.Player)
+	if err != nil {
+		return model{}, err
+	}
 
 	progress := &loadProgress{}
-	cachePath := filepath.Join(opts.Root, ".video_duration_cache.json")
+	positionsPath := filepath.Join(opts.Root, ".video_positions.json")
+	historyPath := filepath.Join(opts.Root, ".video_history.json")
+	// A broken saved filter file only stops the start when --filter needs
+	// it; otherwise it is reported once the library is loaded.
+	var loadWarnings []error
+	savedFilters, err := loadSavedFilterStore(filepath.Join(opts.Root, ".video_filters.json"))
+	if err != nil {
+		if opts.Filter != "" {
+			return model{}, err
+		}
+		loadWarnings = append(loadWarnings, fmt.Errorf("saved filter warning: %w", err))
+	}
+	// Like the saved filters, a broken tag policy is only reported; tags
+	// are saved as typed until it is fixed.
+	tagPolicy, err := tags.LoadPolicy(filepath.Join(opts.Root, tags.PolicyFile))
+	if err != nil {
+		loadWarnings = append(loadWarnings, fmt.Errorf("tag policy warning: %w", err))
+	}
+	var filters filterState
+	if opts.Filter != "" {
+		if filters, err = savedFilters.lookupSavedFilter(opts.Filter); err != nil {
+			return model{}, err
+		}
+	}
 
-	return model{
+	m := model{
 		table:         tbl,
+		columns:       columns,
 		inputs:        inputs,
+		sessionInputs: buildSessionInputs(),
 		tagInput:      tagInput,
 		sortField:     sortByName,
 		sortAscending: true,
 		statusMessage: "Scanning for videos...",
 		loading:       true,
 		root:          opts.Root,
+		roots:         roots,
 		progress:      progress,
-		cachePath:     cachePath,
+		positionsPath: positionsPath,
+		historyPath:   historyPath,
+		savedFilters:  savedFilters,
+		filters:       filters,
+		collection:    opts.Filter,
+		tagPolicy:     tagPolicy,
 		cropValue:     opts.Crop,
 		cropEnabled:   opts.Crop != "",
+		player:        player,
+		fullscreen:    opts.Fullscreen,
+		volume:        opts.Volume,
 		showHelp:      true,
-	}, nil
+	}
+	m.weightedRandom = opts.WeightedRandom
+	m.loadWarnings = loadWarnings
+	m.syncFilterInputs()
+	return m, nil
 }
 
-func buildTable() table.Model {
-	columns := makeColumns(
-		preferredNameColumnWidth,
-		preferredDurationColumnWidth,
-		preferredAgeColumnWidth,
-		preferredTagsColumnWidth,
-	)
+func buildTable(ids []columnID) table.Model {
+	columns := makeColumns(ids, preferredWidths(ids))
 	tbl := table.New(
 		table.WithColumns(columns),
 		table.WithFocused(true),
@@ -119,12 +220,32 @@ func buildFilterInputs() filterInputs {
 	maxInput.CharLimit = 4
 
 	tagInput := textinput.New()
-	tagInput.Placeholder = "tag substring"
+	tagInput.Placeholder = "tag query"
 	tagInput.Prompt = "Tags: "
 	tagInput.CharLimit = 256
 
+	notPlayedInput := textinput.New()
+	notPlayedInput.Placeholder = "days"
+	notPlayedInput.Prompt = "Not played in: "
+	notPlayedInput.CharLimit = 4
+
+	heightInput := textinput.New()
+	heightInput.Placeholder = "pixels"
+	heightInput.Prompt = "Min height: "
+	heightInput.CharLimit = 5
+
+	audioInput := textinput.New()
+	audioInput.Placeholder = "language code"
+	audioInput.Prompt = "Audio: "
+	audioInput.CharLimit = 16
+
+	sourceInput := textinput.New()
+	sourceInput.Placeholder = "root label"
+	sourceInput.Prompt = "Source: "
+	sourceInput.CharLimit = 64
+
 	return filterInputs{
-		fields: []textinput.Model{nameInput, minInput, maxInput, tagInput},
+		fields: []textinput.Model{nameInput, minInput, maxInput, tagInput, notPlayedInput, heightInput, audioInput, sourceInput},
 		focus:  0,
 	}
 }
@@ -137,20 +258,16 @@ func buildTagInput() textinput.Model {
 	return input
 }
 
-func makeColumns(nameWidth, durationWidth, ageWidth, tagsWidth int) []table.Column {
-	return []table.Column{
-		{Title: headerStyle.Render("Name"), Width: nameWidth},
-		{Title: headerStyle.Render("Duration"), Width: durationWidth},
-		{Title: headerStyle.Render("Age"), Width: ageWidth},
-		{Title: headerStyle.Render("Tags"), Width: tagsWidth},
-	}
-}
-
 func (m model) Init() tea.Cmd {
 	if m.progress != nil {
 		m.progress.Reset()
 	}
-	loadCmd := loadVideosCmd(m.root, m.cachePath, m.progress)
+	loadCmd := tea.Batch(
+		loadVideosCmd(m.roots, m.progress),
+		positionsLoadedCmd(m.positionsPath),
+		historyLoadedCmd(m.historyPath),
+		startWatcherCmd(m.rootPaths()...),
+	)
 	if m.progress != nil {
 		return tea.Batch(loadCmd, progressTickerCmd(m.progress))
 	}
@@ -168,11 +285,35 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case videosLoadedMsg:
 		return m.handleVideosLoaded(typed)
 	case playVideoMsg:
-		return m.handlePlayVideo(typed), nil
+		return m.handlePlayVideo(typed), waitForPlaybackCmd(typed.done)
+	case playbackEndedMsg:
+		return m.handlePlaybackEnded(typed)
+	case positionsLoadedMsg:
+		return m.handlePositionsLoaded(typed)
+	case historyLoadedMsg:
+		return m.handleHistoryLoaded(typed)
 	case reindexVideosMsg:
 		return m.handleReindexVideos(typed)
 	case tagsSavedMsg:
 		return m.handleTagsSaved(typed)
+	case metadataSavedMsg:
+		return m.handleMetadataSaved(typed)
+	case tagJobProgressMsg:
+		return m.handleTagJobProgress(typed)
+	case tagJobDoneMsg:
+		return m.handleTagJobDone(typed)
+	case playlistSavedMsg:
+		return m.handlePlaylistSaved(typed)
+	case playlistsListedMsg:
+		return m.handlePlaylistsListed(typed)
+	case playlistLoadedMsg:
+		return m.handlePlaylistLoaded(typed)
+	case playlistExportedMsg:
+		return m.handlePlaylistExported(typed)
+	case watcherStartedMsg:
+		return m.handleWatcherStarted(typed)
+	case libraryChangedMsg:
+		return m.handleLibraryChanged(typed)
 	case tea.WindowSizeMsg:
 		return m.handleWindowSize(typed)
 	default:
@@ -184,23 +325,51 @@ func (m model) View() string {
 	if m.loading {
 		return statusStyle.Render("Loading videos, please wait...")
 	}
+	if m.stats != nil {
+		return m.renderStats()
+	}
 	body := m.renderBody()
+	if m.prompt != nil {
+		return body + "\n\n" + m.renderPrompt()
+	}
+	if m.quick != nil {
+		return body + "\n" + m.renderQuickFilter()
+	}
+	if m.picker != nil {
+		return body + "\n\n" + m.renderPicker()
+	}
 	if m.editingTags {
 		return body + "\n\n" + m.renderTagModal()
 	}
+	if m.details != nil {
+		return body + "\n\n" + m.renderDetailsModal()
+	}
+	if m.tagManager != nil {
+		return body + "\n\n" + m.renderTagManager()
+	}
 	if m.showFilters {
 		return body + "\n\n" + m.renderFilterModal()
 	}
+	if m.showSession {
+		return body + "\n\n" + m.renderSessionModal()
+	}
 	return body
 }
 
 func (m model) renderBody() string {
 	helpLines := []string{
 		"↑/↓ navigate  •  enter play  •  s sort  •  / filter  •  c crop  •  t edit tags  •  i re-index  •  q quit",
+		"enter resumes where you stopped  •  b play from beginning  •  p sort by last played  •  m sort by match  •  : search  •  ctrl+f quick filter",
+		"B build session  •  S stop after current video  •  + queue video  •  P queue panel  •  F saved filters  •  W save filter  •  e edit details  •  T manage tags  •  I stats",
+		"space mark  •  v mark range  •  A mark all filtered  •  esc clear marks  •  t with marks: add/remove tags",
+		"1-5 rate (again to clear)  •  * favourite  •  R sort by rating  •  x random pick  •  X weight random by rating",
 	}
 	info := statusStyle.Render(m.statusText())
 	progressLine := m.renderProgressLine()
 	content := tableStyle.Render(m.table.View())
+	if m.queueVisible() {
+		content = lipgloss.JoinHorizontal(lipgloss.Top, content, m.renderQueuePanel(lipgloss.Height(content)))
+	}
 	parts := []string{content}
 	if progressLine != "" {
 		parts = append(parts, progressLine)
@@ -216,6 +385,12 @@ func (m model) renderBody() string {
 func (m model) statusText() string {
 	status := strings.TrimSpace(m.statusMessage)
 	base := strings.TrimSpace(m.baseStatus)
+	if m.collection != "" && base != "" {
+		base = fmt.Sprintf("[%s] %s", m.collection, base)
+	}
+	if count := len(m.markedVideos()); count > 0 && base != "" {
+		base = fmt.Sprintf("%s (%d marked)", base, count)
+	}
 	if base == "" {
 		return status
 	}
@@ -256,47 +431,28 @@ func (m model) handleWindowSize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m model) visibleColumns() []columnID {
+	if len(m.columns) == 0 {
+		return defaultColumns
+	}
+	return m.columns
+}
+
 func (m *model) resizeColumns(totalWidth int) {
 	if totalWidth <= 0 {
 		return
 	}
+	if m.queueVisible() {
+		totalWidth -= queuePanelWidth
+	}
 	frame := tableStyle.GetHorizontalFrameSize()
 	contentWidth := totalWidth - frame
-	minWidth := nameColumnFloorWidth + durationColumnFloorWidth + ageColumnFloorWidth + tagsColumnFloorWidth
-	if contentWidth < minWidth {
+	ids := m.visibleColumns()
+	if minWidth := floorWidth(ids); contentWidth < minWidth {
 		contentWidth = minWidth
 	}
-	preferred := preferredNameColumnWidth + preferredDurationColumnWidth + preferredAgeColumnWidth + preferredTagsColumnWidth
-	nameWidth := preferredNameColumnWidth
-	durationWidth := preferredDurationColumnWidth
-	ageWidth := preferredAgeColumnWidth
-	tagsWidth := preferredTagsColumnWidth
-	if contentWidth >= preferred {
-		extra := contentWidth - preferred
-		nameWidth += extra
-	} else {
-		deficit := preferred - contentWidth
-		if deficit > 0 {
-			reduce := min(deficit, nameWidth-nameColumnFloorWidth)
-			nameWidth -= reduce
-			deficit -= reduce
-		}
-		if deficit > 0 {
-			reduce := min(deficit, tagsWidth-tagsColumnFloorWidth)
-			tagsWidth -= reduce
-			deficit -= reduce
-		}
-		if deficit > 0 {
-			reduce := min(deficit, ageWidth-ageColumnFloorWidth)
-			ageWidth -= reduce
-			deficit -= reduce
-		}
-		if deficit > 0 {
-			reduce := min(deficit, durationWidth-durationColumnFloorWidth)
-			durationWidth -= reduce
-		}
-	}
-	m.table.SetColumns(makeColumns(nameWidth, durationWidth, ageWidth, tagsWidth))
+	widths := fitColumnWidths(ids, contentWidth)
+	m.table.SetColumns(makeColumns(ids, widths))
 	m.table.SetWidth(contentWidth)
 }
 
@@ -323,16 +479,20 @@ func (m model) updateTable(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m model) handlePlayVideo(msg playVideoMsg) model {
 	if msg.err != nil {
-		m.statusMessage = fmt.Sprintf("Failed to launch VLC: %v", msg.err)
+		m.statusMessage = fmt.Sprintf("Failed to launch %s: %v", m.playerName(msg), msg.err)
+		if msg.path == m.sequencePath {
+			m.clearSequence()
+		}
 		return m
 	}
-	m.statusMessage = fmt.Sprintf("Playing via VLC: %s", trimPath(msg.path))
+	m.statusMessage = fmt.Sprintf("Playing via %s: %s", m.playerName(msg), trimPath(msg.path))
+	m.recordPlayStart(msg.path, msg.started)
 	return m
 }
 
 func (m model) handleReindexVideos(msg reindexVideosMsg) (tea.Model, tea.Cmd) {
 	m.statusMessage = "Re-indexing videos..."
-	return m, loadVideosCmd(m.root, m.cachePath, m.progress)
+	return m, loadVideosCmd(m.roots, m.progress)
 }
 
 func (m model) handleVideosLoaded(msg videosLoadedMsg) (tea.Model, tea.Cmd) {
@@ -342,29 +502,37 @@ func (m model) handleVideosLoaded(msg videosLoadedMsg) (tea.Model, tea.Cmd) {
 		m.statusMessage = fmt.Sprintf("error: %v", msg.err)
 	}
 
-	if len(m.videos) == 0 {
-		m.videos = msg.videos
-	} else {
-		existingVideos := make(map[string]int)
-		for i, v := range m.videos {
-			existingVideos[v.Path] = i
-		}
-
-		for _, newVideo := range msg.videos {
-			if i, ok := existingVideos[newVideo.Path]; ok {
-				m.videos[i] = newVideo
-			} else {
-				m.videos = append(m.videos, newVideo)
+	reindex := m.indexed && msg.err == nil
+	selectedPath := m.currentSelectionPath()
+	var diff libraryDiff
+	if reindex {
+		// Videos of a root that went away (an unmounted share) are kept
+		// rather than reported as deleted.
+		msg.videos = append(msg.videos, m.videosWithin(msg.unavailable)...)
+		var removed []string
+		msg.videos, msg.pending, diff, removed = mergeReloadedVideos(m.videos, msg.videos, msg.pending)
+		if msg.cache.Forget(removed...) {
+			if err := msg.cache.Flush(); err != nil && msg.cacheErr == nil {
+				msg.cacheErr = err
 			}
 		}
 	}
+	if msg.err == nil {
+		m.videos = msg.videos
+		m.indexed = true
+	}
 
+	m.applyHistory()
 	m.cache = msg.cache
 	m.pendingDurations = msg.pending
 	m.durationTotal = len(msg.pending)
 	m.durationDone = 0
 	m.applyFiltersAndSort()
+	m.restoreSelection(selectedPath)
 	m.updateStatusAfterLoad(msg)
+	if reindex {
+		m.statusMessage = fmt.Sprintf("Re-indexed: %s", diff)
+	}
 	m.durationInFlight = 0
 	if len(msg.pending) == 0 {
 		return m, nil
@@ -376,6 +544,10 @@ func (m model) handleVideosLoaded(msg videosLoadedMsg) (tea.Model, tea.Cmd) {
 func (m *model) updateStatusAfterLoad(msg videosLoadedMsg) {
 	if len(m.filtered) == 0 {
 		m.baseStatus = "No videos found"
+		if msg.rootErr != nil {
+			m.baseStatus = fmt.Sprintf("No videos found (%v)", msg.rootErr)
+		}
+		m.baseStatus = m.withLoadWarnings(m.baseStatus)
 		m.statusMessage = m.baseStatus
 		return
 	}
@@ -394,8 +566,18 @@ func (m *model) updateStatusAfterLoad(msg videosLoadedMsg) {
 	if msg.tagErr != nil {
 		status = fmt.Sprintf("%s (tag warning: %v)", status, msg.tagErr)
 	}
-	m.baseStatus = status
-	m.statusMessage = status
+	if msg.rootErr != nil {
+		status = fmt.Sprintf("%s (%v)", status, msg.rootErr)
+	}
+	m.baseStatus = m.withLoadWarnings(status)
+	m.statusMessage = m.baseStatus
+}
+
+func (m model) withLoadWarnings(status string) string {
+	for _, warning := range m.loadWarnings {
+		status = fmt.Sprintf("%s (%v)", status, warning)
+	}
+	return status
 }
 
 func (m *model) startDurationWorkers() tea.Cmd {
@@ -435,6 +617,17 @@ func (m *model) dequeueDurationCmd() tea.Cmd {
 	return probeDurationsCmd(path, m.cache)
 }
 
+func (m model) playerName(msg playVideoMsg) string {
+	if msg.player != "" {
+		return msg.player
+	}
+	return m.player.Name()
+}
+
+func (m model) playOptions() PlayOptions {
+	return PlayOptions{Crop: m.activeCrop(), Fullscreen: m.fullscreen, Volume: m.volume}
+}
+
 func (m model) activeCrop() string {
 	if m.cropEnabled && m.cropValue != "" {
 		return m.cropValue
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Saved filters act as live collections: the active one is kept in
+// m.filters, so every applyFiltersAndSort after a probe, tag edit, history
+// update or library change re-evaluates it against m.videos.
+
+func (m model) openSavedFilterPicker() (tea.Model, tea.Cmd) {
+	names := m.savedFilters.Names()
+	if len(names) == 0 {
+		m.statusMessage = "No saved filters (W saves the current filters)"
+		return m, nil
+	}
+	m = m.openPicker("Saved filters", names, func(m model, name string) (tea.Model, tea.Cmd) {
+		return m.applySavedFilter(name)
+	})
+	return m, nil
+}
+
+func (m model) applySavedFilter(name string) (tea.Model, tea.Cmd) {
+	filters, err := m.savedFilters.lookupSavedFilter(name)
+	if err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	selectedPath := m.currentSelectionPath()
+	m.filters = filters
+	m.collection = name
+	m.syncFilterInputs()
+	m.applyFiltersAndSort()
+	m.restoreSelection(selectedPath)
+	m.statusMessage = fmt.Sprintf("Collection %q (%d videos)", name, len(m.filtered))
+	return m, nil
+}
+
+func (m model) openSaveFilterPrompt() (tea.Model, tea.Cmd) {
+	if !m.filtersActive() {
+		m.statusMessage = "No filters to save"
+		return m, nil
+	}
+	m = m.openPrompt("Save current filters as", m.collection, saveFilterFromPrompt)
+	return m, nil
+}
+
+func saveFilterFromPrompt(m model, name string) (tea.Model, tea.Cmd) {
+	if err := validateSavedFilterName(name); err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	m.savedFilters.Save(name, formatSearchQuery(m.filters))
+	if err := m.savedFilters.Flush(); err != nil {
+		m.statusMessage = fmt.Sprintf("Saving filter failed: %v", err)
+		return m, nil
+	}
+	m.collection = name
+	m.statusMessage = fmt.Sprintf("Saved filter %q", name)
+	return m, nil
+}
+
+func validateSavedFilterName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("filter name must not be empty")
+	}
+	return nil
+}
@@ -0,0 +1,185 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// Field order of the metadata editor.
+const (
+	detailTags = iota
+	detailInstructor
+	detailStyle
+	detailDifficulty
+	detailFocus
+	detailProps
+	detailIntensity
+	detailRating
+	detailNotes
+)
+
+// detailsEditor is the form behind the `e` key. It edits the whole sidecar
+// of the video at path.
+type detailsEditor struct {
+	path   string
+	inputs filterInputs
+}
+
+func buildDetailInputs(v video) filterInputs {
+	field := func(prompt, placeholder, value string, limit int) textinput.Model {
+		input := textinput.New()
+		input.Prompt = prompt
+		input.Placeholder = placeholder
+		input.CharLimit = limit
+		input.SetValue(value)
+		input.CursorEnd()
+		return input
+	}
+	level := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	d := v.Details
+	return filterInputs{fields: []textinput.Model{
+		field("Tags: ", "hatha, hips", strings.Join(v.Tags, ", "), 512),
+		field("Instructor: ", "", d.Instructor, 128),
+		field("Style: ", "yin", d.Style, 128),
+		field("Difficulty (1-5): ", "", level(d.Difficulty), 1),
+		field("Focus areas: ", "hips, back", strings.Join(d.Focus, ", "), 256),
+		field("Props: ", "block, strap", strings.Join(d.Props, ", "), 256),
+		field("Intensity (1-5): ", "", level(d.Intensity), 1),
+		field("Rating (1-5): ", "", level(d.Rating), 1),
+		field("Notes: ", "", d.Notes, 1024),
+	}}
+}
+
+func (m model) openDetailsEditor() (tea.Model, tea.Cmd) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.filtered) {
+		m.statusMessage = "No videos to edit"
+		return m, nil
+	}
+	v := m.filtered[idx]
+	m.details = &detailsEditor{path: v.Path, inputs: buildDetailInputs(v)}
+	m.details.inputs.fields[0].Focus()
+	m.statusMessage = fmt.Sprintf("Editing details for %s", v.Name)
+	return m, textinput.Blink
+}
+
+func (m model) handleDetailsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	editor := *m.details
+	fields := append([]textinput.Model(nil), editor.inputs.fields...)
+	editor.inputs.fields = fields
+	switch msg.String() {
+	case "esc":
+		m.details = nil
+		m.statusMessage = "Details edit cancelled"
+		return m, nil
+	case "enter":
+		md, err := metadataFromInputs(fields)
+		if err != nil {
+			m.statusMessage = err.Error()
+			return m, nil
+		}
+		md.Tags = m.tagPolicy.Normalize(md.Tags)
+		m.details = nil
+		m.statusMessage = fmt.Sprintf("Saving details for %s", filepath.Base(editor.path))
+		return m, saveMetadataCmd(editor.path, md)
+	case "tab", "shift+tab":
+		fields[editor.inputs.focus].Blur()
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = len(fields) - 1
+		}
+		editor.inputs.focus = (editor.inputs.focus + step) % len(fields)
+		fields[editor.inputs.focus].Focus()
+		m.details = &editor
+		return m, nil
+	}
+	var cmd tea.Cmd
+	fields[editor.inputs.focus], cmd = fields[editor.inputs.focus].Update(msg)
+	m.details = &editor
+	return m, cmd
+}
+
+// metadataFromInputs validates the form. Blank levels mean "not set".
+func metadataFromInputs(fields []textinput.Model) (tags.Metadata, error) {
+	value := func(i int) string { return strings.TrimSpace(fields[i].Value()) }
+	md := tags.Metadata{
+		Tags: parseTagInput(value(detailTags)),
+		Details: tags.Details{
+			Instructor: value(detailInstructor),
+			Style:      value(detailStyle),
+			Focus:      parseTagInput(value(detailFocus)),
+			Props:      parseTagInput(value(detailProps)),
+			Notes:      value(detailNotes),
+		},
+	}
+	levels := []struct {
+		name  string
+		index int
+		dst   *int
+	}{
+		{"difficulty", detailDifficulty, &md.Difficulty},
+		{"intensity", detailIntensity, &md.Intensity},
+		{"rating", detailRating, &md.Rating},
+	}
+	for _, level := range levels {
+		text := value(level.index)
+		if text == "" {
+			continue
+		}
+		n, err := strconv.Atoi(text)
+		if err != nil || n < 1 || n > tags.MaxLevel {
+			return tags.Metadata{}, fmt.Errorf("%s must be between 1 and %d", level.name, tags.MaxLevel)
+		}
+		*level.dst = n
+	}
+	return md, nil
+}
+
+func (m model) handleMetadataSaved(msg metadataSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Details save error: %v", msg.err)
+		return m, nil
+	}
+	for i := range m.videos {
+		if m.videos[i].Path == msg.path {
+			m.videos[i].Tags = msg.metadata.Tags
+			m.videos[i].Details = msg.metadata.Details
+			break
+		}
+	}
+	m.applyFiltersAndSort()
+	m.restoreSelection(msg.path)
+	m.statusMessage = msg.status
+	if msg.status == "" {
+		m.statusMessage = fmt.Sprintf("Details saved for %s", filepath.Base(msg.path))
+	}
+	return m, nil
+}
+
+func (m model) renderDetailsModal() string {
+	var b strings.Builder
+	b.WriteString("Edit details\n")
+	b.WriteString("(lists comma separated, levels 1-5, blank to unset)\n\n")
+	for i, field := range m.details.inputs.fields {
+		line := field.View()
+		if i == m.details.inputs.focus {
+			line = highlightStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\nEnter to save, Esc to cancel")
+	return filterStyle.Render(b.String())
+}
@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func TestDetailsEditorSavesSidecar(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flow.mp4")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	m, err := newModel(Options{Root: dir})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "flow.mp4", Path: path, Tags: []string{"hips"}}}
+	m.applyFiltersAndSort()
+
+	modelAny, _ := m.handleKeyMsg(keyMsg("e"))
+	m = modelAny.(model)
+	if m.details == nil || m.details.inputs.fields[detailTags].Value() != "hips" {
+		t.Fatal("expected details editor with the current tags")
+	}
+	m.details.inputs.fields[detailInstructor].SetValue("Ada")
+	m.details.inputs.fields[detailDifficulty].SetValue("7")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.details == nil || m.statusMessage != "difficulty must be between 1 and 5" {
+		t.Fatalf("expected validation error, got %q", m.statusMessage)
+	}
+	m.details.inputs.fields[detailDifficulty].SetValue("2")
+	m.details.inputs.fields[detailProps].SetValue("block, strap")
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.details != nil || cmd == nil {
+		t.Fatal("expected editor to close and save")
+	}
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(model)
+	got := m.videos[0].Details
+	if got.Instructor != "Ada" || got.Difficulty != 2 || len(got.Props) != 2 || m.videos[0].Tags[0] != "hips" {
+		t.Fatalf("unexpected details %+v", m.videos[0])
+	}
+	md, err := tags.LoadMetadata(path)
+	if err != nil || md.Version != tags.SchemaVersion || md.Instructor != "Ada" {
+		t.Fatalf("unexpected sidecar %+v (%v)", md, err)
+	}
+}
+
+func TestDetailsEditorKeepsQuitKeyForNotes(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "a.mp4"}}
+	m.applyFiltersAndSort()
+	modelAny, _ := m.openDetailsEditor()
+	m = modelAny.(model)
+	for range detailNotes {
+		modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyTab})
+		m = modelAny.(model)
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("q"))
+	m = modelAny.(model)
+	if m.details == nil {
+		t.Fatal("expected q to be typed into the notes field")
+	}
+	if m.details.inputs.fields[detailNotes].Value() != "q" {
+		t.Fatalf("unexpected notes %q", m.details.inputs.fields[detailNotes].Value())
+	}
+}
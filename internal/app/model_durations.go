@@ -3,14 +3,15 @@ package app
 import (
 	"fmt"
 	"path/filepath"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/probe"
 )
 
 func (m model) handleDurationUpdate(msg durationUpdateMsg) (tea.Model, tea.Cmd) {
 	if msg.path != "" {
-		m.updateVideoDuration(msg.path, msg.duration, msg.err)
+		m.updateVideoMedia(msg.path, msg.media, msg.err)
 		m.durationDone++
 		m.updateStatusForDuration(msg)
 	}
@@ -58,12 +59,12 @@ func (m *model) restoreSelection(path string) {
 	}
 }
 
-func (m *model) updateVideoDuration(path string, dur time.Duration, err error) {
+func (m *model) updateVideoMedia(path string, media probe.Info, err error) {
 	for i := range m.videos {
 		if m.videos[i].Path != path {
 			continue
 		}
-		m.videos[i].Duration = dur
+		m.videos[i].applyMedia(media)
 		m.videos[i].Err = err
 		return
 	}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func historyLoadedCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		history, err := loadPlayHistory(path)
+		return historyLoadedMsg{history: history, err: err}
+	}
+}
+
+func (m model) handleHistoryLoaded(msg historyLoadedMsg) (tea.Model, tea.Cmd) {
+	m.history = msg.history
+	if msg.err != nil {
+		// Like loadSnapshot, drop the history so no play is ever flushed
+		// over the file that failed to load.
+		m.history = nil
+		m.statusMessage = fmt.Sprintf("Play history warning: %v (plays are not recorded until it is fixed)", msg.err)
+	}
+	selectedPath := m.currentSelectionPath()
+	m.applyHistory()
+	m.applyFiltersAndSort()
+	m.restoreSelection(selectedPath)
+	return m, nil
+}
+
+// applyHistory copies the per-video play statistics into m.videos.
+func (m *model) applyHistory() {
+	stats := m.history.Stats()
+	for i := range m.videos {
+		s := stats[m.videos[i].Path]
+		m.videos[i].LastPlayed = s.LastPlayed
+		m.videos[i].PlayCount = s.Count
+	}
+}
+
+func (m *model) recordPlayStart(path string, started time.Time) {
+	if m.history == nil {
+		return
+	}
+	if started.IsZero() {
+		started = time.Now()
+	}
+	m.history.RecordStart(path, started)
+	for i := range m.videos {
+		if m.videos[i].Path == path {
+			m.videos[i].LastPlayed = started
+			m.videos[i].PlayCount++
+			break
+		}
+	}
+	selectedPath := m.currentSelectionPath()
+	m.applyFiltersAndSort()
+	m.restoreSelection(selectedPath)
+	if err := m.history.Flush(); err != nil {
+		m.statusMessage = fmt.Sprintf("Play history save error: %v", err)
+	}
+}
+
+func (m *model) recordPlayEnd(msg playbackEndedMsg) {
+	if m.history == nil || msg.started.IsZero() {
+		return
+	}
+	m.history.RecordEnd(msg.path, msg.started, msg.elapsed)
+	if err := m.history.Flush(); err != nil {
+		m.statusMessage = fmt.Sprintf("Play history save error: %v", err)
+	}
+}
@@ -2,24 +2,47 @@ package app
 
 import (
 	"fmt"
-	"math/rand"
 
 	tea "github.com/charmbracelet/bubbletea"
 )
 
 func (m model) handleKeyMsg(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.prompt != nil && msg.String() != "ctrl+c" {
+		return m.handlePromptKey(msg)
+	}
+	if m.quick != nil && msg.String() != "ctrl+c" {
+		return m.handleQuickFilterKey(msg)
+	}
+	if m.details != nil && msg.String() != "ctrl+c" {
+		return m.handleDetailsKey(msg)
+	}
+	if m.showSession && msg.String() != "ctrl+c" {
+		return m.handleSessionKey(msg)
+	}
 	if cmd, handled := globalKeyHandler(msg); handled {
 		return m, cmd
 	}
 	if m.loading {
 		return m, nil
 	}
+	if m.picker != nil {
+		return m.handlePickerKey(msg)
+	}
 	if m.editingTags {
 		return m.handleTagKey(msg)
 	}
+	if m.tagManager != nil {
+		return m.handleTagManagerKey(msg)
+	}
+	if m.stats != nil {
+		return m.handleStatsKey(msg)
+	}
 	if m.showFilters {
 		return m.handleFilterKey(msg)
 	}
+	if m.queueFocus {
+		return m.handleQueueKey(msg)
+	}
 	return m.handleTableKey(msg)
 }
 
@@ -58,6 +81,7 @@ func (m *model) applyFiltersFromInputs() tea.Cmd {
 		return nil
 	}
 	m.showFilters = false
+	m.collection = ""
 	m.applyFiltersAndSort()
 	m.statusMessage = fmt.Sprintf("Filters applied (%d videos)", len(m.filtered))
 	return nil
@@ -77,18 +101,55 @@ func (m model) handleTableKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "/", "f":
 		return m.openFilters()
+	case ":":
+		return m.openSearchBar(formatSearchQuery(m.filters))
+	case "ctrl+f":
+		return m.openQuickFilter()
+	case "F":
+		return m.openSavedFilterPicker()
+	case "W":
+		return m.openSaveFilterPrompt()
 	case "enter":
 		return m.playSelection()
+	case "b":
+		return m.playSelectionFromStart()
 	case "n":
 		return m.sortAndReport(sortByName)
 	case "l":
 		return m.sortAndReport(sortByDuration)
 	case "a":
 		return m.sortAndReport(sortByAge)
+	case "p":
+		return m.sortAndReport(sortByLastPlayed)
+	case "m":
+		return m.sortAndReport(sortByScore)
+	case "R":
+		return m.sortAndReport(sortByRating)
+	case "0", "1", "2", "3", "4", "5":
+		return m.rateSelection(int(msg.String()[0] - '0'))
+	case "*":
+		return m.toggleFavorite()
 	case "c":
 		return m.toggleCrop()
 	case "t":
+		if len(m.markedVideos()) > 0 {
+			return m.openBulkTagPrompt()
+		}
 		return m.openTagEditor()
+	case " ":
+		return m.toggleMark()
+	case "v":
+		return m.toggleVisual()
+	case "A":
+		return m.toggleMarkAll()
+	case "esc":
+		return m.clearMarks()
+	case "e":
+		return m.openDetailsEditor()
+	case "T":
+		return m.openTagManager()
+	case "I":
+		return m.openStats()
 	case "H":
 		return m.hideHelpBar()
 	case "h":
@@ -99,7 +160,20 @@ func (m model) handleTableKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, func() tea.Msg { return reindexVideosMsg{} }
 	case "x":
 		return m.selectRandomVideo()
+	case "X":
+		return m.toggleWeightedRandom()
+	case "B":
+		return m.openSessionBuilder()
+	case "S":
+		return m.stopSequence()
+	case "+":
+		return m.toggleQueued()
+	case "P":
+		return m.toggleQueueFocus()
 	default:
+		if m.visual {
+			return m.updateVisualRange(msg)
+		}
 		return m.updateTable(msg)
 	}
 }
@@ -110,7 +184,36 @@ func (m model) openFilters() (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// openSearchBar is the one-line alternative to the guided filter dialog.
+func (m model) openSearchBar(value string) (tea.Model, tea.Cmd) {
+	m = m.openPrompt("Search ("+searchSyntax+")", value, func(m model, value string) (tea.Model, tea.Cmd) {
+		filters, err := parseSearchQuery(value)
+		if err != nil {
+			next, _ := m.openSearchBar(value)
+			updated := next.(model)
+			updated.statusMessage = err.Error()
+			return updated, nil
+		}
+		m.filters = filters
+		m.collection = ""
+		m.syncFilterInputs()
+		m.applyFiltersAndSort()
+		m.statusMessage = fmt.Sprintf("Filters applied (%d videos)", len(m.filtered))
+		return m, nil
+	})
+	m.statusMessage = "Searching"
+	return m, nil
+}
+
 func (m model) playSelection() (tea.Model, tea.Cmd) {
+	return m.launchSelection(true)
+}
+
+func (m model) playSelectionFromStart() (tea.Model, tea.Cmd) {
+	return m.launchSelection(false)
+}
+
+func (m model) launchSelection(resume bool) (tea.Model, tea.Cmd) {
 	if len(m.filtered) == 0 {
 		return m, nil
 	}
@@ -119,8 +222,16 @@ func (m model) playSelection() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	video := m.filtered[idx]
-	m.statusMessage = fmt.Sprintf("Launching VLC: %s", video.Name)
-	return m, playVideoCmd(video.Path, m.activeCrop())
+	opts := m.playOptions()
+	if resume {
+		opts.Start = m.resumePosition(video)
+	}
+	if opts.Start > 0 {
+		m.statusMessage = fmt.Sprintf("Resuming %s at %s via %s", video.Name, formatDuration(opts.Start), m.player.Name())
+	} else {
+		m.statusMessage = fmt.Sprintf("Launching %s: %s", m.player.Name(), video.Name)
+	}
+	return m, playVideoCmd(m.player, video.Path, opts)
 }
 
 func (m model) sortAndReport(field sortField) (tea.Model, tea.Cmd) {
@@ -156,7 +267,7 @@ func (m model) selectRandomVideo() (tea.Model, tea.Cmd) {
 		m.statusMessage = "No videos to select from"
 		return m, nil
 	}
-	idx := rand.Intn(len(m.filtered))
+	idx := randomIndex(m.filtered, m.table.Cursor(), m.weightedRandom)
 	m.table.SetCursor(idx)
 	video := m.filtered[idx]
 	m.statusMessage = fmt.Sprintf("Randomly selected: %s", video.Name)
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"codeberg.org/snonux/yoga/internal/playlist"
+)
+
+const queuePanelWidth = 36
+
+func (m model) toggleQueued() (tea.Model, tea.Cmd) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.filtered) {
+		m.statusMessage = "No selection"
+		return m, nil
+	}
+	v := m.filtered[idx]
+	if pos := m.queueIndex(v.Path); pos >= 0 {
+		m.queue = append(m.queue[:pos:pos], m.queue[pos+1:]...)
+		m.clampQueueCursor()
+		m.statusMessage = fmt.Sprintf("Removed from queue: %s", v.Name)
+	} else {
+		m.queue = append(m.queue, v)
+		m.statusMessage = fmt.Sprintf("Queued %s (%d in queue)", v.Name, len(m.queue))
+	}
+	m.resizeColumns(m.viewportWidth)
+	return m, nil
+}
+
+func (m model) queueIndex(path string) int {
+	for i, v := range m.queue {
+		if v.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+func (m model) toggleQueueFocus() (tea.Model, tea.Cmd) {
+	m.queueFocus = !m.queueFocus
+	if m.queueFocus {
+		m.table.Blur()
+		m.statusMessage = "Queue: ↑/↓ select • K/J move • d remove • enter play • s save • o open • e export • C clear • esc back"
+	} else {
+		m.table.Focus()
+		m.statusMessage = ""
+	}
+	m.resizeColumns(m.viewportWidth)
+	return m, nil
+}
+
+func (m model) handleQueueKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "P":
+		return m.toggleQueueFocus()
+	case "up", "k":
+		if m.queueCursor > 0 {
+			m.queueCursor--
+		}
+	case "down", "j":
+		if m.queueCursor < len(m.queue)-1 {
+			m.queueCursor++
+		}
+	case "K", "shift+up":
+		m.moveQueueItem(-1)
+	case "J", "shift+down":
+		m.moveQueueItem(1)
+	case "d", "delete", "backspace":
+		if len(m.queue) > 0 {
+			m.queue = append(m.queue[:m.queueCursor:m.queueCursor], m.queue[m.queueCursor+1:]...)
+			m.clampQueueCursor()
+		}
+	case "C":
+		m.queue = nil
+		m.queueCursor = 0
+		m.statusMessage = "Queue cleared"
+	case "enter":
+		if len(m.queue) == 0 {
+			m.statusMessage = "Queue is empty"
+			return m, nil
+		}
+		return m.startSequence(m.queue[m.queueCursor:], "queue")
+	case "s":
+		return m.openPrompt("Save queue as playlist", m.queueName, savePlaylistFromPrompt), nil
+	case "e":
+		return m.openPrompt("Export queue as M3U and XSPF", m.queueName, exportPlaylistFromPrompt), nil
+	case "o":
+		return m, listPlaylistsCmd(playlist.Dir(m.root))
+	}
+	m.resizeColumns(m.viewportWidth)
+	return m, nil
+}
+
+func (m *model) moveQueueItem(delta int) {
+	target := m.queueCursor + delta
+	if target < 0 || target >= len(m.queue) {
+		return
+	}
+	m.queue[m.queueCursor], m.queue[target] = m.queue[target], m.queue[m.queueCursor]
+	m.queueCursor = target
+}
+
+func (m *model) clampQueueCursor() {
+	if m.queueCursor >= len(m.queue) {
+		m.queueCursor = len(m.queue) - 1
+	}
+	if m.queueCursor < 0 {
+		m.queueCursor = 0
+	}
+}
+
+func (m model) queueVisible() bool {
+	return m.queueFocus || len(m.queue) > 0
+}
+
+func (m model) queuePaths() []string {
+	paths := make([]string, 0, len(m.queue))
+	for _, v := range m.queue {
+		paths = append(paths, v.Path)
+	}
+	return paths
+}
+
+func (m model) queueEntries() []playlist.Entry {
+	entries := make([]playlist.Entry, 0, len(m.queue))
+	for _, v := range m.queue {
+		entries = append(entries, playlist.Entry{Path: v.Path, Title: v.Name, Duration: v.Duration})
+	}
+	return entries
+}
+
+func savePlaylistFromPrompt(m model, name string) (tea.Model, tea.Cmd) {
+	if err := playlist.ValidateName(name); err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	m.queueName = name
+	m.statusMessage = fmt.Sprintf("Saving playlist %s", name)
+	return m, savePlaylistCmd(playlist.Dir(m.root), playlist.Playlist{Name: name, Paths: m.queuePaths()})
+}
+
+func exportPlaylistFromPrompt(m model, name string) (tea.Model, tea.Cmd) {
+	if err := playlist.ValidateName(name); err != nil {
+		m.statusMessage = err.Error()
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Exporting %s", name)
+	return m, exportPlaylistCmd(m.root, name, m.queueEntries())
+}
+
+func (m model) handlePlaylistSaved(msg playlistSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Playlist save error: %v", msg.err)
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Saved playlist %s (%d videos)", msg.name, len(m.queue))
+	return m, nil
+}
+
+func (m model) handlePlaylistsListed(msg playlistsListedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Playlist error: %v", msg.err)
+		return m, nil
+	}
+	if len(msg.names) == 0 {
+		m.statusMessage = "No saved playlists"
+		return m, nil
+	}
+	return m.openPicker("Open playlist", msg.names, func(m model, name string) (tea.Model, tea.Cmd) {
+		m.statusMessage = fmt.Sprintf("Loading playlist %s", name)
+		return m, loadPlaylistCmd(playlist.Dir(m.root), name)
+	}), nil
+}
+
+func (m model) handlePlaylistLoaded(msg playlistLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Playlist load error: %v", msg.err)
+		return m, nil
+	}
+	byPath := make(map[string]video, len(m.videos))
+	for _, v := range m.videos {
+		byPath[v.Path] = v
+	}
+	queue := make([]video, 0, len(msg.playlist.Paths))
+	missing := 0
+	for _, path := range msg.playlist.Paths {
+		v, ok := byPath[path]
+		if !ok {
+			missing++
+			continue
+		}
+		queue = append(queue, v)
+	}
+	m.queue = queue
+	m.queueCursor = 0
+	m.queueName = msg.playlist.Name
+	m.statusMessage = fmt.Sprintf("Loaded playlist %s (%d videos)", msg.playlist.Name, len(queue))
+	if missing > 0 {
+		m.statusMessage = fmt.Sprintf("%s, %d missing", m.statusMessage, missing)
+	}
+	m.resizeColumns(m.viewportWidth)
+	return m, nil
+}
+
+func (m model) handlePlaylistExported(msg playlistExportedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.statusMessage = fmt.Sprintf("Playlist export error: %v", msg.err)
+		return m, nil
+	}
+	names := make([]string, 0, len(msg.paths))
+	for _, path := range msg.paths {
+		names = append(names, filepath.Base(path))
+	}
+	m.statusMessage = fmt.Sprintf("Exported %s", strings.Join(names, " and "))
+	return m, nil
+}
+
+func (m model) renderQueuePanel(height int) string {
+	inner := queuePanelWidth - queueStyle.GetHorizontalFrameSize()
+	var b strings.Builder
+	title := "Queue"
+	if m.queueName != "" {
+		title = fmt.Sprintf("Queue: %s", m.queueName)
+	}
+	b.WriteString(headerStyle.Render(truncateText(title, inner)))
+	b.WriteString("\n")
+	var total time.Duration
+	for i, v := range m.queue {
+		total += v.Duration
+		line := truncateText(fmt.Sprintf("%d. %s", i+1, v.Name), inner)
+		if m.queueFocus && i == m.queueCursor {
+			line = highlightStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if len(m.queue) == 0 {
+		b.WriteString("(empty, press + on a row)\n")
+	}
+	b.WriteString(statusStyle.Render(fmt.Sprintf("Total %s", formatDuration(total))))
+	style := queueStyle.Width(inner + queueStyle.GetHorizontalPadding())
+	if height > 0 {
+		style = style.Height(height - queueStyle.GetVerticalFrameSize())
+	}
+	if m.queueFocus {
+		style = style.BorderForeground(lipgloss.Color("212"))
+	}
+	return style.Render(b.String())
+}
+
+func truncateText(value string, width int) string {
+	runes := []rune(value)
+	if width <= 0 || len(runes) <= width {
+		return value
+	}
+	if width == 1 {
+		return "…"
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,153 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func queueTestModel(t *testing.T) model {
+	t.Helper()
+	root := t.TempDir()
+	return loadedModel(t, Options{Root: root}, []video{
+		{Name: "a.mp4", Path: filepath.Join(root, "a.mp4"), Duration: time.Minute},
+		{Name: "b.mp4", Path: filepath.Join(root, "b.mp4"), Duration: 2 * time.Minute},
+		{Name: "c.mp4", Path: filepath.Join(root, "c.mp4"), Duration: 3 * time.Minute},
+	})
+}
+
+func TestQueueAddReorderAndPlay(t *testing.T) {
+	m := queueTestModel(t)
+	for _, idx := range []int{0, 2} {
+		m.table.SetCursor(idx)
+		modelAny, _ := m.handleKeyMsg(keyMsg("+"))
+		m = modelAny.(model)
+	}
+	if len(m.queue) != 2 || !m.queueVisible() {
+		t.Fatalf("expected two queued videos, got %d", len(m.queue))
+	}
+	if view := m.View(); !strings.Contains(view, "1. a.mp4") || !strings.Contains(view, "Total 04:00") {
+		t.Fatalf("expected queue panel in view: %s", view)
+	}
+	modelAny, _ := m.handleKeyMsg(keyMsg("P"))
+	m = modelAny.(model)
+	if !m.queueFocus {
+		t.Fatal("expected queue focus")
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("J"))
+	m = modelAny.(model)
+	if m.queue[0].Name != "c.mp4" || m.queueCursor != 1 {
+		t.Fatalf("expected item moved down, got %v", m.queuePaths())
+	}
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if cmd == nil || m.sequencePath != m.queue[1].Path {
+		t.Fatal("expected queue playback from cursor")
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("d"))
+	m = modelAny.(model)
+	if len(m.queue) != 1 || m.queueCursor != 0 {
+		t.Fatalf("expected item removed, got %v", m.queuePaths())
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = modelAny.(model)
+	if m.queueFocus {
+		t.Fatal("expected focus back on table")
+	}
+	m.table.SetCursor(0)
+	modelAny, _ = m.handleKeyMsg(keyMsg("+"))
+	m = modelAny.(model)
+	modelAny, _ = m.handleKeyMsg(keyMsg("+"))
+	m = modelAny.(model)
+	if len(m.queue) != 1 {
+		t.Fatalf("expected second + to unqueue, got %v", m.queuePaths())
+	}
+}
+
+func TestQueueSaveLoadAndExportPlaylist(t *testing.T) {
+	m := queueTestModel(t)
+	m.queue = []video{m.videos[1], m.videos[0]}
+	m.queueFocus = true
+	modelAny, _ := m.handleKeyMsg(keyMsg("s"))
+	m = modelAny.(model)
+	if m.prompt == nil {
+		t.Fatal("expected name prompt")
+	}
+	m = typeText(t, m, "quiet morning")
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if cmd == nil {
+		t.Fatal("expected save command")
+	}
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(model)
+	if !strings.Contains(m.statusMessage, "Saved playlist quiet morning") {
+		t.Fatalf("unexpected status %s", m.statusMessage)
+	}
+
+	m.queue = nil
+	modelAny, cmd = m.handleKeyMsg(keyMsg("o"))
+	m = modelAny.(model)
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(model)
+	if m.picker == nil || len(m.picker.items) != 1 {
+		t.Fatal("expected playlist picker")
+	}
+	modelAny, cmd = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(model)
+	if len(m.queue) != 2 || m.queue[0].Name != "b.mp4" || m.queueName != "quiet morning" {
+		t.Fatalf("unexpected loaded queue %v", m.queuePaths())
+	}
+
+	modelAny, _ = m.handleKeyMsg(keyMsg("e"))
+	m = modelAny.(model)
+	modelAny, cmd = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	modelAny, _ = m.Update(cmd())
+	m = modelAny.(model)
+	if !strings.Contains(m.statusMessage, "Exported quiet morning.m3u and quiet morning.xspf") {
+		t.Fatalf("unexpected status %s", m.statusMessage)
+	}
+	data, err := os.ReadFile(filepath.Join(m.root, "quiet morning.m3u"))
+	if err != nil {
+		t.Fatalf("read m3u: %v", err)
+	}
+	if !strings.Contains(string(data), "#EXTINF:120,b.mp4") {
+		t.Fatalf("unexpected m3u %s", data)
+	}
+}
+
+func TestPromptDoesNotQuitOnQ(t *testing.T) {
+	m := queueTestModel(t)
+	m = m.openPrompt("Name", "", savePlaylistFromPrompt)
+	modelAny, cmd := m.handleKeyMsg(keyMsg("q"))
+	m = modelAny.(model)
+	if cmd != nil && cmd() == tea.Quit() {
+		t.Fatal("expected q to be typed into the prompt")
+	}
+	if m.prompt == nil || m.prompt.input.Value() != "q" {
+		t.Fatal("expected prompt to keep input")
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	if modelAny.(model).prompt != nil {
+		t.Fatal("expected prompt closed")
+	}
+}
+
+func TestPlaylistLoadReportsMissingVideos(t *testing.T) {
+	m := queueTestModel(t)
+	msg := playlistLoadedMsg{}
+	msg.playlist.Name = "old"
+	msg.playlist.Paths = []string{m.videos[0].Path, "/gone.mp4"}
+	modelAny, _ := m.Update(msg)
+	m = modelAny.(model)
+	if len(m.queue) != 1 || !strings.Contains(m.statusMessage, "1 missing") {
+		t.Fatalf("unexpected result %v %s", m.queuePaths(), m.statusMessage)
+	}
+}
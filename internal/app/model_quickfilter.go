@@ -0,0 +1,129 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// quickFilterState backs search-as-you-type. base is m.filtered under the
+// previous filters, taken when the quick filter opened and again whenever
+// the library changes; it is already filtered and sorted, so narrowing it
+// keeps the order without sorting the library again.
+type quickFilterState struct {
+	input      textinput.Model
+	previous   filterState
+	collection string
+	base       []video
+	// query and matches are the last narrowing step. A query that extends
+	// it narrows matches instead of base.
+	query   string
+	matches []video
+}
+
+func (m model) openQuickFilter() (tea.Model, tea.Cmd) {
+	input := textinput.New()
+	input.Prompt = "filter> "
+	input.Placeholder = "type to narrow by name"
+	input.CharLimit = 256
+	input.Focus()
+	m.quick = &quickFilterState{
+		input:      input,
+		previous:   m.filters,
+		collection: m.collection,
+		base:       m.filtered,
+		matches:    m.filtered,
+	}
+	m.statusMessage = "Quick filter: enter keeps it, esc restores the previous filters"
+	return m, textinput.Blink
+}
+
+func (m model) handleQuickFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	quick := *m.quick
+	switch msg.String() {
+	case "esc":
+		m.quick = nil
+		m.filters = quick.previous
+		m.collection = quick.collection
+		// Re-run the filters rather than restoring base, which may be stale
+		// if durations or the library changed meanwhile.
+		m.applyFiltersAndSort()
+		m.statusMessage = "Quick filter cancelled"
+		return m, nil
+	case "enter":
+		m.quick = nil
+		m.filters.name = combineNameQueries(quick.previous.name, quick.input.Value())
+		if m.filters.name != quick.previous.name {
+			m.collection = ""
+		}
+		m.syncFilterInputs()
+		m.statusMessage = fmt.Sprintf("Filters applied (%d videos)", len(m.filtered))
+		return m, nil
+	case "up", "down", "pgup", "pgdown":
+		return m.updateTable(msg)
+	}
+	var cmd tea.Cmd
+	quick.input, cmd = quick.input.Update(msg)
+	m.quick = &quick
+	m.narrowQuickFilter()
+	return m, cmd
+}
+
+// rebaseQuickFilter takes base from the current library after
+// applyFiltersAndSort, so probe results, watcher events and saves that arrive
+// while the quick filter is open survive the next keystroke. m.filtered
+// already holds the matches of the current query.
+func (m *model) rebaseQuickFilter() {
+	quick := *m.quick
+	previous := *m
+	previous.quick = nil
+	previous.filters = quick.previous
+	quick.base = previous.filterAndSort()
+	quick.query = strings.TrimSpace(quick.input.Value())
+	quick.matches = m.filtered
+	m.quick = &quick
+}
+
+// narrowQuickFilter re-filters after a keystroke. Only the name is matched
+// here; the other criteria already hold for every video in base.
+func (m *model) narrowQuickFilter() {
+	quick := m.quick
+	query := strings.TrimSpace(quick.input.Value())
+	if query == quick.query {
+		return
+	}
+	candidates := quick.base
+	if quick.query != "" && strings.HasPrefix(query, quick.query) && !strings.HasSuffix(query, " ") {
+		candidates = quick.matches
+	}
+	name := combineNameQueries(quick.previous.name, query)
+	matches := make([]video, 0, len(candidates))
+	for _, v := range candidates {
+		match, ok := fuzzyMatch(name, v.Name)
+		if !ok {
+			continue
+		}
+		v.match = match
+		matches = append(matches, v)
+	}
+	if m.sortField == sortByScore {
+		// Scores change with the query; only the narrowed list is sorted.
+		sort.SliceStable(matches, func(i, j int) bool { return m.less(matches[i], matches[j]) })
+	}
+	quick.query = query
+	quick.matches = matches
+	m.filters.name = name
+	m.filtered = matches
+	m.updateTableRows()
+}
+
+func combineNameQueries(previous, query string) string {
+	return strings.TrimSpace(previous + " " + strings.TrimSpace(query))
+}
+
+func (m model) renderQuickFilter() string {
+	return fmt.Sprintf("%s  (%d/%d)", m.quick.input.View(), len(m.filtered), len(m.quick.base))
+}
@@ -0,0 +1,109 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+)
+
+func quickFilterModel(t *testing.T) model {
+	t.Helper()
+	m := loadedModel(t, Options{Root: t.TempDir()}, []video{
+		{Name: "Yin Yoga for Hips.mp4", Path: "a", Tags: []string{"yin"}},
+		{Name: "Power Flow.mp4", Path: "b", Tags: []string{"power"}},
+		{Name: "Yin Shoulders.mp4", Path: "c", Tags: []string{"yin"}},
+		{Name: "Quiet Morning.mp4", Path: "d", Tags: []string{"yin"}},
+	})
+	m.filters, _ = parseSearchQuery("tag:yin")
+	m.applyFiltersAndSort()
+	return m
+}
+
+func TestQuickFilterNarrowsAsYouType(t *testing.T) {
+	m := quickFilterModel(t)
+	modelAny, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = modelAny.(model)
+	if m.quick == nil {
+		t.Fatal("expected quick filter")
+	}
+	m = typeText(t, m, "yin")
+	if len(m.filtered) != 2 {
+		t.Fatalf("expected two yin videos, got %d", len(m.filtered))
+	}
+	m = typeText(t, m, " hip")
+	if len(m.filtered) != 1 || m.filtered[0].Path != "a" {
+		t.Fatalf("expected narrowed result, got %+v", m.filtered)
+	}
+	m = typeText(t, m, "q")
+	if m.quick == nil || len(m.filtered) != 0 {
+		t.Fatal("expected q to be typed rather than quit")
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = modelAny.(model)
+	if len(m.filtered) != 1 {
+		t.Fatalf("expected backspace to widen again, got %d", len(m.filtered))
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.quick != nil || m.filters.name != "yin hip" || m.filters.tags != "yin" {
+		t.Fatalf("expected quick filter folded into filters, got %+v", m.filters)
+	}
+	if m.inputs.fields[0].Value() != "yin hip" {
+		t.Fatal("expected guided dialog to show the name")
+	}
+}
+
+func TestQuickFilterEscRestoresPreviousFilters(t *testing.T) {
+	m := quickFilterModel(t)
+	m.collection = "yin"
+	modelAny, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = modelAny.(model)
+	m = typeText(t, m, "shoulder")
+	if len(m.filtered) != 1 {
+		t.Fatalf("expected one match, got %d", len(m.filtered))
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = modelAny.(model)
+	if m.quick != nil || m.filters.name != "" || m.collection != "yin" || len(m.filtered) != 3 {
+		t.Fatalf("expected previous state restored, got %+v (%d videos)", m.filters, len(m.filtered))
+	}
+}
+
+func TestQuickFilterFollowsLibraryUpdates(t *testing.T) {
+	m := quickFilterModel(t)
+	modelAny, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyCtrlF})
+	m = modelAny.(model)
+	m = typeText(t, m, "yin")
+	// A probe result arriving while the quick filter is open.
+	modelAny, _ = m.Update(durationUpdateMsg{path: "a", media: probe.Info{Duration: 20 * time.Minute}})
+	m = modelAny.(model)
+	if len(m.filtered) != 2 {
+		t.Fatalf("expected two yin videos, got %d", len(m.filtered))
+	}
+	// Typing on narrows the updated library, not the one from ctrl+f.
+	m = typeText(t, m, " hip")
+	if len(m.filtered) != 1 || m.filtered[0].Duration != 20*time.Minute {
+		t.Fatalf("expected the probed duration in the results, got %+v", m.filtered)
+	}
+	for range len(" hip") {
+		modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyBackspace})
+		m = modelAny.(model)
+	}
+	// A video removed meanwhile must not come back when typing on.
+	m.videos = m.videos[1:]
+	m.applyFiltersAndSort()
+	m = typeText(t, m, " s")
+	if len(m.filtered) != 1 || m.filtered[0].Path != "c" {
+		t.Fatalf("expected only the remaining yin video, got %+v", m.filtered)
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = modelAny.(model)
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = modelAny.(model)
+	if len(m.filtered) != 1 || len(m.quick.base) != 2 {
+		t.Fatalf("expected the removed video to stay gone, got %+v", m.filtered)
+	}
+}
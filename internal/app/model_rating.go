@@ -0,0 +1,103 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// unratedWeight is the random pick weight of videos without a rating, the
+// middle of the scale.
+const unratedWeight = 3
+
+// favoriteBonus is added to the random pick weight of favourites.
+const favoriteBonus = 2
+
+// rateSelection sets the rating of the selected video. 0, or the current
+// rating again, clears it.
+func (m model) rateSelection(rating int) (tea.Model, tea.Cmd) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.filtered) {
+		m.statusMessage = "No selection"
+		return m, nil
+	}
+	v := m.filtered[idx]
+	if rating == v.Details.Rating {
+		rating = 0
+	}
+	status := fmt.Sprintf("Rated %s %s", v.Name, ratingCell(video{Details: tags.Details{Rating: rating}}))
+	if rating == 0 {
+		status = fmt.Sprintf("Rating cleared for %s", v.Name)
+	}
+	m.statusMessage = fmt.Sprintf("Saving rating for %s", v.Name)
+	return m, updateMetadataCmd(v.Path, status, func(md *tags.Metadata) { md.Rating = rating })
+}
+
+func (m model) toggleFavorite() (tea.Model, tea.Cmd) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.filtered) {
+		m.statusMessage = "No selection"
+		return m, nil
+	}
+	v := m.filtered[idx]
+	favorite := !v.Details.Favorite
+	status := fmt.Sprintf("Added %s to favourites", v.Name)
+	if !favorite {
+		status = fmt.Sprintf("Removed %s from favourites", v.Name)
+	}
+	return m, updateMetadataCmd(v.Path, status, func(md *tags.Metadata) { md.Favorite = favorite })
+}
+
+func (m model) toggleWeightedRandom() (tea.Model, tea.Cmd) {
+	m.weightedRandom = !m.weightedRandom
+	if m.weightedRandom {
+		m.statusMessage = "Random pick weighted by rating"
+	} else {
+		m.statusMessage = "Random pick uniform"
+	}
+	return m, nil
+}
+
+// randomIndex picks a video other than the one at current, so repeated
+// picks always move. With weighted set the chance is proportional to
+// randomWeight.
+func randomIndex(videos []video, current int, weighted bool) int {
+	if len(videos) == 1 {
+		return 0
+	}
+	weight := func(i int) int {
+		switch {
+		case i == current:
+			return 0
+		case weighted:
+			return randomWeight(videos[i])
+		}
+		return 1
+	}
+	total := 0
+	for i := range videos {
+		total += weight(i)
+	}
+	n := rand.Intn(total)
+	for i := range videos {
+		n -= weight(i)
+		if n < 0 {
+			return i
+		}
+	}
+	return len(videos) - 1
+}
+
+func randomWeight(v video) int {
+	weight := v.Details.Rating
+	if weight == 0 {
+		weight = unratedWeight
+	}
+	if v.Details.Favorite {
+		weight += favoriteBonus
+	}
+	return weight
+}
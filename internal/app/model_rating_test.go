@@ -0,0 +1,96 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func newRatingModel(t *testing.T) (model, string) {
+	t.Helper()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "flow.mp4")
+	if err := os.WriteFile(path, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write video: %v", err)
+	}
+	if err := tags.Save(path, []string{"hips"}); err != nil {
+		t.Fatalf("save tags: %v", err)
+	}
+	m := loadedModel(t, Options{Root: dir}, []video{{Name: "flow.mp4", Path: path, Tags: []string{"hips"}}})
+	return m, path
+}
+
+func pressAndSave(t *testing.T, m model, key string) model {
+	t.Helper()
+	modelAny, cmd := m.handleKeyMsg(keyMsg(key))
+	if cmd == nil {
+		t.Fatalf("expected save command for %q", key)
+	}
+	modelAny, _ = modelAny.(model).Update(cmd())
+	return modelAny.(model)
+}
+
+func TestRatingKeysUpdateSidecar(t *testing.T) {
+	m, path := newRatingModel(t)
+	m = pressAndSave(t, m, "4")
+	if m.videos[0].Details.Rating != 4 || !strings.Contains(m.statusMessage, "★★★★☆") {
+		t.Fatalf("expected rating 4, got %+v (%s)", m.videos[0].Details, m.statusMessage)
+	}
+	m = pressAndSave(t, m, "*")
+	md, err := tags.LoadMetadata(path)
+	if err != nil {
+		t.Fatalf("LoadMetadata: %v", err)
+	}
+	if md.Rating != 4 || !md.Favorite || len(md.Tags) != 1 || md.Tags[0] != "hips" {
+		t.Fatalf("expected rating and favourite stored with tags, got %+v", md)
+	}
+	if cell := ratingCell(m.videos[0]); cell != "★★★★☆ ♥" {
+		t.Fatalf("unexpected rating cell %q", cell)
+	}
+	m = pressAndSave(t, m, "4")
+	if m.videos[0].Details.Rating != 0 || !m.videos[0].Details.Favorite {
+		t.Fatalf("expected repeated rating to clear it, got %+v", m.videos[0].Details)
+	}
+}
+
+func TestSortByRating(t *testing.T) {
+	m := model{sortField: sortByRating, sortAscending: true}
+	m.videos = []video{
+		{Name: "b", Details: tags.Details{Rating: 3}},
+		{Name: "c"},
+		{Name: "a", Details: tags.Details{Rating: 3, Favorite: true}},
+		{Name: "d", Details: tags.Details{Rating: 5}},
+	}
+	m.applyFiltersAndSort()
+	var names []string
+	for _, v := range m.filtered {
+		names = append(names, v.Name)
+	}
+	if got := strings.Join(names, ","); got != "d,a,b,c" {
+		t.Fatalf("unexpected order %s", got)
+	}
+}
+
+func TestRandomIndexSkipsCurrentAndWeightsByRating(t *testing.T) {
+	videos := []video{
+		{Name: "loved", Details: tags.Details{Rating: 5, Favorite: true}},
+		{Name: "meh", Details: tags.Details{Rating: 1}},
+		{Name: "current"},
+	}
+	counts := make([]int, len(videos))
+	for range 2000 {
+		counts[randomIndex(videos, 2, true)]++
+	}
+	if counts[2] != 0 {
+		t.Fatal("expected the current video never to be picked")
+	}
+	if counts[0] < 3*counts[1] {
+		t.Fatalf("expected weighting by rating, got %v", counts)
+	}
+	if got := randomIndex(videos[:1], 0, true); got != 0 {
+		t.Fatalf("expected the only video, got %d", got)
+	}
+}
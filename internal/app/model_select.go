@@ -0,0 +1,195 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/table"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// markPrefix is put in front of the first cell of marked rows.
+const markPrefix = "✓ "
+
+// isMarked reports whether the video at row idx of m.filtered is marked,
+// either explicitly or by the pending visual range.
+func (m model) isMarked(idx int, path string) bool {
+	if m.marked[path] {
+		return true
+	}
+	if !m.visual {
+		return false
+	}
+	cursor := m.table.Cursor()
+	return idx >= min(m.visualAnchor, cursor) && idx <= max(m.visualAnchor, cursor)
+}
+
+func (m model) tableRows() []table.Row {
+	rows := make([]table.Row, 0, len(m.filtered))
+	ids := m.visibleColumns()
+	for i, v := range m.filtered {
+		row := videoRowFor(v, ids)
+		if len(row) > 0 && m.isMarked(i, v.Path) {
+			row[0] = markPrefix + row[0]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// refreshMarks redraws the rows after the marks changed, keeping the cursor.
+func (m *model) refreshMarks() {
+	cursor := m.table.Cursor()
+	m.table.SetRows(m.tableRows())
+	m.table.SetCursor(cursor)
+}
+
+// toggleMark marks or unmarks the selected row and moves to the next one.
+func (m model) toggleMark() (tea.Model, tea.Cmd) {
+	idx := m.table.Cursor()
+	if idx < 0 || idx >= len(m.filtered) {
+		return m, nil
+	}
+	m.setMarked(m.filtered[idx].Path, !m.marked[m.filtered[idx].Path])
+	m.table.MoveDown(1)
+	m.refreshMarks()
+	m.statusMessage = m.markStatus()
+	return m, nil
+}
+
+func (m *model) setMarked(path string, marked bool) {
+	if m.marked == nil {
+		m.marked = make(map[string]bool)
+	}
+	if marked {
+		m.marked[path] = true
+	} else {
+		delete(m.marked, path)
+	}
+}
+
+// toggleVisual starts a range at the cursor; the second press marks every
+// row between the start and the cursor.
+func (m model) toggleVisual() (tea.Model, tea.Cmd) {
+	if len(m.filtered) == 0 {
+		return m, nil
+	}
+	if !m.visual {
+		m.visual = true
+		m.visualAnchor = m.table.Cursor()
+		m.refreshMarks()
+		m.statusMessage = "Range select: move to extend, v to mark, esc to cancel"
+		return m, nil
+	}
+	for i, v := range m.filtered {
+		if m.isMarked(i, v.Path) {
+			m.setMarked(v.Path, true)
+		}
+	}
+	m.visual = false
+	m.refreshMarks()
+	m.statusMessage = m.markStatus()
+	return m, nil
+}
+
+// toggleMarkAll marks every filtered video, or unmarks them when all of
+// them are marked already.
+func (m model) toggleMarkAll() (tea.Model, tea.Cmd) {
+	all := true
+	for _, v := range m.filtered {
+		if !m.marked[v.Path] {
+			all = false
+			break
+		}
+	}
+	for _, v := range m.filtered {
+		m.setMarked(v.Path, !all)
+	}
+	m.visual = false
+	m.refreshMarks()
+	m.statusMessage = m.markStatus()
+	return m, nil
+}
+
+// updateVisualRange moves the cursor and redraws the pending range.
+func (m model) updateVisualRange(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.table, cmd = m.table.Update(msg)
+	m.refreshMarks()
+	return m, cmd
+}
+
+// clearMarks cancels a pending range, or else unmarks everything.
+func (m model) clearMarks() (tea.Model, tea.Cmd) {
+	if m.visual {
+		m.visual = false
+		m.refreshMarks()
+		m.statusMessage = "Range select cancelled"
+		return m, nil
+	}
+	if len(m.marked) == 0 {
+		return m, nil
+	}
+	m.marked = nil
+	m.refreshMarks()
+	m.statusMessage = "Selection cleared"
+	return m, nil
+}
+
+func (m model) markStatus() string {
+	count := len(m.markedVideos())
+	if count == 0 {
+		return "Nothing marked"
+	}
+	return fmt.Sprintf("%d videos marked (t to add or remove tags, esc to clear)", count)
+}
+
+// markedVideos returns the marked videos in library order, including ones
+// the current filters hide.
+func (m model) markedVideos() []video {
+	var out []video
+	for _, v := range m.videos {
+		if m.marked[v.Path] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (m model) openBulkTagPrompt() (tea.Model, tea.Cmd) {
+	videos := m.markedVideos()
+	title := fmt.Sprintf("Tags for %d marked videos (hips, -advanced: add hips, remove advanced)", len(videos))
+	m = m.openPrompt(title, "", func(m model, value string) (tea.Model, tea.Cmd) {
+		edit, err := parseBulkTagInput(value, m.tagPolicy)
+		if err != nil {
+			m.statusMessage = err.Error()
+			return m, nil
+		}
+		return m.startTagJob(videos, edit)
+	})
+	return m, nil
+}
+
+// parseBulkTagInput reads comma separated tags; a leading - removes the tag
+// and an optional + adds it. Added tags follow the tag policy.
+func parseBulkTagInput(value string, policy tags.Policy) (tagBulkEdit, error) {
+	var edit tagBulkEdit
+	for _, entry := range parseTagInput(value) {
+		switch {
+		case strings.HasPrefix(entry, "-"):
+			if tag := strings.TrimSpace(entry[1:]); tag != "" {
+				edit.remove = append(edit.remove, tag)
+			}
+		default:
+			if tag := policy.Canonical(strings.TrimPrefix(entry, "+")); tag != "" {
+				edit.add = append(edit.add, tag)
+			}
+		}
+	}
+	if len(edit.add) == 0 && len(edit.remove) == 0 {
+		return tagBulkEdit{}, fmt.Errorf("no tags given")
+	}
+	return edit, nil
+}
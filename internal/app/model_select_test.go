@@ -0,0 +1,114 @@
+package app
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func newSelectModel(t *testing.T, library [][]string) model {
+	t.Helper()
+	root := t.TempDir()
+	var videos []video
+	for i, values := range library {
+		path := filepath.Join(root, string(rune('a'+i))+".mp4")
+		if err := tags.Save(path, values); err != nil {
+			t.Fatalf("save tags: %v", err)
+		}
+		videos = append(videos, video{Name: filepath.Base(path), Path: path, Tags: values})
+	}
+	return loadedModel(t, Options{Root: root}, videos)
+}
+
+func press(t *testing.T, m model, keys ...tea.KeyMsg) model {
+	t.Helper()
+	for _, key := range keys {
+		modelAny, _ := m.handleKeyMsg(key)
+		m = modelAny.(model)
+	}
+	return m
+}
+
+func markedNames(m model) []string {
+	var names []string
+	for _, v := range m.markedVideos() {
+		names = append(names, v.Name)
+	}
+	return names
+}
+
+func TestMarkingRows(t *testing.T) {
+	m := newSelectModel(t, [][]string{nil, nil, nil, nil})
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	m = press(t, m, space)
+	if !reflect.DeepEqual(markedNames(m), []string{"a.mp4"}) || m.table.Cursor() != 1 {
+		t.Fatalf("expected first row marked and cursor moved, got %v at %d", markedNames(m), m.table.Cursor())
+	}
+	if row := m.table.Rows()[0]; !strings.HasPrefix(row[0], markPrefix) {
+		t.Fatalf("expected mark in row, got %q", row[0])
+	}
+	m = press(t, m, keyMsg("v"), tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeyDown})
+	if !strings.HasPrefix(m.table.Rows()[3][0], markPrefix) {
+		t.Fatal("expected pending range to be shown")
+	}
+	m = press(t, m, keyMsg("v"))
+	if len(markedNames(m)) != 4 || !strings.Contains(m.statusText(), "4 videos marked") {
+		t.Fatalf("expected range marked, got %v", markedNames(m))
+	}
+	m = press(t, m, tea.KeyMsg{Type: tea.KeyEsc})
+	if len(markedNames(m)) != 0 {
+		t.Fatal("expected esc to clear marks")
+	}
+	m = press(t, m, keyMsg("A"))
+	if len(markedNames(m)) != 4 {
+		t.Fatal("expected all filtered videos marked")
+	}
+	m = press(t, m, keyMsg("A"))
+	if len(markedNames(m)) != 0 {
+		t.Fatal("expected second A to unmark")
+	}
+}
+
+func TestBulkTagEditKeepsOtherTags(t *testing.T) {
+	m := newSelectModel(t, [][]string{{"hips", "advanced"}, {"morning"}, {"untouched"}})
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	m = press(t, m, space, space, keyMsg("t"))
+	if m.prompt == nil || m.editingTags {
+		t.Fatal("expected bulk tag prompt")
+	}
+	m.prompt.input.SetValue("Flow, -ADVANCED")
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runTagJob(t, modelAny.(model), cmd)
+	want := map[string][]string{
+		"a.mp4": {"Flow", "hips"},
+		"b.mp4": {"Flow", "morning"},
+		"c.mp4": {"untouched"},
+	}
+	for _, v := range m.videos {
+		loaded, err := tags.Load(v.Path)
+		if err != nil || !reflect.DeepEqual(loaded, want[v.Name]) || !reflect.DeepEqual(v.Tags, want[v.Name]) {
+			t.Fatalf("%s: expected %v, got %v on disk and %v in memory (%v)", v.Name, want[v.Name], loaded, v.Tags, err)
+		}
+	}
+	if !strings.Contains(m.statusMessage, "Updated tags (+Flow -ADVANCED) in 2 videos") {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+}
+
+func TestParseBulkTagInput(t *testing.T) {
+	edit, err := parseBulkTagInput("+Hip, -old, new", tags.Policy{FoldCase: true, Aliases: map[string]string{"hip": "hips"}})
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if !reflect.DeepEqual(edit.add, []string{"hips", "new"}) || !reflect.DeepEqual(edit.remove, []string{"old"}) {
+		t.Fatalf("unexpected edit %+v", edit)
+	}
+	if _, err := parseBulkTagInput(" , -", tags.Policy{}); err == nil {
+		t.Fatal("expected error without tags")
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// startSequence plays videos one after another: the next launch happens once
+// the player for the previous video exits.
+func (m model) startSequence(videos []video, label string) (tea.Model, tea.Cmd) {
+	if len(videos) == 0 {
+		m.statusMessage = "Nothing to play"
+		return m, nil
+	}
+	first := videos[0]
+	m.upNext = append([]video{}, videos[1:]...)
+	m.sequencePath = first.Path
+	m.sequenceLabel = label
+	m.statusMessage = fmt.Sprintf("Playing %s (%d videos): %s", label, len(videos), first.Name)
+	return m, playVideoCmd(m.player, first.Path, m.playOptions())
+}
+
+// advanceSequence launches the next queued video when the player for the
+// current sequence entry exits.
+func (m *model) advanceSequence(msg playbackEndedMsg) tea.Cmd {
+	if m.sequencePath == "" || msg.path != m.sequencePath {
+		return nil
+	}
+	if len(m.upNext) == 0 {
+		m.statusMessage = fmt.Sprintf("Finished %s", m.sequenceLabel)
+		m.clearSequence()
+		return nil
+	}
+	next := m.upNext[0]
+	m.upNext = m.upNext[1:]
+	m.sequencePath = next.Path
+	m.statusMessage = fmt.Sprintf("Up next in %s: %s (%d more)", m.sequenceLabel, next.Name, len(m.upNext))
+	return playVideoCmd(m.player, next.Path, m.playOptions())
+}
+
+func (m model) stopSequence() (tea.Model, tea.Cmd) {
+	if m.sequencePath == "" {
+		m.statusMessage = "No sequence playing"
+		return m, nil
+	}
+	m.statusMessage = fmt.Sprintf("Stopped %s after the current video", m.sequenceLabel)
+	m.clearSequence()
+	return m, nil
+}
+
+func (m *model) clearSequence() {
+	m.upNext = nil
+	m.sequencePath = ""
+	m.sequenceLabel = ""
+}
@@ -0,0 +1,170 @@
+package app
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const defaultSessionTolerance = "5"
+
+func buildSessionInputs() filterInputs {
+	targetInput := textinput.New()
+	targetInput.Placeholder = "60"
+	targetInput.Prompt = "Target minutes: "
+	targetInput.CharLimit = 4
+
+	toleranceInput := textinput.New()
+	toleranceInput.Placeholder = defaultSessionTolerance
+	toleranceInput.Prompt = "Tolerance minutes: "
+	toleranceInput.CharLimit = 3
+
+	slotsInput := textinput.New()
+	slotsInput.Placeholder = "warm-up; flow; savasana"
+	slotsInput.Prompt = "Slots: "
+	slotsInput.CharLimit = 512
+
+	return filterInputs{
+		fields: []textinput.Model{targetInput, toleranceInput, slotsInput},
+		focus:  0,
+	}
+}
+
+func (m model) openSessionBuilder() (tea.Model, tea.Cmd) {
+	m.showSession = true
+	m.sessionPlan = nil
+	m.sessionErr = ""
+	m.syncSessionFocus()
+	m.statusMessage = "Building session"
+	return m, nil
+}
+
+func (m model) handleSessionKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.showSession = false
+		m.sessionPlan = nil
+		m.statusMessage = "Session builder closed"
+		return m, nil
+	case "enter":
+		if m.sessionPlan != nil && m.sessionKey == m.sessionInputKey() {
+			plan := *m.sessionPlan
+			m.showSession = false
+			m.sessionPlan = nil
+			return m.startSequence(plan.videos, "session")
+		}
+		m.planSession()
+		return m, nil
+	case "ctrl+r":
+		m.planSession()
+		return m, nil
+	case "tab":
+		m.sessionInputs.focus = (m.sessionInputs.focus + 1) % len(m.sessionInputs.fields)
+	case "shift+tab":
+		m.sessionInputs.focus = (m.sessionInputs.focus - 1 + len(m.sessionInputs.fields)) % len(m.sessionInputs.fields)
+	}
+	m.syncSessionFocus()
+	var cmds []tea.Cmd
+	for i := range m.sessionInputs.fields {
+		var cmd tea.Cmd
+		m.sessionInputs.fields[i], cmd = m.sessionInputs.fields[i].Update(msg)
+		cmds = append(cmds, cmd)
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m *model) syncSessionFocus() {
+	for i := range m.sessionInputs.fields {
+		if i == m.sessionInputs.focus {
+			m.sessionInputs.fields[i].Focus()
+			continue
+		}
+		m.sessionInputs.fields[i].Blur()
+	}
+}
+
+// planSession builds a new random session from the filtered videos.
+func (m *model) planSession() {
+	m.sessionPlan = nil
+	req, err := m.sessionRequestFromInputs()
+	if err != nil {
+		m.sessionErr = err.Error()
+		m.statusMessage = m.sessionErr
+		return
+	}
+	rng := rand.New(rand.NewSource(time.Now().UnixNano()))
+	plan, err := buildSession(m.filtered, req, rng)
+	if err != nil {
+		m.sessionErr = err.Error()
+		m.statusMessage = fmt.Sprintf("Session not possible: %v", err)
+		return
+	}
+	m.sessionErr = ""
+	m.sessionPlan = &plan
+	m.sessionKey = m.sessionInputKey()
+	m.statusMessage = fmt.Sprintf("Session ready (%s, %d videos)", formatDuration(plan.total), len(plan.videos))
+}
+
+func (m model) sessionRequestFromInputs() (sessionRequest, error) {
+	targetText := strings.TrimSpace(m.sessionInputs.fields[0].Value())
+	toleranceText := strings.TrimSpace(m.sessionInputs.fields[1].Value())
+	if toleranceText == "" {
+		toleranceText = defaultSessionTolerance
+	}
+	target, err := strconv.Atoi(targetText)
+	if err != nil || target <= 0 {
+		return sessionRequest{}, fmt.Errorf("invalid target minutes: %q", targetText)
+	}
+	tolerance, err := strconv.Atoi(toleranceText)
+	if err != nil || tolerance < 0 {
+		return sessionRequest{}, fmt.Errorf("invalid tolerance minutes: %q", toleranceText)
+	}
+	return sessionRequest{
+		target:    time.Duration(target) * time.Minute,
+		tolerance: time.Duration(tolerance) * time.Minute,
+		slots:     parseSessionSlots(m.sessionInputs.fields[2].Value()),
+	}, nil
+}
+
+func (m model) sessionInputKey() string {
+	values := make([]string, 0, len(m.sessionInputs.fields))
+	for _, field := range m.sessionInputs.fields {
+		values = append(values, strings.TrimSpace(field.Value()))
+	}
+	return strings.Join(values, "\x00")
+}
+
+func (m model) renderSessionModal() string {
+	var b strings.Builder
+	b.WriteString("Build session\n")
+	b.WriteString("(slots separated by ';', tags within a slot by ',')\n\n")
+	for i, field := range m.sessionInputs.fields {
+		line := field.View()
+		if i == m.sessionInputs.focus {
+			line = highlightStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if m.sessionErr != "" {
+		b.WriteString("\n")
+		b.WriteString(m.sessionErr)
+		b.WriteString("\n")
+	}
+	if m.sessionPlan != nil {
+		b.WriteString("\n")
+		for i, v := range m.sessionPlan.videos {
+			fmt.Fprintf(&b, "%d. %s (%s)\n", i+1, v.Name, formatDuration(v.Duration))
+		}
+		fmt.Fprintf(&b, "Total %s\n", formatDuration(m.sessionPlan.total))
+		b.WriteString("\nEnter to play, Ctrl+R to reshuffle, Esc to cancel")
+	} else {
+		b.WriteString("\nEnter to build, Esc to cancel")
+	}
+	return filterStyle.Render(b.String())
+}
@@ -3,8 +3,6 @@ package app
 import (
 	"sort"
 	"strings"
-
-	"github.com/charmbracelet/bubbles/table"
 )
 
 func (m *model) toggleSort(target sortField) {
@@ -17,17 +15,27 @@ func (m *model) toggleSort(target sortField) {
 }
 
 func (m *model) applyFiltersAndSort() {
+	m.filtered = m.filterAndSort()
+	if m.quick != nil {
+		m.rebaseQuickFilter()
+	}
+	m.updateTableRows()
+}
+
+// filterAndSort returns copies of the videos passing the filters in sort
+// order. The subcommands use it without a table.
+func (m model) filterAndSort() []video {
 	filtered := make([]video, 0, len(m.videos))
 	for _, v := range m.videos {
-		if m.passesFilters(v) {
+		if match, ok := m.matchFilters(v); ok {
+			v.match = match
 			filtered = append(filtered, v)
 		}
 	}
 	sort.Slice(filtered, func(i, j int) bool {
 		return m.less(filtered[i], filtered[j])
 	})
-	m.filtered = filtered
-	m.updateTableRows()
+	return filtered
 }
 
 func (m *model) less(a, b video) bool {
@@ -39,6 +47,25 @@ func (m *model) less(a, b video) bool {
 		less = a.Duration < b.Duration
 	case sortByAge:
 		less = a.ModTime.Before(b.ModTime)
+	case sortByLastPlayed:
+		less = a.LastPlayed.Before(b.LastPlayed)
+	case sortByScore:
+		// Best matches first; ties keep name order.
+		if a.match.score != b.match.score {
+			less = a.match.score > b.match.score
+		} else {
+			less = strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
+	case sortByRating:
+		// Best rated first, favourites ahead within a rating.
+		switch {
+		case a.Details.Rating != b.Details.Rating:
+			less = a.Details.Rating > b.Details.Rating
+		case a.Details.Favorite != b.Details.Favorite:
+			less = a.Details.Favorite
+		default:
+			less = strings.ToLower(a.Name) < strings.ToLower(b.Name)
+		}
 	}
 	if m.sortAscending {
 		return less
@@ -47,10 +74,7 @@ func (m *model) less(a, b video) bool {
 }
 
 func (m *model) updateTableRows() {
-	rows := make([]table.Row, 0, len(m.filtered))
-	for _, v := range m.filtered {
-		rows = append(rows, videoRow(v))
-	}
+	rows := m.tableRows()
 	m.table.SetRows(rows)
 	if len(rows) > 0 {
 		m.table.SetCursor(0)
@@ -0,0 +1,64 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// The stats screen trims the report to fit next to the table's height.
+const (
+	statsScreenTags   = 8
+	statsScreenWeeks  = 8
+	statsScreenMonths = 6
+)
+
+// openStats shows the report for the filtered videos behind the `I` key.
+func (m model) openStats() (tea.Model, tea.Cmd) {
+	stats := computeStats(m.filtered, m.history, time.Now())
+	m.stats = &stats
+	m.statusMessage = fmt.Sprintf("Stats for %d videos", len(m.filtered))
+	return m, nil
+}
+
+func (m model) handleStatsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "I":
+		m.stats = nil
+		m.statusMessage = "Stats closed"
+	}
+	return m, nil
+}
+
+// renderStats lays the report out in two columns: the library on the left,
+// plays and practice time on the right.
+func (m model) renderStats() string {
+	stats := *m.stats
+	left := renderStatsColumn(func(w io.Writer) {
+		writeStatsLibrary(w, stats, statsScreenTags)
+	})
+	right := renderStatsColumn(func(w io.Writer) {
+		writeStatsPlayed(w, "Most played", stats.MostPlayed)
+		fmt.Fprintln(w)
+		writeStatsPlayed(w, "Least played", stats.LeastPlayed)
+		fmt.Fprintln(w)
+		writeStatsPractice(w, "Practice per week", stats.Weeks[max(len(stats.Weeks)-statsScreenWeeks, 0):])
+		fmt.Fprintln(w)
+		writeStatsPractice(w, "Practice per month", stats.Months[max(len(stats.Months)-statsScreenMonths, 0):])
+	})
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left, "      ", right)
+	return filterStyle.Render(body+"\n\nEsc to close") + "\n" + statusStyle.Render(m.statusText())
+}
+
+func renderStatsColumn(write func(io.Writer)) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	write(tw)
+	tw.Flush()
+	return strings.TrimRight(b.String(), "\n")
+}
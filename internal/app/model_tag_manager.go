@@ -0,0 +1,253 @@
+package app
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// tagManagerRows is how many tags the manager shows at once.
+const tagManagerRows = 15
+
+type tagCount struct {
+	tag   string
+	count int
+}
+
+// tagManagerState is the tag browser behind the `T` key.
+type tagManagerState struct {
+	entries []tagCount
+	cursor  int
+	// failures of the last change, shown until the next one starts.
+	failures []tagFailure
+}
+
+// tagCounts lists every tag in videos with the number of videos using it,
+// sorted by name.
+func tagCounts(videos []video) []tagCount {
+	counts := make(map[string]int)
+	for _, v := range videos {
+		for _, tag := range v.Tags {
+			counts[tag]++
+		}
+	}
+	entries := make([]tagCount, 0, len(counts))
+	for tag, count := range counts {
+		entries = append(entries, tagCount{tag: tag, count: count})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		a, b := strings.ToLower(entries[i].tag), strings.ToLower(entries[j].tag)
+		if a != b {
+			return a < b
+		}
+		return entries[i].tag < entries[j].tag
+	})
+	return entries
+}
+
+func (m model) openTagManager() (tea.Model, tea.Cmd) {
+	m.tagManager = &tagManagerState{entries: tagCounts(m.videos)}
+	m.statusMessage = fmt.Sprintf("%d tags", len(m.tagManager.entries))
+	return m, nil
+}
+
+// refreshTagManager recounts after the library changed and moves the cursor
+// to selected; when that tag is gone the cursor keeps its position.
+func (m *model) refreshTagManager(selected string) {
+	if m.tagManager == nil {
+		return
+	}
+	manager := *m.tagManager
+	manager.entries = tagCounts(m.videos)
+	for i, entry := range manager.entries {
+		if entry.tag == selected {
+			manager.cursor = i
+			break
+		}
+	}
+	if manager.cursor >= len(manager.entries) {
+		manager.cursor = max(len(manager.entries)-1, 0)
+	}
+	m.tagManager = &manager
+}
+
+func (s tagManagerState) selected() string {
+	if s.cursor < 0 || s.cursor >= len(s.entries) {
+		return ""
+	}
+	return s.entries[s.cursor].tag
+}
+
+func (m model) handleTagManagerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	manager := *m.tagManager
+	switch msg.String() {
+	case "esc", "T":
+		m.tagManager = nil
+		m.statusMessage = "Tag manager closed"
+		return m, nil
+	case "up", "k":
+		if manager.cursor > 0 {
+			manager.cursor--
+		}
+	case "down", "j":
+		if manager.cursor < len(manager.entries)-1 {
+			manager.cursor++
+		}
+	case "r":
+		return m.promptTagRename(manager.selected())
+	case "m":
+		return m.pickTagMergeTarget(manager.selected())
+	case "d":
+		return m.confirmTagDelete(manager.selected())
+	}
+	m.tagManager = &manager
+	return m, nil
+}
+
+func (m model) promptTagRename(tag string) (tea.Model, tea.Cmd) {
+	if tag == "" {
+		return m, nil
+	}
+	m = m.openPrompt(fmt.Sprintf("Rename %q to", tag), tag, func(m model, value string) (tea.Model, tea.Cmd) {
+		value = m.tagPolicy.Canonical(value)
+		if value == "" || value == tag {
+			m.statusMessage = "Tag unchanged"
+			return m, nil
+		}
+		return m.startTagChange(tagChange{from: tag, to: value})
+	})
+	return m, nil
+}
+
+func (m model) pickTagMergeTarget(tag string) (tea.Model, tea.Cmd) {
+	if tag == "" {
+		return m, nil
+	}
+	var targets []string
+	for _, entry := range m.tagManager.entries {
+		if entry.tag != tag {
+			targets = append(targets, entry.tag)
+		}
+	}
+	m = m.openPicker(fmt.Sprintf("Merge %q into", tag), targets, func(m model, target string) (tea.Model, tea.Cmd) {
+		return m.startTagChange(tagChange{from: tag, to: target})
+	})
+	return m, nil
+}
+
+func (m model) confirmTagDelete(tag string) (tea.Model, tea.Cmd) {
+	if tag == "" {
+		return m, nil
+	}
+	count := m.tagManager.entries[m.tagManager.cursor].count
+	title := fmt.Sprintf("Delete %q from %d videos?", tag, count)
+	m = m.openPicker(title, []string{"Cancel", "Delete"}, func(m model, choice string) (tea.Model, tea.Cmd) {
+		if choice != "Delete" {
+			m.statusMessage = "Cancelled"
+			return m, nil
+		}
+		return m.startTagChange(tagChange{from: tag})
+	})
+	return m, nil
+}
+
+// startTagChange rewrites every video carrying change.from.
+func (m model) startTagChange(change tagChange) (tea.Model, tea.Cmd) {
+	return m.startTagJob(m.videos, change)
+}
+
+// startTagJob applies edit to the videos it affects in the background. Only
+// one job runs at a time.
+func (m model) startTagJob(videos []video, edit tagEdit) (tea.Model, tea.Cmd) {
+	if m.tagJob != nil {
+		m.statusMessage = "A tag change is still running"
+		return m, nil
+	}
+	var paths []string
+	for _, v := range videos {
+		if _, ok := edit.apply(v.Tags); ok {
+			paths = append(paths, v.Path)
+		}
+	}
+	if len(paths) == 0 {
+		m.statusMessage = "No tags to change"
+		return m, nil
+	}
+	if m.tagManager != nil {
+		manager := *m.tagManager
+		manager.failures = nil
+		m.tagManager = &manager
+	}
+	m.tagJob = &tagJob{edit: edit, progress: &loadProgress{}}
+	m.tagJob.progress.SetTotal(len(paths))
+	m.statusMessage = fmt.Sprintf("Updating %d videos...", len(paths))
+	return m, tea.Batch(runTagEditCmd(paths, edit, m.tagJob.progress), tagJobTickerCmd())
+}
+
+func (m model) handleTagJobProgress(tagJobProgressMsg) (tea.Model, tea.Cmd) {
+	if m.tagJob == nil {
+		return m, nil
+	}
+	processed, total, _ := m.tagJob.progress.Snapshot()
+	m.statusMessage = fmt.Sprintf("Updating videos %d/%d...", processed, total)
+	return m, tagJobTickerCmd()
+}
+
+func (m model) handleTagJobDone(msg tagJobDoneMsg) (tea.Model, tea.Cmd) {
+	m.tagJob = nil
+	for i := range m.videos {
+		if updated, ok := msg.updated[m.videos[i].Path]; ok {
+			m.videos[i].Tags = updated
+		}
+	}
+	m.applyFiltersAndSort()
+	if change, ok := msg.edit.(tagChange); ok {
+		m.refreshTagManager(change.to)
+	}
+	m.statusMessage = fmt.Sprintf("%s in %d videos", msg.edit.describe(), len(msg.updated))
+	if len(msg.failures) > 0 {
+		m.statusMessage += "; " + summarizeTagFailures(msg.failures)
+		if m.tagManager != nil {
+			m.tagManager.failures = msg.failures
+		}
+	}
+	return m, nil
+}
+
+func (m model) renderTagManager() string {
+	manager := m.tagManager
+	var b strings.Builder
+	b.WriteString("Tags\n\n")
+	if len(manager.entries) == 0 {
+		b.WriteString("(no tags)\n")
+	}
+	start := min(max(manager.cursor-tagManagerRows/2, 0), max(len(manager.entries)-tagManagerRows, 0))
+	end := min(start+tagManagerRows, len(manager.entries))
+	for i := start; i < end; i++ {
+		entry := manager.entries[i]
+		line := fmt.Sprintf("  %s (%d)", entry.tag, entry.count)
+		if i == manager.cursor {
+			line = highlightStyle.Render(fmt.Sprintf("> %s (%d)", entry.tag, entry.count))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	if m.tagJob != nil {
+		processed, total, _ := m.tagJob.progress.Snapshot()
+		fmt.Fprintf(&b, "\nUpdating videos %s %d/%d\n", renderProgressBar(processed, total, 24), processed, total)
+	}
+	if len(manager.failures) > 0 {
+		fmt.Fprintf(&b, "\n%d videos could not be updated:\n", len(manager.failures))
+		for i, failure := range manager.failures {
+			if i == tagManagerRows {
+				fmt.Fprintf(&b, "  ... and %d more\n", len(manager.failures)-i)
+				break
+			}
+			fmt.Fprintf(&b, "  %s: %v\n", trimPath(failure.path), failure.err)
+		}
+	}
+	b.WriteString("\n↑/↓ select, r rename, m merge, d delete, Esc to close")
+	return filterStyle.Render(b.String())
+}
@@ -0,0 +1,115 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func newTagManagerModel(t *testing.T, library map[string][]string) (model, string) {
+	t.Helper()
+	root := t.TempDir()
+	var videos []video
+	for name, values := range library {
+		path := filepath.Join(root, name)
+		if err := tags.Save(path, values); err != nil {
+			t.Fatalf("save tags: %v", err)
+		}
+		videos = append(videos, video{Name: name, Path: path, Tags: values})
+	}
+	m := loadedModel(t, Options{Root: root}, videos)
+	modelAny, _ := m.handleKeyMsg(keyMsg("T"))
+	return modelAny.(model), root
+}
+
+// runTagJob executes the batch started by a tag change and feeds the
+// completion back into the model.
+func runTagJob(t *testing.T, m model, cmd tea.Cmd) model {
+	t.Helper()
+	if cmd == nil || m.tagJob == nil {
+		t.Fatal("expected a running tag change")
+	}
+	for _, sub := range cmd().(tea.BatchMsg) {
+		if done, ok := sub().(tagJobDoneMsg); ok {
+			modelAny, _ := m.Update(done)
+			return modelAny.(model)
+		}
+	}
+	t.Fatal("expected tagJobDoneMsg")
+	return m
+}
+
+func TestTagManagerRenamesAcrossLibrary(t *testing.T) {
+	m, root := newTagManagerModel(t, map[string][]string{
+		"a.mp4": {"hip", "morning"},
+		"b.mp4": {"hip"},
+		"c.mp4": {"evening"},
+	})
+	if got := m.tagManager.entries; !reflect.DeepEqual(got, []tagCount{{"evening", 1}, {"hip", 2}, {"morning", 1}}) {
+		t.Fatalf("unexpected counts %v", got)
+	}
+	modelAny, _ := m.handleKeyMsg(keyMsg("j"))
+	modelAny, _ = modelAny.(model).handleKeyMsg(keyMsg("r"))
+	m = modelAny.(model)
+	m.prompt.input.SetValue("hips")
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runTagJob(t, modelAny.(model), cmd)
+	if !strings.Contains(m.statusMessage, `Renamed "hip" to "hips" in 2 videos`) {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+	loaded, err := tags.Load(filepath.Join(root, "a.mp4"))
+	if err != nil || !reflect.DeepEqual(loaded, []string{"hips", "morning"}) {
+		t.Fatalf("expected sidecar rewritten, got %v (%v)", loaded, err)
+	}
+	if m.tagManager.selected() != "hips" || m.tagManager.entries[1].count != 2 {
+		t.Fatalf("expected manager refreshed, got %+v", m.tagManager)
+	}
+}
+
+func TestTagManagerMergesAndDeletes(t *testing.T) {
+	m, root := newTagManagerModel(t, map[string][]string{
+		"a.mp4": {"hip", "hips"},
+		"b.mp4": {"hip"},
+	})
+	modelAny, _ := m.handleKeyMsg(keyMsg("m"))
+	m = modelAny.(model)
+	if m.picker == nil || !reflect.DeepEqual(m.picker.items, []string{"hips"}) {
+		t.Fatal("expected merge targets")
+	}
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runTagJob(t, modelAny.(model), cmd)
+	loaded, _ := tags.Load(filepath.Join(root, "a.mp4"))
+	if !reflect.DeepEqual(loaded, []string{"hips"}) || !reflect.DeepEqual(m.tagManager.entries, []tagCount{{"hips", 2}}) {
+		t.Fatalf("expected merged tags, got %v and %v", loaded, m.tagManager.entries)
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("d"))
+	modelAny, _ = modelAny.(model).handleKeyMsg(keyMsg("j"))
+	modelAny, cmd = modelAny.(model).handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = runTagJob(t, modelAny.(model), cmd)
+	if len(m.tagManager.entries) != 0 || len(m.videos[0].Tags) != 0 {
+		t.Fatalf("expected tag deleted, got %v", m.tagManager.entries)
+	}
+}
+
+func TestTagManagerReportsFailures(t *testing.T) {
+	m, root := newTagManagerModel(t, map[string][]string{"a.mp4": {"hip"}})
+	broken := filepath.Join(root, "broken.mp4")
+	if err := os.Mkdir(tags.PathFor(broken), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	m.videos = append(m.videos, video{Name: "broken.mp4", Path: broken, Tags: []string{"hip"}})
+	modelAny, cmd := m.startTagChange(tagChange{from: "hip"})
+	m = runTagJob(t, modelAny.(model), cmd)
+	if !strings.Contains(m.statusMessage, "in 1 videos; 1 failed (broken.mp4:") {
+		t.Fatalf("expected failure summary, got %q", m.statusMessage)
+	}
+	if len(m.tagManager.failures) != 1 || !strings.Contains(m.View(), "could not be updated") {
+		t.Fatal("expected failures listed in the manager")
+	}
+}
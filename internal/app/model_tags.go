@@ -26,6 +26,8 @@ func (m model) openTagEditor() (tea.Model, tea.Cmd) {
 	m.tagInput.SetValue(strings.Join(video.Tags, ", "))
 	m.tagInput.CursorEnd()
 	m.tagInput.Focus()
+	m.tagComplete = tagCompletion{vocabulary: tagVocabulary(m.videos, m.tagPolicy)}
+	m.refreshTagSuggestions()
 	m.statusMessage = fmt.Sprintf("Editing tags for %s", video.Name)
 	return m, nil
 }
@@ -40,12 +42,35 @@ func (m model) handleTagKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, nil
 	case "enter":
 		return m.commitTags()
+	case "tab":
+		if len(m.tagComplete.suggestions) > 0 {
+			m.tagInput.SetValue(completeTag(m.tagInput.Value(), m.tagComplete.suggestions[m.tagComplete.cursor]))
+			m.tagInput.CursorEnd()
+			m.refreshTagSuggestions()
+		}
+		return m, nil
+	case "up":
+		if m.tagComplete.cursor > 0 {
+			m.tagComplete.cursor--
+		}
+		return m, nil
+	case "down":
+		if m.tagComplete.cursor < len(m.tagComplete.suggestions)-1 {
+			m.tagComplete.cursor++
+		}
+		return m, nil
 	}
 	var cmd tea.Cmd
 	m.tagInput, cmd = m.tagInput.Update(msg)
+	m.refreshTagSuggestions()
 	return m, cmd
 }
 
+func (m *model) refreshTagSuggestions() {
+	m.tagComplete.suggestions = suggestTags(m.tagComplete.vocabulary, m.tagInput.Value())
+	m.tagComplete.cursor = 0
+}
+
 func (m model) commitTags() (tea.Model, tea.Cmd) {
 	if m.tagEditPath == "" {
 		m.editingTags = false
@@ -54,7 +79,7 @@ func (m model) commitTags() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	value := m.tagInput.Value()
-	tags := parseTagInput(value)
+	tags := m.tagPolicy.Normalize(parseTagInput(value))
 	m.editingTags = false
 	m.tagInput.Blur()
 	path := m.tagEditPath
@@ -129,7 +154,16 @@ func (m model) renderTagModal() string {
 	b.WriteString("Edit tags\n")
 	b.WriteString("(comma separated)\n\n")
 	b.WriteString(m.tagInput.View())
+	b.WriteString("\n")
+	for i, tag := range m.tagComplete.suggestions {
+		line := "  " + tag
+		if i == m.tagComplete.cursor {
+			line = highlightStyle.Render("> " + tag)
+		}
+		b.WriteString("\n")
+		b.WriteString(line)
+	}
 	b.WriteString("\n\n")
-	b.WriteString("Enter to save, Esc to cancel")
+	b.WriteString("Tab to complete, Enter to save, Esc to cancel")
 	return filterStyle.Render(b.String())
 }
@@ -11,6 +11,8 @@ import (
 
 	"github.com/charmbracelet/bubbles/table"
 	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/probe"
 )
 
 func TestModelHandleVideosLoadedAndSort(t *testing.T) {
@@ -56,7 +58,7 @@ func TestModelHandleDurationUpdateCompletes(t *testing.T) {
 		t.Fatalf("expected duration command")
 	}
 	m = modelAny.(model)
-	durMsg := durationUpdateMsg{path: pendingPath, duration: time.Minute}
+	durMsg := durationUpdateMsg{path: pendingPath, media: probe.Info{Duration: time.Minute}}
 	modelAny, next := m.handleDurationUpdate(durMsg)
 	m = modelAny.(model)
 	if next != nil {
@@ -284,7 +286,7 @@ func TestModelUpdateDurationMsg(t *testing.T) {
 	m.pendingDurations = []string{"a.mp4"}
 	m.durationTotal = 1
 	m.cache = newDurationCache("cache.json")
-	update := durationUpdateMsg{path: "a.mp4", duration: time.Second}
+	update := durationUpdateMsg{path: "a.mp4", media: probe.Info{Duration: time.Second}}
 	modelAny, _ := m.Update(update)
 	m = modelAny.(model)
 	if m.durationTotal != 0 {
@@ -633,14 +635,44 @@ func TestWindowResizeShrinksColumnsGracefully(t *testing.T) {
 	modelAny, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 40})
 	m = modelAny.(model)
 	cols := m.table.Columns()
-	if len(cols) != 4 {
-		t.Fatalf("expected 4 columns")
+	if len(cols) != 6 {
+		t.Fatalf("expected 6 columns")
 	}
 	if cols[0].Width < nameColumnFloorWidth {
 		t.Fatalf("expected name column >= floor, got %d", cols[0].Width)
 	}
-	if cols[3].Width < tagsColumnFloorWidth {
-		t.Fatalf("expected tags column >= floor, got %d", cols[3].Width)
+	if cols[5].Width < tagsColumnFloorWidth {
+		t.Fatalf("expected tags column >= floor, got %d", cols[5].Width)
+	}
+}
+
+func TestCustomColumnsRenderMediaDetails(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir(), Columns: []string{"name", "Resolution", "codecs", "audio", "bitrate", "name"}})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "a.mkv", Width: 1920, Height: 1080, VideoCodec: "h264", AudioCodec: "aac", AudioLanguages: []string{"eng", "deu"}, Bitrate: 4_200_000}}
+	m.applyFiltersAndSort()
+	if cols := m.table.Columns(); len(cols) != 5 {
+		t.Fatalf("expected 5 columns, got %d", len(cols))
+	}
+	row := m.table.Rows()[0]
+	want := []string{"a.mkv", "1920x1080", "h264/aac", "eng,deu", "4.2 Mb/s"}
+	for i, cell := range want {
+		if row[i] != cell {
+			t.Fatalf("column %d: expected %q, got %q", i, cell, row[i])
+		}
+	}
+	modelAny, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	if cols := modelAny.(model).table.Columns(); len(cols) != 5 {
+		t.Fatalf("expected resize to keep custom columns, got %d", len(cols))
+	}
+}
+
+func TestNewModelRejectsUnknownColumn(t *testing.T) {
+	if _, err := newModel(Options{Root: t.TempDir(), Columns: []string{"name", "fps"}}); err == nil {
+		t.Fatal("expected error for unknown column")
 	}
 }
 
@@ -696,7 +728,7 @@ func TestLoadVideosCmdProducesMessage(t *testing.T) {
 	if err := os.WriteFile(video, []byte("x"), 0o644); err != nil {
 		t.Fatalf("write: %v", err)
 	}
-	cmd := loadVideosCmd(root, filepath.Join(root, "cache.json"), &loadProgress{})
+	cmd := loadVideosCmd([]Root{{Path: root}}, &loadProgress{})
 	msg := cmd()
 	if _, ok := msg.(videosLoadedMsg); !ok {
 		t.Fatalf("expected videosLoadedMsg")
@@ -712,6 +744,29 @@ func TestProgressTickerCmdProducesMsg(t *testing.T) {
 	}
 }
 
+// loadedModel returns a model for opts showing videos, as if the library
+// scan had finished.
+func loadedModel(t *testing.T, opts Options, videos []video) model {
+	t.Helper()
+	m, err := newModel(opts)
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = videos
+	m.applyFiltersAndSort()
+	return m
+}
+
+func typeText(t *testing.T, m model, text string) model {
+	t.Helper()
+	for _, r := range text {
+		modelAny, _ := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = modelAny.(model)
+	}
+	return m
+}
+
 func keyMsg(value string) tea.KeyMsg {
 	if len(value) == 1 {
 		return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)}
@@ -1134,12 +1189,12 @@ func TestDurationCacheRecord(t *testing.T) {
 		t.Fatalf("stat: %v", err)
 	}
 
-	if err := cache.Record(videoPath, info, 5*time.Minute); err != nil {
+	if err := cache.Record(videoPath, info, probe.Info{Duration: 5 * time.Minute}); err != nil {
 		t.Fatalf("Record: %v", err)
 	}
 
 	result, ok := cache.Lookup(videoPath, info)
-	if !ok || result != 5*time.Minute {
+	if !ok || result.Duration != 5*time.Minute {
 		t.Fatalf("expected duration to be recorded and retrieved")
 	}
 }
@@ -0,0 +1,165 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func (m model) handleWatcherStarted(msg watcherStartedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if !errors.Is(msg.err, errWatchUnsupported) {
+			m.statusMessage = fmt.Sprintf("Live updates unavailable: %v", msg.err)
+		}
+		return m, nil
+	}
+	m.watcher = msg.watcher
+	return m, waitForLibraryChangeCmd(m.watcher)
+}
+
+// handleLibraryChanged applies a batch of watcher changes to m.videos and
+// probes only the videos that are new or were rewritten.
+func (m model) handleLibraryChanged(msg libraryChangedMsg) (tea.Model, tea.Cmd) {
+	wait := waitForLibraryChangeCmd(m.watcher)
+	selectedPath := m.currentSelectionPath()
+	var toProbe []string
+	added, removed := 0, 0
+	for _, change := range msg.changes {
+		switch change.kind {
+		case changeRescan:
+			next, cmd := m.handleReindexVideos(reindexVideosMsg{})
+			return next, tea.Batch(cmd, wait)
+		case changeAdded:
+			if m.videoIndex(change.path) < 0 {
+				added++
+			}
+			if m.upsertVideo(change.path) {
+				toProbe = append(toProbe, change.path)
+			}
+		case changeRemoved:
+			removed += m.removeVideos(change.path, change.dir)
+		case changeRenamed:
+			if !m.renameVideo(change.oldPath, change.path) {
+				added++
+				if m.upsertVideo(change.path) {
+					toProbe = append(toProbe, change.path)
+				}
+			}
+		}
+	}
+	m.applyHistory()
+	m.applyFiltersAndSort()
+	m.restoreSelection(selectedPath)
+	if added > 0 || removed > 0 {
+		m.statusMessage = fmt.Sprintf("Library updated: +%d / -%d", added, removed)
+	}
+	return m, tea.Batch(wait, m.queueDurationProbes(toProbe))
+}
+
+func (m model) videoIndex(path string) int {
+	for i, v := range m.videos {
+		if v.Path == path {
+			return i
+		}
+	}
+	return -1
+}
+
+// upsertVideo loads path into m.videos, replacing an existing entry, and
+// reports whether its duration still needs probing.
+func (m *model) upsertVideo(path string) bool {
+	v, needsProbe, _ := loadVideo(path, m.cache)
+	v.Source = m.sourceFor(path)
+	if idx := m.videoIndex(path); idx >= 0 {
+		m.videos[idx] = v
+	} else {
+		m.videos = append(m.videos, v)
+	}
+	return needsProbe
+}
+
+// removeVideos drops path, or every video below it when dir is set, along
+// with their cache entries and returns how many videos were removed.
+func (m *model) removeVideos(path string, dir bool) int {
+	prefix := path + string(filepath.Separator)
+	kept := m.videos[:0]
+	var gone []string
+	for _, v := range m.videos {
+		if v.Path == path || (dir && strings.HasPrefix(v.Path, prefix)) {
+			gone = append(gone, v.Path)
+			continue
+		}
+		kept = append(kept, v)
+	}
+	m.videos = kept
+	if m.cache.Forget(gone...) {
+		_ = m.cache.Flush()
+	}
+	return len(gone)
+}
+
+// renameVideo moves an entry and its cache entry to the new path, keeping
+// the probed details since the file content is unchanged. Tags are re-read
+// because sidecars are named after the video.
+func (m *model) renameVideo(oldPath, newPath string) bool {
+	idx := m.videoIndex(oldPath)
+	if idx < 0 {
+		return false
+	}
+	updated, _, _ := loadVideo(newPath, nil)
+	v := m.videos[idx]
+	v.Name = updated.Name
+	v.Path = newPath
+	v.Source = m.sourceFor(newPath)
+	v.Tags = updated.Tags
+	v.Details = updated.Details
+	m.videos[idx] = v
+	if existing := m.videoIndex(newPath); existing >= 0 && existing != idx {
+		m.videos = append(m.videos[:existing], m.videos[existing+1:]...)
+	}
+	if m.cache.Rename(oldPath, newPath) {
+		_ = m.cache.Flush()
+	}
+	return true
+}
+
+func (m *model) queueDurationProbes(paths []string) tea.Cmd {
+	if len(paths) == 0 {
+		return nil
+	}
+	m.pendingDurations = append(m.pendingDurations, paths...)
+	m.durationTotal += len(paths)
+	if m.durationInFlight > 0 {
+		// Running workers pick the new paths up as they finish.
+		return nil
+	}
+	return m.startDurationWorkers()
+}
+
+// sourceFor returns the label of the root containing path.
+func (m model) sourceFor(path string) string {
+	root, _ := rootFor(m.roots, path)
+	return root.Label
+}
+
+// videosWithin returns the loaded videos that live below any of roots.
+func (m model) videosWithin(roots []Root) []video {
+	var out []video
+	for _, v := range m.videos {
+		if _, ok := rootFor(roots, v.Path); ok {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func (m model) rootPaths() []string {
+	paths := make([]string, 0, len(m.roots))
+	for _, root := range m.roots {
+		paths = append(paths, root.Path)
+	}
+	return paths
+}
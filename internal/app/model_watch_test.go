@@ -0,0 +1,97 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func TestHandleLibraryChangedUpdatesVideosIncrementally(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	sub := filepath.Join(root, "series")
+	keep := filepath.Join(root, "keep.mp4")
+	oldName := filepath.Join(root, "old.mp4")
+	m.videos = []video{
+		{Name: "keep.mp4", Path: keep, Duration: time.Minute},
+		{Name: "old.mp4", Path: oldName, Duration: 20 * time.Minute},
+		{Name: "a.mp4", Path: filepath.Join(sub, "a.mp4"), Duration: time.Minute},
+		{Name: "b.mp4", Path: filepath.Join(sub, "b.mp4"), Duration: time.Minute},
+	}
+	m.applyFiltersAndSort()
+	m.restoreSelection(keep)
+
+	newName := filepath.Join(root, "new.mp4")
+	if err := os.WriteFile(newName, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := tags.Save(newName, []string{"hips"}); err != nil {
+		t.Fatalf("save tags: %v", err)
+	}
+	newInfo, err := os.Stat(newName)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	m.cache = newDurationCache(filepath.Join(root, durationCacheFile))
+	if err := m.cache.Record(oldName, newInfo, probe.Info{Duration: 20 * time.Minute}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	fresh := filepath.Join(root, "fresh.mp4")
+	if err := os.WriteFile(fresh, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	modelAny, cmd := m.handleLibraryChanged(libraryChangedMsg{changes: []libraryChange{
+		{kind: changeAdded, path: fresh},
+		{kind: changeRenamed, oldPath: oldName, path: newName},
+		{kind: changeRemoved, path: sub, dir: true},
+	}})
+	m = modelAny.(model)
+	if cmd == nil {
+		t.Fatal("expected probe command for the new video")
+	}
+	if len(m.videos) != 3 {
+		t.Fatalf("expected 3 videos, got %+v", m.videos)
+	}
+	renamed := m.videos[m.videoIndex(newName)]
+	if renamed.Duration != 20*time.Minute || len(renamed.Tags) != 1 {
+		t.Fatalf("expected rename to keep duration and load tags, got %+v", renamed)
+	}
+	if media, ok := m.cache.Lookup(newName, newInfo); !ok || media.Duration != 20*time.Minute {
+		t.Fatalf("expected the cache entry to follow the rename, got %+v %v", media, ok)
+	}
+	if m.videoIndex(fresh) < 0 || len(m.pendingDurations) != 0 || m.durationInFlight != 1 || m.durationTotal != 1 {
+		t.Fatalf("expected only the new video to be probed: pending=%v inFlight=%d total=%d", m.pendingDurations, m.durationInFlight, m.durationTotal)
+	}
+	if m.currentSelectionPath() != keep {
+		t.Fatalf("expected selection to stay on %s, got %s", keep, m.currentSelectionPath())
+	}
+	if m.statusMessage != "Library updated: +1 / -2" {
+		t.Fatalf("unexpected status %q", m.statusMessage)
+	}
+
+	modelAny, _ = m.handleDurationUpdate(durationUpdateMsg{path: fresh, media: probe.Info{Duration: time.Minute}})
+	m = modelAny.(model)
+	if m.videos[m.videoIndex(fresh)].Duration != time.Minute || !m.allDurationsResolved() {
+		t.Fatalf("expected probe result to be applied")
+	}
+}
+
+func TestHandleLibraryChangedRescanReindexes(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	modelAny, cmd := m.handleLibraryChanged(libraryChangedMsg{changes: []libraryChange{{kind: changeRescan}}})
+	if cmd == nil || modelAny.(model).statusMessage != "Re-indexing videos..." {
+		t.Fatalf("expected a re-index after lost events")
+	}
+}
@@ -1,7 +1,70 @@
 package app
 
+import (
+	"path/filepath"
+	"strings"
+)
+
 // Options configures the Yoga application runtime.
 type Options struct {
+	// Root is the primary library root. Positions, history and playlists
+	// are stored there.
 	Root string
-	Crop string
+	// Roots lists every library root. When empty, Root is the only one.
+	Roots      []Root
+	Crop       string
+	Player     string
+	Fullscreen bool
+	Volume     int
+	// Columns lists the table columns by name in display order; empty
+	// selects the defaults.
+	Columns []string
+	// Filter names a saved filter applied at start-up.
+	Filter string
+	// WeightedRandom makes the random pick favour highly rated videos.
+	WeightedRandom bool
+}
+
+// Root is a library directory with an optional label shown in the Source
+// column.
+type Root struct {
+	Label string
+	Path  string
+}
+
+// libraryRoots returns the configured roots with labels filled in from the
+// directory names.
+func (o Options) libraryRoots() []Root {
+	roots := o.Roots
+	if len(roots) == 0 {
+		roots = []Root{{Path: o.Root}}
+	}
+	out := make([]Root, 0, len(roots))
+	for _, root := range roots {
+		if root.Label == "" {
+			root.Label = filepath.Base(root.Path)
+		}
+		out = append(out, root)
+	}
+	return out
+}
+
+// rootFor returns the most specific root containing path.
+func rootFor(roots []Root, path string) (Root, bool) {
+	var best Root
+	found := false
+	for _, root := range roots {
+		if isWithinRoot(path, root.Path) && (!found || len(root.Path) > len(best.Path)) {
+			best, found = root, true
+		}
+	}
+	return best, found
+}
+
+func isWithinRoot(path, root string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
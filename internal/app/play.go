@@ -0,0 +1,85 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+)
+
+// PlayRequest configures Play.
+type PlayRequest struct {
+	Selection
+	// Random picks a random matching video instead of the first one; it
+	// honours Options.WeightedRandom.
+	Random bool
+	// FromStart ignores the stored resume position.
+	FromStart bool
+}
+
+// Play launches one video of the library matching the request with the
+// configured player and waits for the player to exit, recording the launch
+// in the practice log and the resume position like the TUI does.
+func Play(opts Options, req PlayRequest, stdout, stderr io.Writer) error {
+	player, err := NewPlayer(opts.Player)
+	if err != nil {
+		return err
+	}
+	snap, err := loadSnapshot(opts)
+	printWarnings(stderr, snap.warnings)
+	if err != nil {
+		return err
+	}
+	selected, err := req.selectVideos(snap.videos, snap.savedFilters)
+	if err != nil {
+		return err
+	}
+	if len(selected) == 0 {
+		return errors.New("no video matches")
+	}
+	idx := 0
+	if req.Random {
+		idx = randomIndex(selected, -1, opts.WeightedRandom)
+	}
+	v := selected[idx]
+
+	positions, err := loadPositionStore(filepath.Join(opts.Root, ".video_positions.json"))
+	if err != nil {
+		// Carry on without resuming rather than overwrite the file.
+		printWarnings(stderr, []error{fmt.Errorf("resume positions: %w", err)})
+		positions = nil
+	}
+	playOpts := PlayOptions{Crop: opts.Crop, Fullscreen: opts.Fullscreen, Volume: opts.Volume}
+	if !req.FromStart {
+		playOpts.Start = model{positions: positions}.resumePosition(v)
+	}
+	launched := playVideoCmd(player, v.Path, playOpts)().(playVideoMsg)
+	if launched.err != nil {
+		return fmt.Errorf("launch %s: %w", player.Name(), launched.err)
+	}
+	if playOpts.Start > 0 {
+		fmt.Fprintf(stdout, "Resuming %s at %s via %s\n", v.Path, formatDuration(playOpts.Start), player.Name())
+	} else {
+		fmt.Fprintf(stdout, "Playing %s via %s\n", v.Path, player.Name())
+	}
+	if snap.history != nil {
+		snap.history.RecordStart(v.Path, launched.started)
+		if err := snap.history.Flush(); err != nil {
+			printWarnings(stderr, []error{fmt.Errorf("play history: %w", err)})
+		}
+	}
+	ended := <-launched.done
+	if snap.history != nil {
+		snap.history.RecordEnd(v.Path, ended.started, ended.elapsed)
+		if err := snap.history.Flush(); err != nil {
+			printWarnings(stderr, []error{fmt.Errorf("play history: %w", err)})
+		}
+	}
+	if ended.position > 0 && positions != nil {
+		positions.RecordStop(v.Path, ended.position, v.Duration)
+		if err := positions.Flush(); err != nil {
+			printWarnings(stderr, []error{fmt.Errorf("resume positions: %w", err)})
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// recordingPlayer returns a player template whose command appends the
+// played path to the returned log file.
+func recordingPlayer(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	log := filepath.Join(dir, "played.log")
+	script := filepath.Join(dir, "player")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\necho \"$1\" >> '"+log+"'\n"), 0o755); err != nil {
+		t.Fatalf("write player: %v", err)
+	}
+	return script + " {path}", log
+}
+
+func TestPlayLaunchesMatchingVideo(t *testing.T) {
+	opts := writeListLibrary(t)
+	var log string
+	opts.Player, log = recordingPlayer(t)
+	var stdout, stderr bytes.Buffer
+	req := PlayRequest{Selection: Selection{Tags: "hips", MinMinutes: 20}}
+	if err := Play(opts, req, &stdout, &stderr); err != nil {
+		t.Fatalf("Play: %v (%s)", err, stderr.String())
+	}
+	played, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	want := filepath.Join(opts.Root, "Medium Hips.mp4")
+	if strings.TrimSpace(string(played)) != want {
+		t.Fatalf("expected %s to be played, got %q", want, played)
+	}
+	if !strings.Contains(stdout.String(), "Playing "+want) {
+		t.Fatalf("expected playing message, got %q", stdout.String())
+	}
+	history, err := loadPlayHistory(filepath.Join(opts.Root, ".video_history.json"))
+	if err != nil {
+		t.Fatalf("load history: %v", err)
+	}
+	if history.Stats()[want].Count != 1 {
+		t.Fatalf("expected the launch in the practice log, got %+v", history.Stats())
+	}
+}
+
+func TestPlayRandomStaysWithinSelection(t *testing.T) {
+	opts := writeListLibrary(t)
+	var log string
+	opts.Player, log = recordingPlayer(t)
+	var stdout, stderr bytes.Buffer
+	req := PlayRequest{Selection: Selection{Tags: "hips"}, Random: true}
+	for range 5 {
+		if err := Play(opts, req, &stdout, &stderr); err != nil {
+			t.Fatalf("Play: %v", err)
+		}
+	}
+	played, err := os.ReadFile(log)
+	if err != nil {
+		t.Fatalf("read log: %v", err)
+	}
+	for _, path := range strings.Fields(strings.ReplaceAll(string(played), " ", "_")) {
+		if !strings.Contains(path, "Hips") {
+			t.Fatalf("random pick left the selection: %s", path)
+		}
+	}
+	if err := Play(opts, PlayRequest{Selection: Selection{Tags: "inversions"}}, &stdout, &stderr); err == nil {
+		t.Fatal("expected an error when nothing matches")
+	}
+}
+
+func TestPlayKeepsCorruptPositionsFile(t *testing.T) {
+	opts := writeListLibrary(t)
+	opts.Player, _ = recordingPlayer(t)
+	path := filepath.Join(opts.Root, ".video_positions.json")
+	if err := os.WriteFile(path, []byte(`{"a.mp4":`), 0o644); err != nil {
+		t.Fatalf("write positions: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := Play(opts, PlayRequest{Selection: Selection{Name: "Long"}}, &stdout, &stderr); err != nil {
+		t.Fatalf("Play: %v", err)
+	}
+	if !strings.Contains(stderr.String(), "warning: resume positions") {
+		t.Fatalf("expected a warning, got %q", stderr.String())
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"a.mp4":` {
+		t.Fatalf("expected the positions file to be kept, got %q", data)
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	positionPollInterval = 2 * time.Second
+	positionQueryTimeout = time.Second
+	// resumeTailThreshold treats playback that stopped this close to the end
+	// as finished, so the next launch starts from the beginning again.
+	resumeTailThreshold = 30 * time.Second
+)
+
+var socketCounter atomic.Int64
+
+func positionsLoadedCmd(path string) tea.Cmd {
+	return func() tea.Msg {
+		store, err := loadPositionStore(path)
+		return positionsLoadedMsg{store: store, err: err}
+	}
+}
+
+func playVideoCmd(p Player, path string, opts PlayOptions) tea.Cmd {
+	return func() tea.Msg {
+		tracker, _ := p.(positionTracker)
+		if tracker != nil {
+			opts.IPCSocket = newIPCSocketPath()
+		}
+		name, args := p.Command(path, opts)
+		cmd := exec.Command(name, args...)
+		started := time.Now()
+		if err := cmd.Start(); err != nil {
+			return playVideoMsg{path: path, player: p.Name(), err: err}
+		}
+		done := make(chan playbackEndedMsg, 1)
+		go monitorPlayback(cmd, tracker, opts, path, started, done)
+		return playVideoMsg{path: path, player: p.Name(), started: started, done: done}
+	}
+}
+
+// waitForPlaybackCmd blocks until the player launched for a playVideoMsg exits.
+func waitForPlaybackCmd(done <-chan playbackEndedMsg) tea.Cmd {
+	if done == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return <-done
+	}
+}
+
+func newIPCSocketPath() string {
+	name := fmt.Sprintf("yoga-%d-%d.sock", os.Getpid(), socketCounter.Add(1))
+	return filepath.Join(os.TempDir(), name)
+}
+
+// monitorPlayback polls the player for its position until the process exits
+// and then reports the last known position. Players that take the volume
+// over the socket get it once the first connection is up.
+func monitorPlayback(cmd *exec.Cmd, tracker positionTracker, opts PlayOptions, path string, started time.Time, done chan<- playbackEndedMsg) {
+	socket := opts.IPCSocket
+	setter, _ := tracker.(volumeSetter)
+	volume := opts.Volume
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	ticker := time.NewTicker(positionPollInterval)
+	defer ticker.Stop()
+	var conn net.Conn
+	var rw *bufio.ReadWriter
+	var position time.Duration
+	for {
+		select {
+		case err := <-exited:
+			if conn != nil {
+				_ = conn.Close()
+			}
+			if socket != "" {
+				_ = os.Remove(socket)
+			}
+			done <- playbackEndedMsg{path: path, started: started, position: position, elapsed: time.Since(started), err: err}
+			return
+		case <-ticker.C:
+			if tracker == nil {
+				continue
+			}
+			if conn == nil {
+				dialed, err := net.DialTimeout("unix", socket, positionQueryTimeout)
+				if err != nil {
+					// The player may not have created its socket yet.
+					continue
+				}
+				conn = dialed
+				rw = bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+			}
+			_ = conn.SetDeadline(time.Now().Add(positionQueryTimeout))
+			if setter != nil && volume > 0 {
+				if err := setter.SetVolume(rw, volume); err != nil {
+					_ = conn.Close()
+					conn = nil
+					continue
+				}
+				volume = 0
+			}
+			pos, err := tracker.QueryPosition(rw)
+			if err != nil {
+				_ = conn.Close()
+				conn = nil
+				continue
+			}
+			if pos > 0 {
+				position = pos
+			}
+		}
+	}
+}
+
+// resumePosition returns where playback of v should start, ignoring stored
+// positions that are too close to the end of the video.
+func (m model) resumePosition(v video) time.Duration {
+	pos, ok := m.positions.Lookup(v.Path)
+	if !ok {
+		return 0
+	}
+	if v.Duration > 0 && pos >= v.Duration-resumeTailThreshold {
+		return 0
+	}
+	return pos
+}
+
+func (m model) handlePositionsLoaded(msg positionsLoadedMsg) (tea.Model, tea.Cmd) {
+	m.positions = msg.store
+	if msg.err != nil {
+		// Without a store nothing is flushed over the file that failed to
+		// load; playback simply starts from the beginning.
+		m.positions = nil
+		m.statusMessage = fmt.Sprintf("Resume position warning: %v (positions are not saved until it is fixed)", msg.err)
+	}
+	return m, nil
+}
+
+func (m model) handlePlaybackEnded(msg playbackEndedMsg) (tea.Model, tea.Cmd) {
+	m.recordPlayEnd(msg)
+	m.recordPosition(msg)
+	return m, m.advanceSequence(msg)
+}
+
+func (m *model) recordPosition(msg playbackEndedMsg) {
+	if m.positions == nil || msg.position <= 0 {
+		return
+	}
+	name := filepath.Base(msg.path)
+	if m.positions.RecordStop(msg.path, msg.position, m.videoDuration(msg.path)) {
+		m.statusMessage = fmt.Sprintf("Finished %s", name)
+	} else {
+		m.statusMessage = fmt.Sprintf("Stopped %s at %s (enter resumes, b restarts)", name, formatDuration(msg.position))
+	}
+	if err := m.positions.Flush(); err != nil {
+		m.statusMessage = fmt.Sprintf("Resume position save error: %v", err)
+	}
+}
+
+func (m model) videoDuration(path string) time.Duration {
+	for _, v := range m.videos {
+		if v.Path == path {
+			return v.Duration
+		}
+	}
+	return 0
+}
@@ -0,0 +1,176 @@
+package app
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func fakePlayerConn(t *testing.T, serve func(*bufio.ReadWriter)) *bufio.ReadWriter {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() {
+		_ = client.Close()
+		_ = server.Close()
+	})
+	go serve(bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server)))
+	return bufio.NewReadWriter(bufio.NewReader(client), bufio.NewWriter(client))
+}
+
+func TestMPVQueryPosition(t *testing.T) {
+	rw := fakePlayerConn(t, func(srv *bufio.ReadWriter) {
+		line, _ := srv.ReadString('\n')
+		if !strings.Contains(line, "time-pos") {
+			return
+		}
+		srv.WriteString(`{"event":"playback-restart"}` + "\n")
+		srv.WriteString(`{"data":754.25,"request_id":0,"error":"success"}` + "\n")
+		srv.Flush()
+	})
+	pos, err := mpvPlayer{}.QueryPosition(rw)
+	if err != nil {
+		t.Fatalf("QueryPosition: %v", err)
+	}
+	if pos != 754250*time.Millisecond {
+		t.Fatalf("unexpected position %v", pos)
+	}
+}
+
+func TestMPVQueryPositionError(t *testing.T) {
+	rw := fakePlayerConn(t, func(srv *bufio.ReadWriter) {
+		srv.ReadString('\n')
+		srv.WriteString(`{"request_id":0,"error":"property unavailable"}` + "\n")
+		srv.Flush()
+	})
+	if _, err := (mpvPlayer{}).QueryPosition(rw); err == nil {
+		t.Fatal("expected error for unavailable property")
+	}
+}
+
+func TestVLCQueryPosition(t *testing.T) {
+	rw := fakePlayerConn(t, func(srv *bufio.ReadWriter) {
+		line, _ := srv.ReadString('\n')
+		if strings.TrimSpace(line) != "get_time" {
+			return
+		}
+		srv.WriteString("VLC media player 3.0.20 Vetinari\nCommand Line Interface initialized.\n> 321\n")
+		srv.Flush()
+	})
+	pos, err := vlcPlayer{}.QueryPosition(rw)
+	if err != nil {
+		t.Fatalf("QueryPosition: %v", err)
+	}
+	if pos != 321*time.Second {
+		t.Fatalf("unexpected position %v", pos)
+	}
+}
+
+func TestVLCSetVolume(t *testing.T) {
+	got := make(chan string, 1)
+	rw := fakePlayerConn(t, func(srv *bufio.ReadWriter) {
+		line, _ := srv.ReadString('\n')
+		got <- line
+	})
+	if err := (vlcPlayer{}).SetVolume(rw, 50); err != nil {
+		t.Fatalf("SetVolume: %v", err)
+	}
+	if line := <-got; line != "volume 128\n" {
+		t.Fatalf("unexpected command %q", line)
+	}
+}
+
+func TestPlayVideoCmdReportsPlaybackEnd(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "fakeplayer")
+	if err := os.WriteFile(script, []byte("#!/bin/sh\nexit 0\n"), 0o755); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	p, err := NewPlayer(script + " {path}")
+	if err != nil {
+		t.Fatalf("NewPlayer: %v", err)
+	}
+	msg := playVideoCmd(p, "clip.mp4", PlayOptions{})().(playVideoMsg)
+	if msg.err != nil {
+		t.Fatalf("unexpected launch error: %v", msg.err)
+	}
+	ended, ok := waitForPlaybackCmd(msg.done)().(playbackEndedMsg)
+	if !ok || ended.path != "clip.mp4" || ended.err != nil {
+		t.Fatalf("unexpected playback end %+v", ended)
+	}
+	if waitForPlaybackCmd(nil) != nil {
+		t.Fatal("expected nil wait command without a session")
+	}
+}
+
+func TestHandlePlaybackEndedRecordsPosition(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.positions = newPositionStore(m.positionsPath)
+	vid := video{Name: "class.mp4", Path: filepath.Join(root, "class.mp4"), Duration: 90 * time.Minute}
+	m.videos = []video{vid}
+	m.applyFiltersAndSort()
+	modelAny, _ := m.Update(playbackEndedMsg{path: vid.Path, position: 45 * time.Minute})
+	m = modelAny.(model)
+	if !strings.Contains(m.statusMessage, "Stopped class.mp4 at 45:00") {
+		t.Fatalf("unexpected status %s", m.statusMessage)
+	}
+	if _, err := os.Stat(m.positionsPath); err != nil {
+		t.Fatalf("expected positions persisted: %v", err)
+	}
+	modelAny, _ = m.playSelection()
+	if status := modelAny.(model).statusMessage; !strings.Contains(status, "Resuming class.mp4 at 45:00") {
+		t.Fatalf("expected resume status, got %s", status)
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("b"))
+	if status := modelAny.(model).statusMessage; !strings.Contains(status, "Launching") {
+		t.Fatalf("expected launch from start, got %s", status)
+	}
+	modelAny, _ = m.Update(playbackEndedMsg{path: vid.Path, position: 89*time.Minute + 45*time.Second})
+	m = modelAny.(model)
+	if _, ok := m.positions.Lookup(vid.Path); ok {
+		t.Fatal("expected position cleared after finishing")
+	}
+	if !strings.Contains(m.statusMessage, "Finished") {
+		t.Fatalf("unexpected status %s", m.statusMessage)
+	}
+}
+
+func TestHandlePositionsLoaded(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	msg := positionsLoadedCmd(m.positionsPath)().(positionsLoadedMsg)
+	modelAny, _ := m.Update(msg)
+	m = modelAny.(model)
+	if m.positions == nil {
+		t.Fatal("expected position store attached")
+	}
+}
+
+func TestHandlePositionsLoadedKeepsCorruptFile(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	if err := os.WriteFile(m.positionsPath, []byte(`{"a.mp4":`), 0o644); err != nil {
+		t.Fatalf("write positions: %v", err)
+	}
+	modelAny, _ := m.Update(positionsLoadedCmd(m.positionsPath)())
+	m = modelAny.(model)
+	if m.positions != nil || !strings.Contains(m.statusMessage, "Resume position warning") {
+		t.Fatalf("expected no store and a warning, got %v %q", m.positions, m.statusMessage)
+	}
+	m.recordPosition(playbackEndedMsg{path: "b.mp4", position: time.Minute})
+	if data, _ := os.ReadFile(m.positionsPath); string(data) != `{"a.mp4":` {
+		t.Fatalf("expected the positions file to be kept, got %q", data)
+	}
+}
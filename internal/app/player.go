@@ -0,0 +1,321 @@
+package app
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PlayOptions carries per-launch settings that each player translates into
+// its own command-line arguments.
+type PlayOptions struct {
+	Crop       string
+	Start      time.Duration
+	Fullscreen bool
+	Volume     int
+	// IPCSocket is the unix socket a player should expose for position
+	// queries. It is only set for players implementing positionTracker.
+	IPCSocket string
+}
+
+// Player builds the command line used to launch an external media player.
+type Player interface {
+	// Name identifies the player in status messages.
+	Name() string
+	// Command returns the executable and arguments that play path.
+	Command(path string, opts PlayOptions) (string, []string)
+}
+
+// positionTracker is implemented by players that report their playback
+// position over the IPC socket requested through PlayOptions.IPCSocket.
+type positionTracker interface {
+	QueryPosition(rw *bufio.ReadWriter) (time.Duration, error)
+}
+
+// volumeSetter is implemented by trackers that cannot take the start volume
+// on the command line and set it over the IPC socket instead.
+type volumeSetter interface {
+	SetVolume(rw *bufio.ReadWriter, percent int) error
+}
+
+// NewPlayer resolves a player specification. "vlc" and "mpv" select the
+// built-in backends; anything else is treated as a command template.
+func NewPlayer(spec string) (Player, error) {
+	trimmed := strings.TrimSpace(spec)
+	switch strings.ToLower(trimmed) {
+	case "", "vlc":
+		return vlcPlayer{}, nil
+	case "mpv":
+		return mpvPlayer{}, nil
+	}
+	return newTemplatePlayer(trimmed)
+}
+
+// vlcPlayer launches VLC. VLC has no portable start volume option (its
+// volume options belong to the individual audio outputs), so the volume is
+// set over the remote control interface once it is up.
+type vlcPlayer struct{}
+
+func (vlcPlayer) Name() string { return "VLC" }
+
+func (vlcPlayer) Command(path string, opts PlayOptions) (string, []string) {
+	args := []string{}
+	if opts.Crop != "" {
+		args = append(args, "--crop", opts.Crop)
+	}
+	if opts.Start > 0 {
+		args = append(args, "--start-time="+formatSeconds(opts.Start))
+	}
+	if opts.Fullscreen {
+		args = append(args, "--fullscreen")
+	}
+	if opts.IPCSocket != "" {
+		args = append(args, "--extraintf=rc", "--rc-unix="+opts.IPCSocket)
+	}
+	return "vlc", append(args, path)
+}
+
+// QueryPosition asks VLC's remote control interface for the elapsed time,
+// which it reports in whole seconds.
+func (vlcPlayer) QueryPosition(rw *bufio.ReadWriter) (time.Duration, error) {
+	if _, err := rw.WriteString("get_time\n"); err != nil {
+		return 0, err
+	}
+	if err := rw.Flush(); err != nil {
+		return 0, err
+	}
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		value := strings.TrimSpace(strings.TrimLeft(line, "> "))
+		if value == "" {
+			continue
+		}
+		seconds, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			// Skip the greeting and prompts the rc interface prints.
+			continue
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+}
+
+// SetVolume sends the volume to VLC's remote control interface, which counts
+// 256 as 100%.
+func (vlcPlayer) SetVolume(rw *bufio.ReadWriter, percent int) error {
+	if _, err := fmt.Fprintf(rw, "volume %d\n", percent*256/100); err != nil {
+		return err
+	}
+	return rw.Flush()
+}
+
+type mpvPlayer struct{}
+
+func (mpvPlayer) Name() string { return "mpv" }
+
+func (mpvPlayer) Command(path string, opts PlayOptions) (string, []string) {
+	args := []string{}
+	if opts.Crop != "" {
+		args = append(args, mpvCropArg(opts.Crop))
+	}
+	if opts.Start > 0 {
+		args = append(args, "--start="+formatSeconds(opts.Start))
+	}
+	if opts.Fullscreen {
+		args = append(args, "--fs")
+	}
+	if opts.Volume > 0 {
+		args = append(args, "--volume="+strconv.Itoa(opts.Volume))
+	}
+	if opts.IPCSocket != "" {
+		args = append(args, "--input-ipc-server="+opts.IPCSocket)
+	}
+	return "mpv", append(args, "--", path)
+}
+
+type mpvReply struct {
+	Event string   `json:"event"`
+	Error string   `json:"error"`
+	Data  *float64 `json:"data"`
+}
+
+// QueryPosition reads the time-pos property through mpv's JSON IPC.
+func (mpvPlayer) QueryPosition(rw *bufio.ReadWriter) (time.Duration, error) {
+	if _, err := rw.WriteString(`{"command": ["get_property", "time-pos"]}` + "\n"); err != nil {
+		return 0, err
+	}
+	if err := rw.Flush(); err != nil {
+		return 0, err
+	}
+	for {
+		line, err := rw.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		var reply mpvReply
+		if err := json.Unmarshal([]byte(line), &reply); err != nil {
+			return 0, err
+		}
+		if reply.Event != "" {
+			continue
+		}
+		if reply.Error != "success" {
+			return 0, fmt.Errorf("mpv: %s", reply.Error)
+		}
+		if reply.Data == nil {
+			return 0, errors.New("mpv: missing time-pos")
+		}
+		return time.Duration(*reply.Data * float64(time.Second)), nil
+	}
+}
+
+// mpvCropArg converts a VLC-style aspect crop (e.g. 5:4) into an mpv video
+// filter. Other values are passed through as mpv crop geometry.
+func mpvCropArg(crop string) string {
+	w, h, ok := parseAspect(crop)
+	if !ok {
+		return "--video-crop=" + crop
+	}
+	return fmt.Sprintf("--vf-add=lavfi-crop=w=ih*%d/%d:h=ih", w, h)
+}
+
+func parseAspect(value string) (int, int, bool) {
+	left, right, found := strings.Cut(value, ":")
+	if !found {
+		return 0, 0, false
+	}
+	w, err := strconv.Atoi(strings.TrimSpace(left))
+	if err != nil || w <= 0 {
+		return 0, 0, false
+	}
+	h, err := strconv.Atoi(strings.TrimSpace(right))
+	if err != nil || h <= 0 {
+		return 0, 0, false
+	}
+	return w, h, true
+}
+
+// templatePlayer runs an arbitrary command. The placeholders {path}, {start},
+// {crop} and {volume} are substituted per launch; arguments whose
+// placeholders expand to nothing are dropped. {fullscreen} expands to
+// nothing but keeps its argument only in fullscreen, as in "-fs{fullscreen}".
+// Without {path} the video path is appended.
+type templatePlayer struct {
+	binary string
+	args   []string
+}
+
+func newTemplatePlayer(template string) (Player, error) {
+	fields, err := splitCommandLine(template)
+	if err != nil {
+		return nil, fmt.Errorf("invalid player command %q: %w", template, err)
+	}
+	if len(fields) == 0 {
+		return nil, errors.New("empty player command")
+	}
+	return templatePlayer{binary: fields[0], args: fields[1:]}, nil
+}
+
+func (p templatePlayer) Name() string { return p.binary }
+
+func (p templatePlayer) Command(path string, opts PlayOptions) (string, []string) {
+	values := map[string]string{
+		"{path}":   path,
+		"{start}":  "",
+		"{crop}":   opts.Crop,
+		"{volume}": "",
+	}
+	if opts.Start > 0 {
+		values["{start}"] = formatSeconds(opts.Start)
+	}
+	if opts.Volume > 0 {
+		values["{volume}"] = strconv.Itoa(opts.Volume)
+	}
+	args := make([]string, 0, len(p.args)+1)
+	hasPath := false
+	for _, arg := range p.args {
+		if strings.Contains(arg, "{path}") {
+			hasPath = true
+		}
+		if strings.Contains(arg, "{fullscreen}") {
+			if !opts.Fullscreen {
+				continue
+			}
+			arg = strings.ReplaceAll(arg, "{fullscreen}", "")
+		}
+		expanded, keep := expandTemplateArg(arg, values)
+		if keep {
+			args = append(args, expanded)
+		}
+	}
+	if !hasPath {
+		args = append(args, path)
+	}
+	return p.binary, args
+}
+
+// expandTemplateArg substitutes the placeholders of arg in a single pass, so
+// placeholder text inside a substituted value (a file named
+// "Flow {crop}.mp4") is never expanded again. An argument using a
+// placeholder without a value is dropped.
+func expandTemplateArg(arg string, values map[string]string) (string, bool) {
+	pairs := make([]string, 0, 2*len(values))
+	for placeholder, value := range values {
+		if !strings.Contains(arg, placeholder) {
+			continue
+		}
+		if value == "" {
+			return "", false
+		}
+		pairs = append(pairs, placeholder, value)
+	}
+	return strings.NewReplacer(pairs...).Replace(arg), true
+}
+
+// splitCommandLine splits a command template into fields, honouring single
+// and double quotes.
+func splitCommandLine(value string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	inField := false
+	var quote rune
+	for _, r := range value {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case r == '\'' || r == '"':
+			quote = r
+			inField = true
+		case r == ' ' || r == '\t':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(r)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, errors.New("unterminated quote")
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewPlayerSelectsBackend(t *testing.T) {
+	cases := map[string]string{"": "VLC", "vlc": "VLC", "MPV": "mpv", "celluloid {path}": "celluloid"}
+	for spec, want := range cases {
+		p, err := NewPlayer(spec)
+		if err != nil {
+			t.Fatalf("NewPlayer(%q): %v", spec, err)
+		}
+		if p.Name() != want {
+			t.Fatalf("NewPlayer(%q) name = %s, want %s", spec, p.Name(), want)
+		}
+	}
+	if _, err := NewPlayer(`mplayer "unterminated`); err == nil {
+		t.Fatal("expected error for unterminated quote")
+	}
+}
+
+func TestVLCPlayerCommand(t *testing.T) {
+	opts := PlayOptions{Crop: "5:4", Start: 90 * time.Second, Fullscreen: true, Volume: 50}
+	name, args := vlcPlayer{}.Command("clip.mp4", opts)
+	want := []string{"--crop", "5:4", "--start-time=90", "--fullscreen", "clip.mp4"}
+	if name != "vlc" || !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected command %s %v", name, args)
+	}
+	_, args = vlcPlayer{}.Command("clip.mp4", PlayOptions{})
+	if !reflect.DeepEqual(args, []string{"clip.mp4"}) {
+		t.Fatalf("expected only path without options, got %v", args)
+	}
+}
+
+func TestMPVPlayerCommand(t *testing.T) {
+	opts := PlayOptions{Crop: "5:4", Start: 1500 * time.Millisecond, Fullscreen: true, Volume: 80}
+	name, args := mpvPlayer{}.Command("clip.mp4", opts)
+	want := []string{"--vf-add=lavfi-crop=w=ih*5/4:h=ih", "--start=1.5", "--fs", "--volume=80", "--", "clip.mp4"}
+	if name != "mpv" || !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected command %s %v", name, args)
+	}
+	_, args = mpvPlayer{}.Command("clip.mp4", PlayOptions{Crop: "640x480+0+0"})
+	if args[0] != "--video-crop=640x480+0+0" {
+		t.Fatalf("expected geometry passthrough, got %v", args)
+	}
+}
+
+func TestTemplatePlayerCommand(t *testing.T) {
+	p, err := NewPlayer(`celluloid --mpv-options="--start={start}" --volume {volume} {path}`)
+	if err != nil {
+		t.Fatalf("NewPlayer: %v", err)
+	}
+	name, args := p.Command("my clip.mp4", PlayOptions{Start: time.Minute})
+	want := []string{"--mpv-options=--start=60", "--volume", "my clip.mp4"}
+	if name != "celluloid" || !reflect.DeepEqual(args, want) {
+		t.Fatalf("unexpected command %s %v", name, args)
+	}
+	p, _ = NewPlayer("mplayer -fs{fullscreen} {path}")
+	if _, args = p.Command("clip.mp4", PlayOptions{Fullscreen: true}); !reflect.DeepEqual(args, []string{"-fs", "clip.mp4"}) {
+		t.Fatalf("expected the fullscreen flag, got %v", args)
+	}
+	if _, args = p.Command("clip.mp4", PlayOptions{}); !reflect.DeepEqual(args, []string{"clip.mp4"}) {
+		t.Fatalf("expected the fullscreen flag dropped, got %v", args)
+	}
+	p, _ = NewPlayer("totem")
+	_, args = p.Command("clip.mp4", PlayOptions{})
+	if !reflect.DeepEqual(args, []string{"clip.mp4"}) {
+		t.Fatalf("expected path appended, got %v", args)
+	}
+}
+
+func TestTemplatePlayerKeepsPlaceholdersInPath(t *testing.T) {
+	p, err := NewPlayer("vlc --crop={crop} {path}")
+	if err != nil {
+		t.Fatalf("NewPlayer: %v", err)
+	}
+	// Map iteration is random; repeat so a second expansion pass would show.
+	for range 20 {
+		_, args := p.Command("Flow {crop} {volume}.mp4", PlayOptions{Volume: 50})
+		if !reflect.DeepEqual(args, []string{"Flow {crop} {volume}.mp4"}) {
+			t.Fatalf("expected the path untouched, got %v", args)
+		}
+	}
+}
+
+func TestNewModelRejectsInvalidPlayer(t *testing.T) {
+	if _, err := newModel(Options{Root: t.TempDir(), Player: "'"}); err == nil {
+		t.Fatal("expected error for invalid player template")
+	}
+}
+
+func TestPlaySelectionUsesConfiguredPlayer(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir(), Player: "mpv"})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.filtered = []video{{Name: "clip", Path: "clip.mp4"}}
+	modelAny, _ := m.playSelection()
+	if status := modelAny.(model).statusMessage; !strings.Contains(status, "mpv") {
+		t.Fatalf("expected mpv in status, got %s", status)
+	}
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/playlist"
+)
+
+func savePlaylistCmd(dir string, p playlist.Playlist) tea.Cmd {
+	return func() tea.Msg {
+		return playlistSavedMsg{name: p.Name, err: playlist.Save(dir, p)}
+	}
+}
+
+func listPlaylistsCmd(dir string) tea.Cmd {
+	return func() tea.Msg {
+		names, err := playlist.List(dir)
+		return playlistsListedMsg{names: names, err: err}
+	}
+}
+
+func loadPlaylistCmd(dir, name string) tea.Cmd {
+	return func() tea.Msg {
+		p, err := playlist.Load(dir, name)
+		return playlistLoadedMsg{playlist: p, err: err}
+	}
+}
+
+// exportPlaylistCmd writes the entries as <name>.m3u and <name>.xspf into dir.
+func exportPlaylistCmd(dir, name string, entries []playlist.Entry) tea.Cmd {
+	return func() tea.Msg {
+		if err := playlist.ValidateName(name); err != nil {
+			return playlistExportedMsg{err: err}
+		}
+		m3uPath := filepath.Join(dir, name+".m3u")
+		xspfPath := filepath.Join(dir, name+".xspf")
+		if err := writeFileWith(m3uPath, func(f *os.File) error { return playlist.WriteM3U(f, entries) }); err != nil {
+			return playlistExportedMsg{err: err}
+		}
+		if err := writeFileWith(xspfPath, func(f *os.File) error { return playlist.WriteXSPF(f, name, entries) }); err != nil {
+			return playlistExportedMsg{err: err}
+		}
+		return playlistExportedMsg{paths: []string{m3uPath, xspfPath}}
+	}
+}
+
+func writeFileWith(path string, write func(*os.File) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+)
+
+type positionEntry struct {
+	PositionSeconds float64 `json:"position_seconds"`
+	UpdatedUnix     int64   `json:"updated_unix"`
+}
+
+// positionStore remembers where playback of each video stopped so it can be
+// resumed later. It is persisted next to the duration cache.
+type positionStore struct {
+	path    string
+	entries map[string]positionEntry
+	mu      sync.Mutex
+}
+
+func newPositionStore(path string) *positionStore {
+	return &positionStore{path: path, entries: make(map[string]positionEntry)}
+}
+
+func loadPositionStore(path string) (*positionStore, error) {
+	store := newPositionStore(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		return store, err
+	}
+	if len(data) == 0 {
+		return store, nil
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		store.entries = make(map[string]positionEntry)
+		return store, err
+	}
+	return store, nil
+}
+
+func (s *positionStore) Lookup(path string) (time.Duration, bool) {
+	if s == nil {
+		return 0, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[path]
+	if !ok || entry.PositionSeconds <= 0 {
+		return 0, false
+	}
+	return time.Duration(entry.PositionSeconds * float64(time.Second)), true
+}
+
+func (s *positionStore) Record(path string, pos time.Duration) {
+	if s == nil || pos <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[path] = positionEntry{PositionSeconds: pos.Seconds(), UpdatedUnix: time.Now().Unix()}
+}
+
+// RecordStop stores where playback stopped, or forgets the position when it
+// stopped within resumeTailThreshold of the end of a video of the given
+// duration. It reports whether the video counts as finished.
+func (s *positionStore) RecordStop(path string, pos, duration time.Duration) bool {
+	if duration > 0 && pos >= duration-resumeTailThreshold {
+		s.Clear(path)
+		return true
+	}
+	s.Record(path, pos)
+	return false
+}
+
+func (s *positionStore) Clear(path string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, path)
+}
+
+func (s *positionStore) Flush() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	snapshot := make(map[string]positionEntry, len(s.entries))
+	for k, v := range s.entries {
+		snapshot[k] = v
+	}
+	s.mu.Unlock()
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
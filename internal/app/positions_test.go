@@ -0,0 +1,80 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPositionStoreLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "positions.json")
+	store, err := loadPositionStore(path)
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	store.Record("a.mp4", 90*time.Second)
+	store.Record("b.mp4", 0)
+	if err := store.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	reloaded, err := loadPositionStore(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if pos, ok := reloaded.Lookup("a.mp4"); !ok || pos != 90*time.Second {
+		t.Fatalf("expected stored position, got %v %v", pos, ok)
+	}
+	if _, ok := reloaded.Lookup("b.mp4"); ok {
+		t.Fatal("expected zero position to be ignored")
+	}
+	reloaded.Clear("a.mp4")
+	if _, ok := reloaded.Lookup("a.mp4"); ok {
+		t.Fatal("expected position cleared")
+	}
+}
+
+func TestPositionStoreRecordStop(t *testing.T) {
+	store := newPositionStore(filepath.Join(t.TempDir(), "positions.json"))
+	if store.RecordStop("a.mp4", 5*time.Minute, 20*time.Minute) {
+		t.Fatal("expected a stop midway not to finish the video")
+	}
+	if pos, ok := store.Lookup("a.mp4"); !ok || pos != 5*time.Minute {
+		t.Fatalf("expected stored position, got %v %v", pos, ok)
+	}
+	if !store.RecordStop("a.mp4", 20*time.Minute-10*time.Second, 20*time.Minute) {
+		t.Fatal("expected a stop near the end to finish the video")
+	}
+	if _, ok := store.Lookup("a.mp4"); ok {
+		t.Fatal("expected the position cleared")
+	}
+	if store.RecordStop("b.mp4", time.Hour, 0) {
+		t.Fatal("expected an unknown duration never to finish the video")
+	}
+}
+
+func TestPositionStoreInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "positions.json")
+	if err := os.WriteFile(path, []byte("nope"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := loadPositionStore(path)
+	if err == nil {
+		t.Fatal("expected error for invalid json")
+	}
+	if len(store.entries) != 0 {
+		t.Fatal("expected entries reset")
+	}
+}
+
+func TestPositionStoreNilSafe(t *testing.T) {
+	var store *positionStore
+	store.Record("a.mp4", time.Second)
+	store.Clear("a.mp4")
+	if _, ok := store.Lookup("a.mp4"); ok {
+		t.Fatal("expected nil store lookup to miss")
+	}
+	if err := store.Flush(); err != nil {
+		t.Fatalf("flush nil store: %v", err)
+	}
+}
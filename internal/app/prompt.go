@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// promptState is a single-line input modal whose result is handed to submit.
+type promptState struct {
+	title  string
+	input  textinput.Model
+	submit func(m model, value string) (tea.Model, tea.Cmd)
+}
+
+// pickerState is a modal list; enter hands the highlighted item to choose.
+type pickerState struct {
+	title  string
+	items  []string
+	cursor int
+	choose func(m model, item string) (tea.Model, tea.Cmd)
+}
+
+func (m model) openPrompt(title, value string, submit func(model, string) (tea.Model, tea.Cmd)) model {
+	input := textinput.New()
+	input.Prompt = "> "
+	input.CharLimit = 256
+	input.SetValue(value)
+	input.CursorEnd()
+	input.Focus()
+	m.prompt = &promptState{title: title, input: input, submit: submit}
+	return m
+}
+
+func (m model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.prompt = nil
+		m.statusMessage = "Cancelled"
+		return m, nil
+	case "enter":
+		prompt := m.prompt
+		m.prompt = nil
+		return prompt.submit(m, strings.TrimSpace(prompt.input.Value()))
+	}
+	prompt := *m.prompt
+	var cmd tea.Cmd
+	prompt.input, cmd = prompt.input.Update(msg)
+	m.prompt = &prompt
+	return m, cmd
+}
+
+func (m model) renderPrompt() string {
+	var b strings.Builder
+	b.WriteString(m.prompt.title)
+	b.WriteString("\n\n")
+	b.WriteString(m.prompt.input.View())
+	b.WriteString("\n\n")
+	b.WriteString("Enter to confirm, Esc to cancel")
+	return filterStyle.Render(b.String())
+}
+
+func (m model) openPicker(title string, items []string, choose func(model, string) (tea.Model, tea.Cmd)) model {
+	m.picker = &pickerState{title: title, items: items, choose: choose}
+	return m
+}
+
+func (m model) handlePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	picker := *m.picker
+	switch msg.String() {
+	case "esc":
+		m.picker = nil
+		m.statusMessage = "Cancelled"
+		return m, nil
+	case "enter":
+		m.picker = nil
+		if len(picker.items) == 0 {
+			return m, nil
+		}
+		return picker.choose(m, picker.items[picker.cursor])
+	case "up", "k":
+		if picker.cursor > 0 {
+			picker.cursor--
+		}
+	case "down", "j":
+		if picker.cursor < len(picker.items)-1 {
+			picker.cursor++
+		}
+	}
+	m.picker = &picker
+	return m, nil
+}
+
+func (m model) renderPicker() string {
+	var b strings.Builder
+	b.WriteString(m.picker.title)
+	b.WriteString("\n\n")
+	if len(m.picker.items) == 0 {
+		b.WriteString("(empty)\n")
+	}
+	for i, item := range m.picker.items {
+		line := fmt.Sprintf("  %s", item)
+		if i == m.picker.cursor {
+			line = highlightStyle.Render(fmt.Sprintf("> %s", item))
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	b.WriteString("\n↑/↓ select, Enter to choose, Esc to cancel")
+	return filterStyle.Render(b.String())
+}
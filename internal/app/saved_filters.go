@@ -0,0 +1,116 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// savedFilterStore keeps named searches ("smart collections") in the
+// library root. Queries are stored in the search bar syntax so the file
+// stays readable and editable by hand.
+type savedFilterStore struct {
+	path    string
+	entries map[string]string
+	mu      sync.Mutex
+	// loadErr is set when the file exists but could not be loaded; Flush
+	// then refuses to overwrite it.
+	loadErr error
+}
+
+func newSavedFilterStore(path string) *savedFilterStore {
+	return &savedFilterStore{path: path, entries: make(map[string]string)}
+}
+
+func loadSavedFilterStore(path string) (*savedFilterStore, error) {
+	store := newSavedFilterStore(path)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return store, nil
+		}
+		store.loadErr = err
+		return store, err
+	}
+	if len(data) == 0 {
+		return store, nil
+	}
+	if err := json.Unmarshal(data, &store.entries); err != nil {
+		store.entries = make(map[string]string)
+		store.loadErr = fmt.Errorf("parse %s: %w", path, err)
+		return store, store.loadErr
+	}
+	return store, nil
+}
+
+// Names returns the saved filter names in alphabetical order.
+func (s *savedFilterStore) Names() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.entries))
+	for name := range s.entries {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return strings.ToLower(names[i]) < strings.ToLower(names[j]) })
+	return names
+}
+
+func (s *savedFilterStore) Lookup(name string) (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query, ok := s.entries[name]
+	return query, ok
+}
+
+func (s *savedFilterStore) Save(name, query string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[name] = query
+}
+
+func (s *savedFilterStore) Flush() error {
+	if s == nil {
+		return nil
+	}
+	if s.loadErr != nil {
+		return fmt.Errorf("not overwriting a file that failed to load: %w", s.loadErr)
+	}
+	s.mu.Lock()
+	snapshot := make(map[string]string, len(s.entries))
+	for k, v := range s.entries {
+		snapshot[k] = v
+	}
+	s.mu.Unlock()
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.path, data, 0o644)
+}
+
+// lookupSavedFilter parses the named saved filter.
+func (s *savedFilterStore) lookupSavedFilter(name string) (filterState, error) {
+	query, ok := s.Lookup(name)
+	if !ok {
+		return filterState{}, fmt.Errorf("unknown saved filter %q", name)
+	}
+	filters, err := parseSearchQuery(query)
+	if err != nil {
+		return filterState{}, fmt.Errorf("saved filter %q: %w", name, err)
+	}
+	return filters, nil
+}
@@ -0,0 +1,145 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+)
+
+func TestSavedFilterStoreLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filters.json")
+	store, err := loadSavedFilterStore(path)
+	if err != nil {
+		t.Fatalf("load store: %v", err)
+	}
+	store.Save("short mornings", "tag:morning len:..20")
+	store.Save("Backbends", "tag:back")
+	if err := store.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	reloaded, err := loadSavedFilterStore(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if names := reloaded.Names(); len(names) != 2 || names[0] != "Backbends" {
+		t.Fatalf("unexpected names %v", names)
+	}
+	filters, err := reloaded.lookupSavedFilter("short mornings")
+	if err != nil || filters.tags != "morning" || filters.maxMinutes != 20 {
+		t.Fatalf("unexpected filters %+v (%v)", filters, err)
+	}
+	if _, err := reloaded.lookupSavedFilter("missing"); err == nil {
+		t.Fatal("expected unknown filter error")
+	}
+}
+
+func TestLoadSavedFilterStoreInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filters.json")
+	if err := os.WriteFile(path, []byte("{"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	store, err := loadSavedFilterStore(path)
+	if err == nil || len(store.Names()) != 0 {
+		t.Fatal("expected parse error and empty store")
+	}
+}
+
+func TestSaveAndPickCollectionStaysLive(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{
+		{Name: "sun.mp4", Path: "sun.mp4", Tags: []string{"morning"}, Duration: 15 * time.Minute},
+		{Name: "moon.mp4", Path: "moon.mp4", Tags: []string{"morning"}},
+	}
+	m.filters, _ = parseSearchQuery("tag:morning len:..20")
+	m.applyFiltersAndSort()
+	modelAny, _ := m.handleKeyMsg(keyMsg("W"))
+	m = modelAny.(model)
+	m.prompt.input.SetValue("short mornings")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.collection != "short mornings" {
+		t.Fatalf("expected saved collection, status %q", m.statusMessage)
+	}
+
+	m.resetFilters()
+	m.applyFiltersAndSort()
+	modelAny, _ = m.handleKeyMsg(keyMsg("F"))
+	m = modelAny.(model)
+	if m.picker == nil || len(m.picker.items) != 1 {
+		t.Fatal("expected saved filter picker")
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.collection != "short mornings" || len(m.filtered) != 1 {
+		t.Fatalf("expected collection applied, got %d videos", len(m.filtered))
+	}
+
+	modelAny, _ = m.handleDurationUpdate(durationUpdateMsg{path: "moon.mp4", media: probe.Info{Duration: 10 * time.Minute}})
+	m = modelAny.(model)
+	if len(m.filtered) != 2 {
+		t.Fatalf("expected collection to pick up the probed video, got %d", len(m.filtered))
+	}
+}
+
+func TestNewModelAppliesStartupFilter(t *testing.T) {
+	root := t.TempDir()
+	store := newSavedFilterStore(filepath.Join(root, ".video_filters.json"))
+	store.Save("hips", "tag:hips")
+	if err := store.Flush(); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+	m, err := newModel(Options{Root: root, Filter: "hips"})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	if m.filters.tags != "hips" || m.inputs.fields[3].Value() != "hips" {
+		t.Fatalf("expected start-up filter applied, got %+v", m.filters)
+	}
+	if _, err := newModel(Options{Root: root, Filter: "missing"}); err == nil {
+		t.Fatal("expected unknown filter to fail")
+	}
+}
+
+func TestBrokenSavedFilterFileIsReportedAndKept(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, ".video_filters.json")
+	if err := os.WriteFile(path, []byte(`{"hips": "tag:hips",`), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("expected a broken saved filter file not to stop the start: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "sun.mp4", Path: "sun.mp4", Tags: []string{"morning"}}}
+	m.applyFiltersAndSort()
+	m.updateStatusAfterLoad(videosLoadedMsg{})
+	if !strings.Contains(m.statusText(), "saved filter warning") {
+		t.Fatalf("expected the parse error in the status line, got %q", m.statusText())
+	}
+
+	m.filters, _ = parseSearchQuery("tag:morning")
+	m.applyFiltersAndSort()
+	modelAny, _ := m.handleKeyMsg(keyMsg("W"))
+	m = modelAny.(model)
+	m.prompt.input.SetValue("mornings")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if !strings.Contains(m.statusMessage, "Saving filter failed") || m.collection != "" {
+		t.Fatalf("expected saving to be refused, status %q", m.statusMessage)
+	}
+	if data, _ := os.ReadFile(path); string(data) != `{"hips": "tag:hips",` {
+		t.Fatalf("expected the broken file to be kept, got %q", data)
+	}
+}
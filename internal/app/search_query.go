@@ -0,0 +1,484 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// searchSyntax is shown as a hint in the search bar.
+const searchSyntax = "name:flow tag:hips len:20..45 age:<30d size:>1G played:never"
+
+const day = 24 * time.Hour
+
+// parseSearchQuery translates a one-line search such as
+// "name:flow tag:hips len:20..45 age:<30d size:>1G played:never" into a
+// filterState. Words without a key are matched against the name (quote them
+// when they contain a colon); values may be quoted, with \" and \\ escaping
+// a quote and a backslash inside the quotes, and tag values may be a
+// parenthesised tag query.
+func parseSearchQuery(query string) (filterState, error) {
+	words, err := splitSearchQuery(query)
+	if err != nil {
+		return filterState{}, err
+	}
+	var filters filterState
+	var names, tagQueries []string
+	for _, word := range words {
+		key, value, ok := strings.Cut(word, ":")
+		if !ok || strings.HasPrefix(word, `"`) {
+			names = append(names, unquote(word))
+			continue
+		}
+		if value == "" {
+			return filterState{}, fmt.Errorf("missing value for %s:", key)
+		}
+		switch strings.ToLower(key) {
+		case "name":
+			names = append(names, unquote(value))
+		case "tag", "tags":
+			tagQueries = append(tagQueries, stripOuterParens(value))
+		case "len", "length":
+			err = parseLengthTerm(&filters, value)
+		case "age":
+			err = parseAgeTerm(&filters, value)
+		case "size":
+			err = parseSizeTerm(&filters, value)
+		case "played":
+			err = parsePlayedTerm(&filters, value)
+		case "height", "res":
+			err = populateMinHeightFilter(&filters, strings.TrimLeft(value, ">="))
+		case "audio":
+			filters.audioLanguage = strings.ToLower(unquote(value))
+		case "source":
+			filters.source = unquote(value)
+		case "instructor", "teacher":
+			filters.details.instructor = unquote(value)
+		case "style":
+			filters.details.style = unquote(value)
+		case "focus":
+			filters.details.focus = unquote(value)
+		case "props":
+			filters.details.props = unquote(value)
+		case "notes":
+			filters.details.notes = unquote(value)
+		case "difficulty", "level":
+			filters.details.difficulty, err = parseDifficulty(value)
+		case "intensity":
+			filters.details.intensity, err = parseLevelRange("intensity", value)
+		case "rating":
+			filters.details.rating, err = parseLevelRange("rating", value)
+		default:
+			err = fmt.Errorf("unknown search key %q", key)
+		}
+		if err != nil {
+			return filterState{}, err
+		}
+	}
+	filters.name = strings.Join(names, " ")
+	if len(tagQueries) == 1 {
+		filters.tags = tagQueries[0]
+	} else if len(tagQueries) > 1 {
+		filters.tags = "(" + strings.Join(tagQueries, ") AND (") + ")"
+	}
+	if err := populateTagFilter(&filters, filters.tags); err != nil {
+		return filterState{}, err
+	}
+	if filters.minEnabled && filters.maxEnabled && filters.minMinutes > filters.maxMinutes {
+		return filterState{}, errors.New("min minutes cannot exceed max minutes")
+	}
+	return filters, nil
+}
+
+// splitSearchQuery splits on whitespace outside quotes and parentheses.
+// Escapes are kept in the words for unquote to resolve.
+func splitSearchQuery(query string) ([]string, error) {
+	var words []string
+	var current strings.Builder
+	depth, quoted, escaped := 0, false, false
+	for _, r := range query {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth < 0 {
+				return nil, errors.New("unexpected ) in search")
+			}
+		case unicode.IsSpace(r) && depth == 0:
+			if current.Len() > 0 {
+				words = append(words, current.String())
+				current.Reset()
+			}
+			continue
+		}
+		current.WriteRune(r)
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote in search")
+	}
+	if depth > 0 {
+		return nil, errors.New("missing ) in search")
+	}
+	if current.Len() > 0 {
+		words = append(words, current.String())
+	}
+	return words, nil
+}
+
+// stripOuterParens removes one pair of parentheses enclosing all of value,
+// so that tag:(hips OR back) stores "hips OR back".
+func stripOuterParens(value string) string {
+	if !strings.HasPrefix(value, "(") || !strings.HasSuffix(value, ")") {
+		return value
+	}
+	depth, quoted, escaped := 0, false, false
+	for i, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && r == '\\':
+			escaped = true
+		case r == '"':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 && i < len(value)-1 {
+				return value
+			}
+		}
+	}
+	return value[1 : len(value)-1]
+}
+
+// searchEscaper and searchUnescaper convert quotes and backslashes inside a
+// quoted search value.
+var (
+	searchEscaper   = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	searchUnescaper = strings.NewReplacer(`\\`, `\`, `\"`, `"`)
+)
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return searchUnescaper.Replace(value[1 : len(value)-1])
+	}
+	return value
+}
+
+// splitComparison separates a leading <, <=, >, >= or = from value.
+func splitComparison(value string) (string, string) {
+	for _, op := range []string{"<=", ">=", "<", ">", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "", value
+}
+
+// parseLengthTerm accepts minutes as 20..45, 20.., ..45, >20, <=45 or 30.
+// A maximum must be positive.
+func parseLengthTerm(dst *filterState, value string) error {
+	if lo, hi, ok := strings.Cut(value, ".."); ok {
+		if lo != "" {
+			if err := populateMinFilter(dst, lo); err != nil {
+				return err
+			}
+		}
+		if hi != "" {
+			if err := populateMaxFilter(dst, hi); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	op, number := splitComparison(value)
+	minutes, err := strconv.Atoi(number)
+	if err != nil || minutes < 0 {
+		return fmt.Errorf("invalid length: %q", value)
+	}
+	switch op {
+	case ">":
+		minutes++
+		fallthrough
+	case ">=":
+		dst.minEnabled, dst.minMinutes = true, minutes
+	case "<":
+		minutes--
+		fallthrough
+	case "<=":
+		if minutes <= 0 {
+			return fmt.Errorf("invalid length: %q", value)
+		}
+		dst.maxEnabled, dst.maxMinutes = true, minutes
+	default:
+		if minutes <= 0 {
+			return fmt.Errorf("invalid length: %q", value)
+		}
+		dst.minEnabled, dst.minMinutes = true, minutes
+		dst.maxEnabled, dst.maxMinutes = true, minutes
+	}
+	return nil
+}
+
+// difficultyNames are shorthands for difficulty ranges.
+var difficultyNames = map[string]intRange{
+	"beginner":     {min: 1, max: 2},
+	"intermediate": {min: 3, max: 3},
+	"advanced":     {min: 4, max: 5},
+}
+
+func parseDifficulty(value string) (intRange, error) {
+	if r, ok := difficultyNames[strings.ToLower(value)]; ok {
+		return r, nil
+	}
+	return parseLevelRange("difficulty", value)
+}
+
+// parseLevelRange accepts a level on the 1-5 scale as 3, >=3, <4 or 2..4.
+func parseLevelRange(name, value string) (intRange, error) {
+	invalid := fmt.Errorf("invalid %s: %q (use 1-%d, >=3 or 2..4)", name, value, tags.MaxLevel)
+	level := func(text string) (int, bool) {
+		n, err := strconv.Atoi(text)
+		return n, err == nil && n >= 1 && n <= tags.MaxLevel
+	}
+	if lo, hi, ok := strings.Cut(value, ".."); ok {
+		var r intRange
+		if lo != "" {
+			if r.min, ok = level(lo); !ok {
+				return intRange{}, invalid
+			}
+		}
+		if hi != "" {
+			if r.max, ok = level(hi); !ok {
+				return intRange{}, invalid
+			}
+		}
+		if !r.active() || (r.max > 0 && r.min > r.max) {
+			return intRange{}, invalid
+		}
+		return r, nil
+	}
+	op, text := splitComparison(value)
+	n, ok := level(text)
+	if !ok {
+		return intRange{}, invalid
+	}
+	switch op {
+	case ">":
+		return intRange{min: n + 1}, nil
+	case ">=":
+		return intRange{min: n}, nil
+	case "<":
+		if n == 1 {
+			return intRange{}, invalid
+		}
+		return intRange{max: n - 1}, nil
+	case "<=":
+		return intRange{max: n}, nil
+	}
+	return intRange{min: n, max: n}, nil
+}
+
+// parseAgeTerm accepts <30d (modified within 30 days) or >1y. Without an
+// operator the age is an upper bound.
+func parseAgeTerm(dst *filterState, value string) error {
+	op, text := splitComparison(value)
+	age, err := parseSpan(text)
+	if err != nil {
+		return fmt.Errorf("invalid age: %q", value)
+	}
+	if strings.HasPrefix(op, ">") {
+		dst.minAge = age
+	} else {
+		dst.maxAge = age
+	}
+	return nil
+}
+
+// parsePlayedTerm accepts never, >30d (not played within 30 days) and <7d
+// (played within the last 7 days).
+func parsePlayedTerm(dst *filterState, value string) error {
+	if strings.EqualFold(value, "never") {
+		dst.neverPlayed = true
+		return nil
+	}
+	op, text := splitComparison(value)
+	span, err := parseSpan(text)
+	if err != nil || op == "" || op == "=" {
+		return fmt.Errorf("invalid played: %q (use never, >30d or <7d)", value)
+	}
+	days := int((span + day - 1) / day)
+	if strings.HasPrefix(op, ">") {
+		dst.notPlayedEnabled, dst.notPlayedDays = true, days
+	} else {
+		dst.playedWithinDays = days
+	}
+	return nil
+}
+
+// parseSpan reads a number followed by h, d, w, m (30 days) or y; days are
+// the default unit.
+func parseSpan(text string) (time.Duration, error) {
+	units := map[byte]time.Duration{'h': time.Hour, 'd': day, 'w': 7 * day, 'm': 30 * day, 'y': 365 * day}
+	unit := day
+	if n := len(text); n > 0 {
+		if u, ok := units[text[n-1]|0x20]; ok {
+			unit = u
+			text = text[:n-1]
+		}
+	}
+	n, err := strconv.Atoi(text)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid span %q", text)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// parseSizeTerm accepts >1G or <500M; bounds are inclusive and units are
+// binary (K, M, G, T with an optional B).
+func parseSizeTerm(dst *filterState, value string) error {
+	op, text := splitComparison(value)
+	size, err := parseSize(text)
+	if err != nil {
+		return fmt.Errorf("invalid size: %q", value)
+	}
+	if strings.HasPrefix(op, "<") {
+		dst.maxSize = size
+	} else {
+		dst.minSize = size
+	}
+	return nil
+}
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{{"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10}}
+
+func parseSize(text string) (int64, error) {
+	text = strings.TrimSuffix(strings.ToUpper(text), "B")
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(text, unit.suffix) {
+			multiplier = unit.bytes
+			text = strings.TrimSuffix(text, unit.suffix)
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(text, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("invalid size %q", text)
+	}
+	return int64(n * float64(multiplier)), nil
+}
+
+// formatSearchQuery renders filters in the search syntax so that the bar
+// can be pre-filled and filters can be stored as text.
+func formatSearchQuery(f filterState) string {
+	var parts []string
+	if f.name != "" {
+		parts = append(parts, "name:"+quoteSearchValue(f.name))
+	}
+	if f.tags != "" {
+		if strings.ContainsAny(f.tags, " \"()") {
+			parts = append(parts, "tag:("+f.tags+")")
+		} else {
+			parts = append(parts, "tag:"+f.tags)
+		}
+	}
+	switch {
+	case f.minEnabled && f.maxEnabled && f.minMinutes == f.maxMinutes:
+		parts = append(parts, fmt.Sprintf("len:%d", f.minMinutes))
+	case f.minEnabled && f.maxEnabled:
+		parts = append(parts, fmt.Sprintf("len:%d..%d", f.minMinutes, f.maxMinutes))
+	case f.minEnabled:
+		parts = append(parts, fmt.Sprintf("len:>=%d", f.minMinutes))
+	case f.maxEnabled:
+		parts = append(parts, fmt.Sprintf("len:<=%d", f.maxMinutes))
+	}
+	if f.maxAge > 0 {
+		parts = append(parts, "age:<"+formatSpan(f.maxAge))
+	}
+	if f.minAge > 0 {
+		parts = append(parts, "age:>"+formatSpan(f.minAge))
+	}
+	if f.minSize > 0 {
+		parts = append(parts, "size:>="+formatSize(f.minSize))
+	}
+	if f.maxSize > 0 {
+		parts = append(parts, "size:<="+formatSize(f.maxSize))
+	}
+	if f.neverPlayed {
+		parts = append(parts, "played:never")
+	}
+	if f.notPlayedEnabled {
+		parts = append(parts, fmt.Sprintf("played:>%dd", f.notPlayedDays))
+	}
+	if f.playedWithinDays > 0 {
+		parts = append(parts, fmt.Sprintf("played:<%dd", f.playedWithinDays))
+	}
+	if f.minHeight > 0 {
+		parts = append(parts, fmt.Sprintf("height:%dp", f.minHeight))
+	}
+	if f.audioLanguage != "" {
+		parts = append(parts, "audio:"+f.audioLanguage)
+	}
+	if f.source != "" {
+		parts = append(parts, "source:"+quoteSearchValue(f.source))
+	}
+	d := f.details
+	for _, text := range []struct{ key, value string }{
+		{"instructor", d.instructor}, {"style", d.style}, {"focus", d.focus}, {"props", d.props}, {"notes", d.notes},
+	} {
+		if text.value != "" {
+			parts = append(parts, text.key+":"+quoteSearchValue(text.value))
+		}
+	}
+	for _, level := range []struct {
+		key   string
+		value intRange
+	}{{"difficulty", d.difficulty}, {"intensity", d.intensity}, {"rating", d.rating}} {
+		if level.value.active() {
+			parts = append(parts, level.key+":"+level.value.String())
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func quoteSearchValue(value string) string {
+	if strings.ContainsAny(value, ` ()"`) {
+		return `"` + searchEscaper.Replace(value) + `"`
+	}
+	return value
+}
+
+func formatSpan(d time.Duration) string {
+	if d%day == 0 {
+		return fmt.Sprintf("%dd", d/day)
+	}
+	return fmt.Sprintf("%dh", d/time.Hour)
+}
+
+func formatSize(n int64) string {
+	for _, unit := range sizeUnits {
+		if n%unit.bytes == 0 {
+			return fmt.Sprintf("%d%s", n/unit.bytes, unit.suffix)
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
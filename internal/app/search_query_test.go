@@ -0,0 +1,188 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	f, err := parseSearchQuery(`name:flow tag:hips len:20..45 age:<30d size:>1G played:never morning`)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if f.name != "flow morning" || f.tags != "hips" || f.tagQuery == nil {
+		t.Fatalf("unexpected name/tags %+v", f)
+	}
+	if !f.minEnabled || f.minMinutes != 20 || !f.maxEnabled || f.maxMinutes != 45 {
+		t.Fatalf("unexpected length %+v", f)
+	}
+	if f.maxAge != 30*day || f.minSize != 1<<30 || !f.neverPlayed {
+		t.Fatalf("unexpected age/size/played %+v", f)
+	}
+}
+
+func TestParseSearchQueryVariants(t *testing.T) {
+	cases := map[string]func(filterState) bool{
+		"len:>20":                      func(f filterState) bool { return f.minEnabled && f.minMinutes == 21 && !f.maxEnabled },
+		"len:..30":                     func(f filterState) bool { return f.maxEnabled && f.maxMinutes == 30 && !f.minEnabled },
+		"len:30":                       func(f filterState) bool { return f.minMinutes == 30 && f.maxMinutes == 30 },
+		"age:>1y":                      func(f filterState) bool { return f.minAge == 365*day },
+		"size:<500M":                   func(f filterState) bool { return f.maxSize == 500<<20 },
+		"played:>2w":                   func(f filterState) bool { return f.notPlayedEnabled && f.notPlayedDays == 14 },
+		"played:<7d":                   func(f filterState) bool { return f.playedWithinDays == 7 },
+		"height:1080p audio:ENG":       func(f filterState) bool { return f.minHeight == 1080 && f.audioLanguage == "eng" },
+		`source:nas name:"slow flow"`:  func(f filterState) bool { return f.source == "nas" && f.name == "slow flow" },
+		`"Part 1: Flow"`:               func(f filterState) bool { return f.name == "Part 1: Flow" },
+		`tag:(hips OR back) tag:hatha`: func(f filterState) bool { return f.tags == "(hips OR back) AND (hatha)" },
+		`tag:(hips)OR(back)`:           func(f filterState) bool { return f.tags == "(hips)OR(back)" },
+		`tag:teacher:adriene`:          func(f filterState) bool { return f.tags == "teacher:adriene" },
+		"difficulty:beginner props:none": func(f filterState) bool {
+			return f.details.difficulty == intRange{min: 1, max: 2} && f.details.props == "none"
+		},
+		"level:2..3":   func(f filterState) bool { return f.details.difficulty == intRange{min: 2, max: 3} },
+		"rating:>=4":   func(f filterState) bool { return f.details.rating == intRange{min: 4} },
+		"intensity:<3": func(f filterState) bool { return f.details.intensity == intRange{max: 2} },
+		`instructor:"Ada B" style:yin focus:hips notes:knee`: func(f filterState) bool {
+			d := f.details
+			return d.instructor == "Ada B" && d.style == "yin" && d.focus == "hips" && d.notes == "knee"
+		},
+	}
+	for query, check := range cases {
+		f, err := parseSearchQuery(query)
+		if err != nil {
+			t.Fatalf("parse %q: %v", query, err)
+		}
+		if !check(f) {
+			t.Errorf("%q: unexpected filters %+v", query, f)
+		}
+	}
+}
+
+func TestParseSearchQueryErrors(t *testing.T) {
+	for _, query := range []string{"len:abc", "len:50..20", "len:<0", "len:<1", "len:<=0", "len:..0", "len:0", "age:<soon", "size:>big", "played:yesterday", "colour:red", "tag:", `name:"open`, "tag:(hips", "tag:(hips AND)", "difficulty:6", "rating:<1", "intensity:4..2", "level:hard"} {
+		if _, err := parseSearchQuery(query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+}
+
+func TestFormatSearchQueryRoundTrip(t *testing.T) {
+	query := `name:"slow flow" tag:(hips OR back) len:20..45 age:<30d size:>=1G played:never played:>7d height:720p audio:eng source:nas instructor:"Ada \"B\"" notes:"a\\b c" props:none difficulty:2..3 rating:>=4`
+	f, err := parseSearchQuery(query)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if f.details.instructor != `Ada "B"` || f.details.notes != `a\b c` {
+		t.Fatalf("expected escapes resolved, got %q and %q", f.details.instructor, f.details.notes)
+	}
+	formatted := formatSearchQuery(f)
+	again, err := parseSearchQuery(formatted)
+	if err != nil {
+		t.Fatalf("reparse %q: %v", formatted, err)
+	}
+	f.tagQuery, again.tagQuery = nil, nil
+	if !reflect.DeepEqual(f, again) {
+		t.Fatalf("round trip changed filters:\n%+v\n%+v (%s)", f, again, formatted)
+	}
+}
+
+func TestFormatSearchQueryRoundTripsLengths(t *testing.T) {
+	for _, query := range []string{"len:>0", "len:<2", "len:..1", "len:1", "len:0..5"} {
+		f, err := parseSearchQuery(query)
+		if err != nil {
+			t.Fatalf("parse %q: %v", query, err)
+		}
+		formatted := formatSearchQuery(f)
+		again, err := parseSearchQuery(formatted)
+		if err != nil {
+			t.Fatalf("reparse %q from %q: %v", formatted, query, err)
+		}
+		if again.minEnabled != f.minEnabled || again.minMinutes != f.minMinutes || again.maxEnabled != f.maxEnabled || again.maxMinutes != f.maxMinutes {
+			t.Fatalf("round trip of %q changed lengths: %+v vs %+v (%s)", query, f, again, formatted)
+		}
+	}
+}
+
+func TestPassesSearchOnlyFilters(t *testing.T) {
+	now := time.Now()
+	fresh := video{Name: "fresh", ModTime: now.Add(-2 * day), Size: 2 << 30}
+	old := video{Name: "old", ModTime: now.Add(-90 * day), Size: 100 << 20, PlayCount: 1, LastPlayed: now.Add(-3 * day)}
+	m := model{filters: filterState{maxAge: 30 * day}}
+	if !m.passesFilters(fresh) || m.passesFilters(old) {
+		t.Fatal("expected age filter to keep only fresh video")
+	}
+	m.filters = filterState{minSize: 1 << 30}
+	if !m.passesFilters(fresh) || m.passesFilters(old) {
+		t.Fatal("expected size filter to keep only large video")
+	}
+	m.filters = filterState{neverPlayed: true}
+	if !m.passesFilters(fresh) || m.passesFilters(old) {
+		t.Fatal("expected never played filter")
+	}
+	m.filters = filterState{playedWithinDays: 7}
+	if m.passesFilters(fresh) || !m.passesFilters(old) {
+		t.Fatal("expected played within filter")
+	}
+}
+
+func TestPassesDetailFilters(t *testing.T) {
+	gentle := video{Name: "gentle", Duration: 20 * time.Minute, Details: tags.Details{Instructor: "Ada", Difficulty: 1}}
+	blocks := video{Name: "blocks", Duration: 20 * time.Minute, Details: tags.Details{Difficulty: 2, Props: []string{"block"}}}
+	hard := video{Name: "hard", Duration: 20 * time.Minute, Details: tags.Details{Difficulty: 5, Rating: 4}}
+	unrated := video{Name: "unrated", Duration: 20 * time.Minute}
+	f, err := parseSearchQuery("difficulty:beginner props:none len:<30")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	m := model{filters: f}
+	if !m.passesFilters(gentle) || m.passesFilters(blocks) || m.passesFilters(hard) || m.passesFilters(unrated) {
+		t.Fatal("expected beginner without props to keep only the gentle video")
+	}
+	m.filters = filterState{details: detailFilters{props: "BLOCK"}}
+	if m.passesFilters(gentle) || !m.passesFilters(blocks) {
+		t.Fatal("expected props filter to match case-insensitively")
+	}
+	m.filters = filterState{details: detailFilters{rating: intRange{min: 4}}}
+	if !m.passesFilters(hard) || m.passesFilters(unrated) {
+		t.Fatal("expected rating filter to skip unrated videos")
+	}
+	m.filters = filterState{details: detailFilters{instructor: "ada"}}
+	if !m.passesFilters(gentle) || m.passesFilters(hard) {
+		t.Fatal("expected instructor filter")
+	}
+}
+
+func TestSearchBarAppliesQuery(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "flow.mp4", Tags: []string{"hips"}}, {Name: "power.mp4"}}
+	m.applyFiltersAndSort()
+	modelAny, _ := m.handleKeyMsg(keyMsg(":"))
+	m = modelAny.(model)
+	if m.prompt == nil {
+		t.Fatal("expected search bar")
+	}
+	m.prompt.input.SetValue("tag:(hips AND")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.prompt == nil || m.statusMessage == "" {
+		t.Fatal("expected search bar to stay open with the error")
+	}
+	m.prompt.input.SetValue("tag:hips len:..90")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.prompt != nil || len(m.filtered) != 0 {
+		t.Fatalf("expected search applied, got %d videos", len(m.filtered))
+	}
+	if m.inputs.fields[3].Value() != "hips" || m.inputs.fields[2].Value() != "90" {
+		t.Fatal("expected guided dialog to show the search")
+	}
+}
@@ -0,0 +1,154 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// sessionSearchBudget caps the number of combinations examined so building a
+// session stays responsive on large libraries.
+const sessionSearchBudget = 50000
+
+// sessionRequest describes a routine: one video per slot whose durations add
+// up to target within tolerance. Each slot lists tag substrings that the
+// chosen video must all match; an empty slot accepts any video.
+type sessionRequest struct {
+	target    time.Duration
+	tolerance time.Duration
+	slots     [][]string
+}
+
+type sessionPlan struct {
+	videos []video
+	total  time.Duration
+}
+
+// parseSessionSlots splits "warm-up; hatha, hips; savasana" into slots of
+// tag constraints.
+func parseSessionSlots(value string) [][]string {
+	var slots [][]string
+	for _, raw := range strings.Split(value, ";") {
+		var constraints []string
+		for _, tag := range strings.Split(raw, ",") {
+			if trimmed := strings.TrimSpace(tag); trimmed != "" && trimmed != "*" {
+				constraints = append(constraints, trimmed)
+			}
+		}
+		slots = append(slots, constraints)
+	}
+	return slots
+}
+
+func buildSession(candidates []video, req sessionRequest, rng *rand.Rand) (sessionPlan, error) {
+	if req.target <= 0 {
+		return sessionPlan{}, errors.New("target length must be positive")
+	}
+	if len(req.slots) == 0 {
+		return sessionPlan{}, errors.New("session needs at least one slot")
+	}
+	options := make([][]video, len(req.slots))
+	for i, constraints := range req.slots {
+		for _, v := range candidates {
+			if v.Duration > 0 && matchesAllTags(v, constraints) {
+				options[i] = append(options[i], v)
+			}
+		}
+		if len(options[i]) == 0 {
+			return sessionPlan{}, fmt.Errorf("no videos with known length match slot %d (%s)", i+1, describeSlot(constraints))
+		}
+		rng.Shuffle(len(options[i]), func(a, b int) {
+			options[i][a], options[i][b] = options[i][b], options[i][a]
+		})
+	}
+	search := sessionSearch{req: req, options: options, used: make(map[string]struct{}), budget: sessionSearchBudget}
+	if search.visit(0, 0) {
+		return sessionPlan{videos: search.found, total: search.foundTotal}, nil
+	}
+	if search.bestTotal > 0 {
+		return sessionPlan{}, fmt.Errorf("no combination within ±%s of %s (closest %s)",
+			formatDuration(req.tolerance), formatDuration(req.target), formatDuration(search.bestTotal))
+	}
+	return sessionPlan{}, errors.New("not enough distinct videos for every slot")
+}
+
+type sessionSearch struct {
+	req        sessionRequest
+	options    [][]video
+	used       map[string]struct{}
+	chosen     []video
+	found      []video
+	foundTotal time.Duration
+	bestTotal  time.Duration
+	budget     int
+}
+
+func (s *sessionSearch) visit(slot int, total time.Duration) bool {
+	if slot == len(s.options) {
+		if absDuration(s.req.target-total) < absDuration(s.req.target-s.bestTotal) {
+			s.bestTotal = total
+		}
+		if absDuration(s.req.target-total) <= s.req.tolerance {
+			s.found = append([]video{}, s.chosen...)
+			s.foundTotal = total
+			return true
+		}
+		return false
+	}
+	for _, v := range s.options[slot] {
+		if s.budget <= 0 {
+			return false
+		}
+		s.budget--
+		if _, taken := s.used[v.Path]; taken {
+			continue
+		}
+		next := total + v.Duration
+		if next > s.req.target+s.req.tolerance {
+			continue
+		}
+		s.used[v.Path] = struct{}{}
+		s.chosen = append(s.chosen, v)
+		if s.visit(slot+1, next) {
+			return true
+		}
+		s.chosen = s.chosen[:len(s.chosen)-1]
+		delete(s.used, v.Path)
+	}
+	return false
+}
+
+func matchesAllTags(v video, constraints []string) bool {
+	for _, constraint := range constraints {
+		if !hasTagContaining(v, constraint) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasTagContaining(v video, query string) bool {
+	lowered := strings.ToLower(query)
+	for _, tag := range v.Tags {
+		if strings.Contains(strings.ToLower(tag), lowered) {
+			return true
+		}
+	}
+	return false
+}
+
+func describeSlot(constraints []string) string {
+	if len(constraints) == 0 {
+		return "any"
+	}
+	return strings.Join(constraints, ", ")
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,155 @@
+package app
+
+import (
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func sessionLibrary(root string) []video {
+	return []video{
+		{Name: "sun salutation.mp4", Path: filepath.Join(root, "sun.mp4"), Duration: 10 * time.Minute, Tags: []string{"warm-up"}},
+		{Name: "neck release.mp4", Path: filepath.Join(root, "neck.mp4"), Duration: 5 * time.Minute, Tags: []string{"warm-up"}},
+		{Name: "power flow.mp4", Path: filepath.Join(root, "power.mp4"), Duration: 45 * time.Minute, Tags: []string{"flow"}},
+		{Name: "hip flow.mp4", Path: filepath.Join(root, "hip.mp4"), Duration: 30 * time.Minute, Tags: []string{"flow", "hips"}},
+		{Name: "savasana.mp4", Path: filepath.Join(root, "savasana.mp4"), Duration: 8 * time.Minute, Tags: []string{"savasana"}},
+		{Name: "unknown.mp4", Path: filepath.Join(root, "unknown.mp4"), Tags: []string{"flow"}},
+	}
+}
+
+func TestParseSessionSlots(t *testing.T) {
+	slots := parseSessionSlots(" warm-up ; hatha, hips ;*")
+	if len(slots) != 3 {
+		t.Fatalf("expected 3 slots, got %v", slots)
+	}
+	if len(slots[1]) != 2 || slots[1][1] != "hips" {
+		t.Fatalf("unexpected slot constraints %v", slots[1])
+	}
+	if len(slots[2]) != 0 {
+		t.Fatalf("expected wildcard slot, got %v", slots[2])
+	}
+}
+
+func TestBuildSessionHitsTarget(t *testing.T) {
+	req := sessionRequest{
+		target:    60 * time.Minute,
+		tolerance: 2 * time.Minute,
+		slots:     parseSessionSlots("warm-up; flow; savasana"),
+	}
+	for seed := int64(0); seed < 10; seed++ {
+		plan, err := buildSession(sessionLibrary(t.TempDir()), req, rand.New(rand.NewSource(seed)))
+		if err != nil {
+			t.Fatalf("buildSession: %v", err)
+		}
+		if len(plan.videos) != 3 || absDuration(plan.total-req.target) > req.tolerance {
+			t.Fatalf("unexpected plan %+v", plan)
+		}
+		if plan.videos[2].Name != "savasana.mp4" {
+			t.Fatalf("expected savasana last, got %s", plan.videos[2].Name)
+		}
+	}
+}
+
+func TestBuildSessionErrors(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	library := sessionLibrary(t.TempDir())
+	if _, err := buildSession(library, sessionRequest{target: 10 * time.Minute, slots: parseSessionSlots("meditation")}, rng); err == nil {
+		t.Fatal("expected error for unmatched slot")
+	}
+	_, err := buildSession(library, sessionRequest{target: 200 * time.Minute, slots: parseSessionSlots("flow; flow")}, rng)
+	if err == nil || !strings.Contains(err.Error(), "closest") {
+		t.Fatalf("expected closest match error, got %v", err)
+	}
+	if _, err := buildSession(library, sessionRequest{slots: parseSessionSlots("flow")}, rng); err == nil {
+		t.Fatal("expected error for missing target")
+	}
+}
+
+func TestSessionBuilderPlaysSequence(t *testing.T) {
+	root := t.TempDir()
+	m, err := newModel(Options{Root: root, Player: "true"})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	m.videos = sessionLibrary(root)
+	m.applyFiltersAndSort()
+	modelAny, _ := m.handleKeyMsg(keyMsg("B"))
+	m = modelAny.(model)
+	if !m.showSession {
+		t.Fatal("expected session builder to open")
+	}
+	m.sessionInputs.fields[0].SetValue("60")
+	m.sessionInputs.fields[2].SetValue("warm-up; flow; savasana")
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if m.sessionPlan == nil {
+		t.Fatalf("expected plan, status %s", m.statusMessage)
+	}
+	if modal := m.View(); !strings.Contains(modal, "Total") {
+		t.Fatalf("expected plan preview in view: %s", modal)
+	}
+	plan := *m.sessionPlan
+	modelAny, cmd := m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if cmd == nil || m.showSession {
+		t.Fatal("expected session playback to start")
+	}
+	if m.sequencePath != plan.videos[0].Path || len(m.upNext) != 2 {
+		t.Fatalf("unexpected sequence state %s %v", m.sequencePath, m.upNext)
+	}
+	modelAny, cmd = m.Update(playbackEndedMsg{path: plan.videos[0].Path})
+	m = modelAny.(model)
+	if cmd == nil || m.sequencePath != plan.videos[1].Path {
+		t.Fatal("expected next session video to launch")
+	}
+	modelAny, _ = m.handleKeyMsg(keyMsg("S"))
+	m = modelAny.(model)
+	if m.sequencePath != "" || m.upNext != nil {
+		t.Fatal("expected sequence stopped")
+	}
+}
+
+func TestSessionBuilderTakesQAsText(t *testing.T) {
+	m := loadedModel(t, Options{Root: t.TempDir()}, nil)
+	modelAny, _ := m.handleKeyMsg(keyMsg("B"))
+	m = modelAny.(model)
+	for range 2 {
+		modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyTab})
+		m = modelAny.(model)
+	}
+	modelAny, cmd := m.handleKeyMsg(keyMsg("q"))
+	m = modelAny.(model)
+	if cmd != nil {
+		if _, quit := cmd().(tea.QuitMsg); quit {
+			t.Fatal("expected q to be typed, not to quit")
+		}
+	}
+	if !m.showSession || m.sessionInputs.fields[2].Value() != "q" {
+		t.Fatalf("expected q in the slots field, got %q", m.sessionInputs.fields[2].Value())
+	}
+}
+
+func TestSessionBuilderReportsInvalidInput(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	modelAny, _ := m.openSessionBuilder()
+	m = modelAny.(model)
+	m.sessionInputs.fields[0].SetValue("soon")
+	modelAny, _ = m.handleSessionKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	if !strings.Contains(m.renderSessionModal(), "invalid target minutes") {
+		t.Fatalf("expected validation error in modal")
+	}
+	modelAny, _ = m.handleSessionKey(tea.KeyMsg{Type: tea.KeyEsc})
+	if modelAny.(model).showSession {
+		t.Fatal("expected builder closed")
+	}
+}
@@ -0,0 +1,320 @@
+package app
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	// statsPlayedRows is how many videos the most and least played lists
+	// show.
+	statsPlayedRows = 5
+	// statsWeeks and statsMonths are the practice periods reported, ending
+	// with the current one.
+	statsWeeks  = 12
+	statsMonths = 12
+	// statsBarWidth is the width of the longest bar in the text report.
+	statsBarWidth = 30
+)
+
+// lengthBuckets are the upper bounds of the length distribution in minutes;
+// the last bucket is open.
+var lengthBuckets = []int{15, 30, 45, 60, 90}
+
+// libraryStats is the report behind `yoga stats` and the TUI stats screen.
+// The library figures cover the selected videos; practice time covers the
+// whole practice log.
+type libraryStats struct {
+	Videos        int             `json:"videos"`
+	TotalMinutes  int             `json:"total_minutes"`
+	UnknownLength int             `json:"unknown_length"`
+	Tags          []tagStats      `json:"tags"`
+	Lengths       []lengthStats   `json:"lengths"`
+	MostPlayed    []playedStats   `json:"most_played"`
+	LeastPlayed   []playedStats   `json:"least_played"`
+	Weeks         []practiceStats `json:"practice_weeks"`
+	Months        []practiceStats `json:"practice_months"`
+}
+
+type tagStats struct {
+	Tag     string `json:"tag"`
+	Videos  int    `json:"videos"`
+	Minutes int    `json:"minutes"`
+}
+
+type lengthStats struct {
+	Label  string `json:"label"`
+	Videos int    `json:"videos"`
+}
+
+type playedStats struct {
+	Name       string `json:"name"`
+	Path       string `json:"path"`
+	Plays      int    `json:"plays"`
+	LastPlayed string `json:"last_played,omitempty"`
+}
+
+type practiceStats struct {
+	Period  string `json:"period"`
+	Plays   int    `json:"plays"`
+	Minutes int    `json:"minutes"`
+}
+
+// computeStats builds the report for videos and the practice log up to now.
+func computeStats(videos []video, history *playHistory, now time.Time) libraryStats {
+	stats := libraryStats{Videos: len(videos), Tags: []tagStats{}}
+	var total time.Duration
+	tagDurations := make(map[string]time.Duration)
+	tagVideos := make(map[string]int)
+	buckets := make([]int, len(lengthBuckets)+1)
+	for _, v := range videos {
+		for _, tag := range v.Tags {
+			tagDurations[tag] += v.Duration
+			tagVideos[tag]++
+		}
+		if v.Duration <= 0 {
+			stats.UnknownLength++
+			continue
+		}
+		total += v.Duration
+		bucket := len(lengthBuckets)
+		for i, limit := range lengthBuckets {
+			if v.Duration < time.Duration(limit)*time.Minute {
+				bucket = i
+				break
+			}
+		}
+		buckets[bucket]++
+	}
+	stats.TotalMinutes = int(total.Minutes())
+	for tag, count := range tagVideos {
+		stats.Tags = append(stats.Tags, tagStats{Tag: tag, Videos: count, Minutes: int(tagDurations[tag].Minutes())})
+	}
+	slices.SortFunc(stats.Tags, func(a, b tagStats) int {
+		return cmp.Or(cmp.Compare(b.Minutes, a.Minutes), cmp.Compare(b.Videos, a.Videos), strings.Compare(a.Tag, b.Tag))
+	})
+	for i, count := range buckets {
+		stats.Lengths = append(stats.Lengths, lengthStats{Label: lengthBucketLabel(i), Videos: count})
+	}
+	stats.MostPlayed, stats.LeastPlayed = playedExtremes(videos)
+	stats.Weeks, stats.Months = practicePeriods(history.Entries(), now)
+	return stats
+}
+
+func lengthBucketLabel(i int) string {
+	switch i {
+	case 0:
+		return fmt.Sprintf("< %d min", lengthBuckets[0])
+	case len(lengthBuckets):
+		return fmt.Sprintf(">= %d min", lengthBuckets[i-1])
+	}
+	return fmt.Sprintf("%d-%d min", lengthBuckets[i-1], lengthBuckets[i])
+}
+
+// playedExtremes returns the most played videos, most recent first among
+// equals, and the least played ones, never and longest ago played first.
+func playedExtremes(videos []video) ([]playedStats, []playedStats) {
+	sorted := slices.Clone(videos)
+	slices.SortFunc(sorted, func(a, b video) int {
+		return cmp.Or(cmp.Compare(b.PlayCount, a.PlayCount), b.LastPlayed.Compare(a.LastPlayed), strings.Compare(a.Name, b.Name))
+	})
+	most, least := []playedStats{}, []playedStats{}
+	for _, v := range sorted {
+		if v.PlayCount == 0 || len(most) == statsPlayedRows {
+			break
+		}
+		most = append(most, newPlayedStats(v))
+	}
+	for i := len(sorted) - 1; i >= 0 && len(least) < statsPlayedRows; i-- {
+		least = append(least, newPlayedStats(sorted[i]))
+	}
+	return most, least
+}
+
+func newPlayedStats(v video) playedStats {
+	return playedStats{Name: v.Name, Path: v.Path, Plays: v.PlayCount, LastPlayed: formatTimestamp(v.LastPlayed)}
+}
+
+// practicePeriods sums the practice log per ISO week and per calendar month,
+// including periods without practice.
+func practicePeriods(entries []historyEntry, now time.Time) ([]practiceStats, []practiceStats) {
+	thisWeek := weekStart(now)
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	weeks := make([]practiceStats, statsWeeks)
+	for i := range weeks {
+		year, week := thisWeek.AddDate(0, 0, -7*(statsWeeks-1-i)).ISOWeek()
+		weeks[i].Period = fmt.Sprintf("%d-W%02d", year, week)
+	}
+	months := make([]practiceStats, statsMonths)
+	for i := range months {
+		months[i].Period = thisMonth.AddDate(0, -(statsMonths - 1 - i), 0).Format("2006-01")
+	}
+	weekSeconds := make([]float64, statsWeeks)
+	monthSeconds := make([]float64, statsMonths)
+	for _, entry := range entries {
+		started := time.Unix(entry.StartedUnix, 0).In(now.Location())
+		if started.After(now) {
+			continue
+		}
+		// Rounding absorbs weeks that are an hour short or long around
+		// daylight saving changes.
+		weeksAgo := int(math.Round(thisWeek.Sub(weekStart(started)).Hours() / (24 * 7)))
+		if i := statsWeeks - 1 - weeksAgo; i >= 0 {
+			weeks[i].Plays++
+			weekSeconds[i] += entry.WatchedSeconds
+		}
+		monthsAgo := (thisMonth.Year()-started.Year())*12 + int(thisMonth.Month()) - int(started.Month())
+		if i := statsMonths - 1 - monthsAgo; i >= 0 {
+			months[i].Plays++
+			monthSeconds[i] += entry.WatchedSeconds
+		}
+	}
+	for i := range weeks {
+		weeks[i].Minutes = int(weekSeconds[i] / 60)
+	}
+	for i := range months {
+		months[i].Minutes = int(monthSeconds[i] / 60)
+	}
+	return weeks, months
+}
+
+// weekStart returns midnight of the Monday starting t's week.
+func weekStart(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+func writeStatsJSON(w io.Writer, stats libraryStats) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+// writeStatsText prints the whole report.
+func writeStatsText(w io.Writer, stats libraryStats) error {
+	sections := []func(io.Writer){
+		func(w io.Writer) { writeStatsLibrary(w, stats, 0) },
+		func(w io.Writer) { writeStatsPlayed(w, "Most played", stats.MostPlayed) },
+		func(w io.Writer) { writeStatsPlayed(w, "Least played", stats.LeastPlayed) },
+		func(w io.Writer) { writeStatsPractice(w, "Practice per week", stats.Weeks) },
+		func(w io.Writer) { writeStatsPractice(w, "Practice per month", stats.Months) },
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		section(tw)
+	}
+	return tw.Flush()
+}
+
+// writeStatsLibrary prints the totals, the hours per tag and the length
+// distribution; tagLimit caps the tag rows when positive.
+func writeStatsLibrary(w io.Writer, stats libraryStats, tagLimit int) {
+	fmt.Fprintf(w, "Library: %d videos, %s", stats.Videos, formatHours(stats.TotalMinutes))
+	if stats.UnknownLength > 0 {
+		fmt.Fprintf(w, " (%d without a known length)", stats.UnknownLength)
+	}
+	fmt.Fprintln(w, "\n\nHours per tag")
+	if len(stats.Tags) == 0 {
+		fmt.Fprintln(w, "  (no tags)")
+	}
+	for i, tag := range stats.Tags {
+		if tagLimit > 0 && i == tagLimit {
+			fmt.Fprintf(w, "  ... and %d more tags\n", len(stats.Tags)-i)
+			break
+		}
+		fmt.Fprintf(w, "  %s\t%s\t%d videos\n", tag.Tag, formatHours(tag.Minutes), tag.Videos)
+	}
+	fmt.Fprintln(w, "\nLengths")
+	most := 0
+	for _, bucket := range stats.Lengths {
+		most = max(most, bucket.Videos)
+	}
+	for _, bucket := range stats.Lengths {
+		fmt.Fprintf(w, "  %s\t%d%s\n", bucket.Label, bucket.Videos, statsBar(bucket.Videos, most))
+	}
+}
+
+func writeStatsPlayed(w io.Writer, title string, rows []playedStats) {
+	fmt.Fprintln(w, title)
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "  (nothing played yet)")
+	}
+	for _, row := range rows {
+		last := "never"
+		if row.LastPlayed != "" {
+			last = "last " + row.LastPlayed[:len("2006-01-02")]
+		}
+		fmt.Fprintf(w, "  %d×\t%s\t%s\n", row.Plays, row.Name, last)
+	}
+}
+
+func writeStatsPractice(w io.Writer, title string, periods []practiceStats) {
+	fmt.Fprintln(w, title)
+	longest := 0
+	for _, period := range periods {
+		longest = max(longest, period.Minutes)
+	}
+	for _, period := range periods {
+		fmt.Fprintf(w, "  %s\t%d min\t%d plays%s\n", period.Period, period.Minutes, period.Plays, statsBar(period.Minutes, longest))
+	}
+}
+
+func formatHours(minutes int) string {
+	return fmt.Sprintf("%.1f h", float64(minutes)/60)
+}
+
+// statsBar draws value as a bar scaled so that most fills statsBarWidth,
+// as a further column, or nothing for zero.
+func statsBar(value, most int) string {
+	if value <= 0 || most <= 0 {
+		return ""
+	}
+	return "\t" + strings.Repeat("█", max(1, value*statsBarWidth/most))
+}
+
+// FormatText is the human readable format of Stats.
+const FormatText = "text"
+
+// StatsOptions configures Stats.
+type StatsOptions struct {
+	Selection
+	// Format is FormatText or FormatJSON.
+	Format string
+}
+
+// Stats prints library statistics for the selected videos and the practice
+// time of the whole practice log.
+func Stats(opts Options, req StatsOptions, stdout, stderr io.Writer) error {
+	format := strings.ToLower(req.Format)
+	if format == "" {
+		format = FormatText
+	}
+	if format != FormatText && format != FormatJSON {
+		return fmt.Errorf("unknown format %q (use text or json)", req.Format)
+	}
+	snap, err := loadSnapshot(opts)
+	printWarnings(stderr, snap.warnings)
+	if err != nil {
+		return err
+	}
+	selected, err := req.selectVideos(snap.videos, snap.savedFilters)
+	if err != nil {
+		return err
+	}
+	stats := computeStats(selected, snap.history, time.Now())
+	if format == FormatJSON {
+		return writeStatsJSON(stdout, stats)
+	}
+	return writeStatsText(stdout, stats)
+}
@@ -0,0 +1,144 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestComputeStats(t *testing.T) {
+	now := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	played := now.Add(-24 * time.Hour)
+	videos := []video{
+		{Name: "a.mp4", Path: "/y/a.mp4", Duration: 10 * time.Minute, Tags: []string{"hips"}, PlayCount: 3, LastPlayed: played},
+		{Name: "b.mp4", Path: "/y/b.mp4", Duration: 25 * time.Minute, Tags: []string{"hips", "morning"}, PlayCount: 1, LastPlayed: played.Add(-time.Hour)},
+		{Name: "c.mp4", Path: "/y/c.mp4", Duration: 40 * time.Minute, Tags: []string{"flow"}},
+		{Name: "d.mp4", Path: "/y/d.mp4", Tags: []string{"flow"}},
+		{Name: "e.mp4", Path: "/y/e.mp4", Duration: 100 * time.Minute},
+	}
+	history := &playHistory{entries: []historyEntry{
+		{Path: "/y/a.mp4", StartedUnix: played.Unix(), WatchedSeconds: 30 * 60},
+		{Path: "/y/b.mp4", StartedUnix: time.Date(2026, 10, 5, 8, 0, 0, 0, time.UTC).Unix(), WatchedSeconds: 20 * 60},
+		{Path: "/y/c.mp4", StartedUnix: time.Date(2026, 9, 1, 8, 0, 0, 0, time.UTC).Unix(), WatchedSeconds: 45 * 60},
+		{Path: "/y/c.mp4", StartedUnix: time.Date(2024, 10, 1, 8, 0, 0, 0, time.UTC).Unix(), WatchedSeconds: 60 * 60},
+		{Path: "/y/c.mp4", StartedUnix: now.Add(time.Hour).Unix(), WatchedSeconds: 60 * 60},
+	}}
+
+	stats := computeStats(videos, history, now)
+	if stats.Videos != 5 || stats.TotalMinutes != 175 || stats.UnknownLength != 1 {
+		t.Fatalf("unexpected totals %+v", stats)
+	}
+	wantTags := []tagStats{{Tag: "flow", Videos: 2, Minutes: 40}, {Tag: "hips", Videos: 2, Minutes: 35}, {Tag: "morning", Videos: 1, Minutes: 25}}
+	if len(stats.Tags) != len(wantTags) {
+		t.Fatalf("expected tags %+v, got %+v", wantTags, stats.Tags)
+	}
+	for i := range wantTags {
+		if stats.Tags[i] != wantTags[i] {
+			t.Fatalf("expected tags %+v, got %+v", wantTags, stats.Tags)
+		}
+	}
+	var lengths []int
+	for _, bucket := range stats.Lengths {
+		lengths = append(lengths, bucket.Videos)
+	}
+	if !slices.Equal(lengths, []int{1, 1, 1, 0, 0, 1}) {
+		t.Fatalf("unexpected length distribution %v", lengths)
+	}
+	if stats.Lengths[0].Label != "< 15 min" || stats.Lengths[5].Label != ">= 90 min" {
+		t.Fatalf("unexpected bucket labels %+v", stats.Lengths)
+	}
+	if len(stats.MostPlayed) != 2 || stats.MostPlayed[0].Name != "a.mp4" || stats.MostPlayed[0].Plays != 3 {
+		t.Fatalf("unexpected most played %+v", stats.MostPlayed)
+	}
+	if len(stats.LeastPlayed) != 5 || stats.LeastPlayed[0].Plays != 0 || stats.LeastPlayed[4].Name != "a.mp4" {
+		t.Fatalf("unexpected least played %+v", stats.LeastPlayed)
+	}
+
+	weeks, months := stats.Weeks, stats.Months
+	if len(weeks) != statsWeeks || len(months) != statsMonths {
+		t.Fatalf("expected %d weeks and %d months, got %d and %d", statsWeeks, statsMonths, len(weeks), len(months))
+	}
+	if last := weeks[len(weeks)-1]; last != (practiceStats{Period: "2026-W42", Plays: 1, Minutes: 30}) {
+		t.Fatalf("unexpected current week %+v", last)
+	}
+	if prev := weeks[len(weeks)-2]; prev != (practiceStats{Period: "2026-W41", Plays: 1, Minutes: 20}) {
+		t.Fatalf("unexpected previous week %+v", prev)
+	}
+	if last := months[len(months)-1]; last != (practiceStats{Period: "2026-10", Plays: 2, Minutes: 50}) {
+		t.Fatalf("unexpected current month %+v", last)
+	}
+	if prev := months[len(months)-2]; prev != (practiceStats{Period: "2026-09", Plays: 1, Minutes: 45}) {
+		t.Fatalf("unexpected previous month %+v", prev)
+	}
+	if months[0].Period != "2025-11" {
+		t.Fatalf("expected the report to start in 2025-11, got %s", months[0].Period)
+	}
+}
+
+func TestStatsTextAndJSON(t *testing.T) {
+	opts := writeListLibrary(t)
+	var stdout, stderr bytes.Buffer
+	if err := Stats(opts, StatsOptions{Format: FormatJSON, Selection: Selection{Tags: "hips"}}, &stdout, &stderr); err != nil {
+		t.Fatalf("Stats: %v (%s)", err, stderr.String())
+	}
+	var stats libraryStats
+	if err := json.Unmarshal(stdout.Bytes(), &stats); err != nil {
+		t.Fatalf("decode %q: %v", stdout.String(), err)
+	}
+	if stats.Videos != 2 || stats.TotalMinutes != 35 || len(stats.Tags) != 2 || stats.Tags[0].Tag != "hips" {
+		t.Fatalf("unexpected report %+v", stats)
+	}
+	if stats.MostPlayed == nil || len(stats.MostPlayed) != 0 {
+		t.Fatalf("expected an empty most played list, got %#v", stats.MostPlayed)
+	}
+
+	stdout.Reset()
+	if err := Stats(opts, StatsOptions{}, &stdout, &stderr); err != nil {
+		t.Fatalf("Stats: %v", err)
+	}
+	out := stdout.String()
+	for _, want := range []string{"Library: 3 videos", "Hours per tag", "morning", "30-45 min", "(nothing played yet)", "Practice per month"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in the report:\n%s", want, out)
+		}
+	}
+
+	if err := Stats(opts, StatsOptions{Format: "xml"}, &stdout, &stderr); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestStatsScreen(t *testing.T) {
+	m := loadedModel(t, Options{Root: t.TempDir()}, []video{
+		{Name: "a.mp4", Path: "/y/a.mp4", Duration: 20 * time.Minute, Tags: []string{"hips"}},
+		{Name: "b.mp4", Path: "/y/b.mp4", Duration: 50 * time.Minute, Tags: []string{"flow"}},
+	})
+
+	modelAny, _ := m.handleKeyMsg(keyMsg("I"))
+	m = modelAny.(model)
+	if m.stats == nil || m.stats.Videos != 2 {
+		t.Fatalf("expected the stats screen for 2 videos, got %+v", m.stats)
+	}
+	view := m.View()
+	for _, want := range []string{"Library: 2 videos, 1.2 h", "Hours per tag", "Most played", "Practice per week"} {
+		if !strings.Contains(view, want) {
+			t.Fatalf("expected %q on the stats screen:\n%s", want, view)
+		}
+	}
+	// Keys other than the close keys do nothing while the screen is open.
+	modelAny, _ = m.handleKeyMsg(keyMsg("x"))
+	m = modelAny.(model)
+	if m.stats == nil {
+		t.Fatal("expected the stats screen to stay open")
+	}
+	modelAny, _ = m.handleKeyMsg(tea.KeyMsg{Type: tea.KeyEsc})
+	m = modelAny.(model)
+	if m.stats != nil || strings.Contains(m.View(), "Hours per tag") {
+		t.Fatal("expected esc to close the stats screen")
+	}
+}
@@ -17,4 +17,5 @@ var (
 	filterStyle    = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("105")).Padding(1, 2)
 	statusStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
 	highlightStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("212")).Bold(true)
+	queueStyle     = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("63")).Padding(0, 1)
 )
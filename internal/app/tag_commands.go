@@ -19,3 +19,34 @@ func saveTagsCmd(path string, entries []string) tea.Cmd {
 		return tagsSavedMsg{path: path, tags: sanitized}
 	}
 }
+
+// saveMetadataCmd writes the whole sidecar and reads it back, so the model
+// gets the normalised values.
+func saveMetadataCmd(path string, md tags.Metadata) tea.Cmd {
+	return func() tea.Msg {
+		if err := tags.SaveMetadata(path, md); err != nil {
+			return metadataSavedMsg{path: path, err: err}
+		}
+		saved, err := tags.LoadMetadata(path)
+		if err != nil {
+			return metadataSavedMsg{path: path, err: err}
+		}
+		return metadataSavedMsg{path: path, metadata: saved}
+	}
+}
+
+// updateMetadataCmd changes a single field: it reads the sidecar, applies
+// update and writes it back, so the other fields stay as they are on disk.
+func updateMetadataCmd(path, status string, update func(*tags.Metadata)) tea.Cmd {
+	return func() tea.Msg {
+		md, err := tags.LoadMetadata(path)
+		if err != nil {
+			return metadataSavedMsg{path: path, err: err}
+		}
+		update(&md)
+		if err := tags.SaveMetadata(path, md); err != nil {
+			return metadataSavedMsg{path: path, err: err}
+		}
+		return metadataSavedMsg{path: path, metadata: md, status: status}
+	}
+}
@@ -0,0 +1,82 @@
+package app
+
+import (
+	"sort"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// maxTagSuggestions caps the completion list under the tag input.
+const maxTagSuggestions = 5
+
+// tagCompletion holds the suggestions for the tag being typed. vocabulary
+// is built when the editor opens.
+type tagCompletion struct {
+	vocabulary  []string
+	suggestions []string
+	cursor      int
+}
+
+// tagVocabulary lists the library's tags in their canonical form, most used
+// first.
+func tagVocabulary(videos []video, policy tags.Policy) []string {
+	counts := make(map[string]int)
+	for _, v := range videos {
+		for _, tag := range policy.Normalize(v.Tags) {
+			counts[tag]++
+		}
+	}
+	vocabulary := make([]string, 0, len(counts))
+	for tag := range counts {
+		vocabulary = append(vocabulary, tag)
+	}
+	sort.Slice(vocabulary, func(i, j int) bool {
+		a, b := vocabulary[i], vocabulary[j]
+		if counts[a] != counts[b] {
+			return counts[a] > counts[b]
+		}
+		return strings.ToLower(a) < strings.ToLower(b)
+	})
+	return vocabulary
+}
+
+// suggestTags completes the last comma separated entry of input. Tags
+// starting with it come before tags containing it; tags already entered
+// are left out.
+func suggestTags(vocabulary []string, input string) []string {
+	entries := strings.Split(input, ",")
+	current := strings.ToLower(strings.TrimSpace(entries[len(entries)-1]))
+	if current == "" {
+		return nil
+	}
+	entered := make(map[string]bool, len(entries))
+	for _, entry := range entries[:len(entries)-1] {
+		entered[strings.ToLower(strings.TrimSpace(entry))] = true
+	}
+	var prefixed, contained []string
+	for _, tag := range vocabulary {
+		lower := strings.ToLower(tag)
+		switch {
+		case entered[lower] || tag == strings.TrimSpace(entries[len(entries)-1]):
+		case strings.HasPrefix(lower, current):
+			prefixed = append(prefixed, tag)
+		case strings.Contains(lower, current):
+			contained = append(contained, tag)
+		}
+	}
+	suggestions := append(prefixed, contained...)
+	if len(suggestions) > maxTagSuggestions {
+		suggestions = suggestions[:maxTagSuggestions]
+	}
+	return suggestions
+}
+
+// completeTag replaces the last entry of input with tag and starts the next.
+func completeTag(input, tag string) string {
+	idx := strings.LastIndex(input, ",")
+	if idx < 0 {
+		return tag + ", "
+	}
+	return input[:idx+1] + " " + tag + ", "
+}
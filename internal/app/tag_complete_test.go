@@ -0,0 +1,93 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func TestTagVocabularyAndSuggestions(t *testing.T) {
+	videos := []video{
+		{Tags: []string{"hips", "morning"}},
+		{Tags: []string{"hips", "Hip"}},
+		{Tags: []string{"shoulders", "hip"}},
+	}
+	vocabulary := tagVocabulary(videos, tags.Policy{})
+	if vocabulary[0] != "hips" {
+		t.Fatalf("expected most used tag first, got %v", vocabulary)
+	}
+	folded := tagVocabulary(videos, tags.Policy{FoldCase: true, Aliases: map[string]string{"hip": "hips"}})
+	if !reflect.DeepEqual(folded, []string{"hips", "morning", "shoulders"}) {
+		t.Fatalf("unexpected canonical vocabulary %v", folded)
+	}
+	if got := suggestTags(folded, "morning, H"); !reflect.DeepEqual(got, []string{"hips", "shoulders"}) {
+		t.Fatalf("expected prefix then substring matches, got %v", got)
+	}
+	if got := suggestTags(folded, "hips, hi"); len(got) != 0 {
+		t.Fatalf("expected entered tags to be skipped, got %v", got)
+	}
+	if got := completeTag("morning, H", "hips"); got != "morning, hips, " {
+		t.Fatalf("unexpected completion %q", got)
+	}
+}
+
+func TestTagEditorCompletesAndNormalizes(t *testing.T) {
+	root := t.TempDir()
+	policy := `{"fold_case": true, "aliases": {"hip": "hips"}}`
+	if err := os.WriteFile(filepath.Join(root, tags.PolicyFile), []byte(policy), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.loading = false
+	path := filepath.Join(root, "flow.mp4")
+	m.videos = []video{{Name: "flow.mp4", Path: path}, {Name: "yin.mp4", Tags: []string{"morning"}}}
+	m.applyFiltersAndSort()
+	modelAny, _ := m.openTagEditor()
+	m = modelAny.(model)
+	for _, r := range "mo" {
+		modelAny, _ = m.handleTagKey(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = modelAny.(model)
+	}
+	if !reflect.DeepEqual(m.tagComplete.suggestions, []string{"morning"}) {
+		t.Fatalf("expected suggestion, got %v", m.tagComplete.suggestions)
+	}
+	modelAny, _ = m.handleTagKey(tea.KeyMsg{Type: tea.KeyTab})
+	m = modelAny.(model)
+	m.tagInput.SetValue(m.tagInput.Value() + "Hip")
+	modelAny, cmd := m.handleTagKey(tea.KeyMsg{Type: tea.KeyEnter})
+	m = modelAny.(model)
+	msg := cmd().(tagsSavedMsg)
+	if msg.err != nil || !reflect.DeepEqual(msg.tags, []string{"hips", "morning"}) {
+		t.Fatalf("expected completed and normalized tags, got %v (%v)", msg.tags, msg.err)
+	}
+}
+
+func TestNewModelReportsBrokenTagPolicy(t *testing.T) {
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, tags.PolicyFile), []byte(`{`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	m, err := newModel(Options{Root: root})
+	if err != nil {
+		t.Fatalf("expected a broken policy not to stop the start: %v", err)
+	}
+	if !reflect.DeepEqual(m.tagPolicy, tags.Policy{}) {
+		t.Fatalf("expected the zero policy, got %+v", m.tagPolicy)
+	}
+	m.loading = false
+	m.videos = []video{{Name: "flow.mp4", Path: filepath.Join(root, "flow.mp4")}}
+	m.applyFiltersAndSort()
+	m.updateStatusAfterLoad(videosLoadedMsg{})
+	if !strings.Contains(m.statusText(), "tag policy warning") {
+		t.Fatalf("expected the parse error in the status line, got %q", m.statusText())
+	}
+}
@@ -0,0 +1,183 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// Tag actions of TagFiles.
+const (
+	TagAdd    = "add"
+	TagRemove = "remove"
+	TagSet    = "set"
+	TagShow   = "show"
+)
+
+// TagCommand configures TagFiles.
+type TagCommand struct {
+	// Action is TagAdd, TagRemove, TagSet or TagShow.
+	Action string
+	// Pattern is a video path, a directory (searched recursively like a
+	// library root) or a glob matching either.
+	Pattern string
+	// Tags are the tags to add, remove or set; entries may hold several
+	// comma separated tags.
+	Tags []string
+	// DryRun reports the changes without writing any sidecar.
+	DryRun bool
+}
+
+// tagSet replaces the tags of every video.
+type tagSet struct {
+	tags []string
+}
+
+func (s tagSet) describe() string {
+	return fmt.Sprintf("Set tags (%s)", strings.Join(s.tags, ", "))
+}
+
+func (s tagSet) apply(values []string) ([]string, bool) {
+	return s.tags, len(values) != len(s.tags) || !sameTags(values, s.tags)
+}
+
+// edit builds the tag edit for the action. Added and set tags follow the
+// tag policy.
+func (c TagCommand) edit(policy tags.Policy) (tagEdit, error) {
+	var values []string
+	for _, entry := range c.Tags {
+		values = append(values, parseTagInput(entry)...)
+	}
+	switch c.Action {
+	case TagAdd, TagRemove:
+		if len(values) == 0 {
+			return nil, fmt.Errorf("no tags to %s", c.Action)
+		}
+		if c.Action == TagRemove {
+			return tagBulkEdit{remove: values}, nil
+		}
+		return tagBulkEdit{add: policy.Normalize(values)}, nil
+	case TagSet:
+		values = policy.Normalize(values)
+		sort.Strings(values)
+		return tagSet{tags: values}, nil
+	case TagShow:
+		if len(values) > 0 {
+			return nil, fmt.Errorf("show takes no tags")
+		}
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown tag action %q (use add, remove, set or show)", c.Action)
+}
+
+// TagFiles shows or edits the tags of the videos matching cmd.Pattern. Each
+// video is reported on stdout; files that cannot be read or written are
+// reported on stderr and make TagFiles fail once every file was handled.
+func TagFiles(opts Options, cmd TagCommand, stdout, stderr io.Writer) error {
+	policy, err := tags.LoadPolicy(filepath.Join(opts.Root, tags.PolicyFile))
+	if err != nil {
+		return err
+	}
+	edit, err := cmd.edit(policy)
+	if err != nil {
+		return err
+	}
+	paths, failures := matchVideoPaths(cmd.Pattern)
+	if len(paths) == 0 && len(failures) == 0 {
+		return fmt.Errorf("no videos match %q", cmd.Pattern)
+	}
+	total := len(paths) + len(failures)
+	changed := 0
+	for _, path := range paths {
+		current, err := tags.Load(path)
+		if err != nil {
+			failures = append(failures, tagFailure{path: path, err: err})
+			continue
+		}
+		if edit == nil {
+			fmt.Fprintf(stdout, "%s: %s\n", path, formatTagList(current))
+			continue
+		}
+		updated, ok := edit.apply(current)
+		if !ok {
+			continue
+		}
+		// Sidecars store their tags sorted; report them that way.
+		updated = append([]string(nil), updated...)
+		sort.Strings(updated)
+		if !cmd.DryRun {
+			if err := tags.Save(path, updated); err != nil {
+				failures = append(failures, tagFailure{path: path, err: err})
+				continue
+			}
+		}
+		changed++
+		fmt.Fprintf(stdout, "%s: %s -> %s\n", path, formatTagList(current), formatTagList(updated))
+	}
+	if edit != nil {
+		verb := "Updated"
+		if cmd.DryRun {
+			verb = "Would update"
+		}
+		fmt.Fprintf(stdout, "%s %d of %d videos\n", verb, changed, total)
+	}
+	for _, failure := range failures {
+		fmt.Fprintf(stderr, "%s: %v\n", failure.path, failure.err)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d of %d files failed", len(failures), total)
+	}
+	return nil
+}
+
+// matchVideoPaths expands pattern and collects the videos of every matching
+// file or directory. Matches that cannot be read are returned as failures.
+func matchVideoPaths(pattern string) ([]string, []tagFailure) {
+	matches, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, []tagFailure{{path: pattern, err: err}}
+	}
+	if len(matches) == 0 && !hasGlobMeta(pattern) {
+		// Report a missing plain path instead of "no videos match".
+		matches = []string{pattern}
+	}
+	seen := make(map[string]bool)
+	var paths []string
+	var failures []tagFailure
+	for _, match := range matches {
+		videos, err := collectVideoPaths(match)
+		if err != nil {
+			var pathErr *fs.PathError
+			if errors.As(err, &pathErr) && pathErr.Path == match {
+				err = pathErr.Err
+			}
+			failures = append(failures, tagFailure{path: match, err: err})
+			continue
+		}
+		for _, path := range videos {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths, failures
+}
+
+func hasGlobMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+func formatTagList(values []string) string {
+	if len(values) == 0 {
+		return "(none)"
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,92 @@
+package app
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+func TestTagFilesAddRemoveSet(t *testing.T) {
+	opts := writeListLibrary(t)
+	pattern := filepath.Join(opts.Root, "*Hips.mp4")
+	var stdout, stderr bytes.Buffer
+	if err := TagFiles(opts, TagCommand{Action: TagAdd, Pattern: pattern, Tags: []string{"series-a, hips"}}, &stdout, &stderr); err != nil {
+		t.Fatalf("add: %v (%s)", err, stderr.String())
+	}
+	got, _ := tags.Load(filepath.Join(opts.Root, "Medium Hips.mp4"))
+	if !reflect.DeepEqual(got, []string{"hips", "morning", "series-a"}) {
+		t.Fatalf("unexpected tags after add %v", got)
+	}
+	if !strings.Contains(stdout.String(), "Updated 2 of 2 videos") {
+		t.Fatalf("expected a summary, got %q", stdout.String())
+	}
+
+	if err := TagFiles(opts, TagCommand{Action: TagRemove, Pattern: pattern, Tags: []string{"MORNING"}}, &stdout, &stderr); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	got, _ = tags.Load(filepath.Join(opts.Root, "Medium Hips.mp4"))
+	if !reflect.DeepEqual(got, []string{"hips", "series-a"}) {
+		t.Fatalf("unexpected tags after remove %v", got)
+	}
+
+	long := filepath.Join(opts.Root, "Long Flow.mp4")
+	if err := TagFiles(opts, TagCommand{Action: TagSet, Pattern: long, Tags: []string{"vinyasa", "strength"}}, &stdout, &stderr); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, _ = tags.Load(long)
+	if !reflect.DeepEqual(got, []string{"strength", "vinyasa"}) {
+		t.Fatalf("unexpected tags after set %v", got)
+	}
+
+	stdout.Reset()
+	if err := TagFiles(opts, TagCommand{Action: TagShow, Pattern: opts.Root}, &stdout, &stderr); err != nil {
+		t.Fatalf("show: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(stdout.String()), "\n"); len(lines) != 3 || lines[0] != long+": strength, vinyasa" {
+		t.Fatalf("unexpected show output %q", stdout.String())
+	}
+}
+
+func TestTagFilesDryRunWritesNothing(t *testing.T) {
+	opts := writeListLibrary(t)
+	path := filepath.Join(opts.Root, "Short Hips.mp4")
+	var stdout, stderr bytes.Buffer
+	if err := TagFiles(opts, TagCommand{Action: TagSet, Pattern: path, DryRun: true}, &stdout, &stderr); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if got, _ := tags.Load(path); !reflect.DeepEqual(got, []string{"hips"}) {
+		t.Fatalf("dry run changed tags to %v", got)
+	}
+	want := path + ": hips -> (none)\nWould update 1 of 1 videos\n"
+	if stdout.String() != want {
+		t.Fatalf("expected %q, got %q", want, stdout.String())
+	}
+}
+
+func TestTagFilesReportsFailures(t *testing.T) {
+	opts := writeListLibrary(t)
+	broken := filepath.Join(opts.Root, "Broken.mp4")
+	writeClip(t, broken, 60)
+	if err := os.WriteFile(tags.PathFor(broken), []byte("{"), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	var stdout, stderr bytes.Buffer
+	err := TagFiles(opts, TagCommand{Action: TagAdd, Pattern: filepath.Join(opts.Root, "*.mp4"), Tags: []string{"new"}}, &stdout, &stderr)
+	if err == nil || !strings.Contains(err.Error(), "1 of 4 files failed") {
+		t.Fatalf("expected a failure summary, got %v", err)
+	}
+	if !strings.HasPrefix(stderr.String(), broken+": ") {
+		t.Fatalf("expected the broken sidecar on stderr, got %q", stderr.String())
+	}
+	if got, _ := tags.Load(filepath.Join(opts.Root, "Long Flow.mp4")); !reflect.DeepEqual(got, []string{"flow", "new"}) {
+		t.Fatalf("expected the other videos to be tagged, got %v", got)
+	}
+	if err := TagFiles(opts, TagCommand{Action: TagShow, Pattern: filepath.Join(opts.Root, "*.mkv")}, &stdout, &stderr); err == nil {
+		t.Fatal("expected an error when nothing matches")
+	}
+}
@@ -0,0 +1,157 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"codeberg.org/snonux/yoga/internal/tags"
+)
+
+// tagEdit is a change applied to the tags of many videos. apply returns the
+// new tags, or false when the change does not affect values.
+type tagEdit interface {
+	apply(values []string) ([]string, bool)
+	describe() string
+}
+
+// tagChange rewrites one tag across sidecars. An empty to deletes the tag;
+// renaming onto an existing tag merges the two.
+type tagChange struct {
+	from string
+	to   string
+}
+
+func (c tagChange) describe() string {
+	if c.to == "" {
+		return fmt.Sprintf("Deleted %q", c.from)
+	}
+	return fmt.Sprintf("Renamed %q to %q", c.from, c.to)
+}
+
+// apply returns values with the change made, or false when from is absent.
+func (c tagChange) apply(values []string) ([]string, bool) {
+	out := make([]string, 0, len(values))
+	found := false
+	for _, tag := range values {
+		if tag != c.from {
+			out = append(out, tag)
+			continue
+		}
+		found = true
+		if c.to != "" {
+			out = append(out, c.to)
+		}
+	}
+	return out, found
+}
+
+// tagBulkEdit adds and removes tags on selected videos, leaving their other
+// tags alone. Removal ignores case.
+type tagBulkEdit struct {
+	add    []string
+	remove []string
+}
+
+func (e tagBulkEdit) describe() string {
+	parts := make([]string, 0, len(e.add)+len(e.remove))
+	for _, tag := range e.add {
+		parts = append(parts, "+"+tag)
+	}
+	for _, tag := range e.remove {
+		parts = append(parts, "-"+tag)
+	}
+	return fmt.Sprintf("Updated tags (%s)", strings.Join(parts, " "))
+}
+
+func (e tagBulkEdit) apply(values []string) ([]string, bool) {
+	drop := make(map[string]bool, len(e.remove))
+	for _, tag := range e.remove {
+		drop[strings.ToLower(tag)] = true
+	}
+	out := make([]string, 0, len(values)+len(e.add))
+	present := make(map[string]bool, len(values))
+	for _, tag := range values {
+		if drop[strings.ToLower(tag)] {
+			continue
+		}
+		out = append(out, tag)
+		present[strings.ToLower(tag)] = true
+	}
+	for _, tag := range e.add {
+		if !present[strings.ToLower(tag)] {
+			out = append(out, tag)
+			present[strings.ToLower(tag)] = true
+		}
+	}
+	return out, len(out) != len(values) || !sameTags(out, values)
+}
+
+func sameTags(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// tagJob is a tag edit running in the background.
+type tagJob struct {
+	edit     tagEdit
+	progress *loadProgress
+}
+
+type tagFailure struct {
+	path string
+	err  error
+}
+
+// runTagEditCmd rewrites the sidecars of paths one by one. Each sidecar is
+// re-read first, so edits made since the library was loaded are kept.
+func runTagEditCmd(paths []string, edit tagEdit, progress *loadProgress) tea.Cmd {
+	paths = append([]string(nil), paths...)
+	return func() tea.Msg {
+		progress.SetTotal(len(paths))
+		defer progress.MarkDone()
+		done := tagJobDoneMsg{edit: edit, updated: make(map[string][]string)}
+		for _, path := range paths {
+			current, err := tags.Load(path)
+			if err == nil {
+				if changed, ok := edit.apply(current); ok {
+					if err = tags.Save(path, changed); err == nil {
+						current, err = tags.Load(path)
+					}
+				}
+			}
+			if err != nil {
+				done.failures = append(done.failures, tagFailure{path: path, err: err})
+			} else {
+				done.updated[path] = current
+			}
+			progress.Increment()
+		}
+		return done
+	}
+}
+
+func tagJobTickerCmd() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg { return tagJobProgressMsg{} })
+}
+
+// summarizeTagFailures lists the first few failed files.
+func summarizeTagFailures(failures []tagFailure) string {
+	const shown = 3
+	parts := make([]string, 0, shown)
+	for i, failure := range failures {
+		if i == shown {
+			parts = append(parts, fmt.Sprintf("and %d more", len(failures)-shown))
+			break
+		}
+		parts = append(parts, fmt.Sprintf("%s: %v", filepath.Base(failure.path), failure.err))
+	}
+	return fmt.Sprintf("%d failed (%s)", len(failures), strings.Join(parts, "; "))
+}
@@ -0,0 +1,306 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// tagQuery is a compiled "Tags" filter. The language combines terms with
+// AND, OR, NOT and parentheses; adjacent terms are joined with AND and NOT
+// binds tighter than AND, which binds tighter than OR.
+//
+// A bare term matches any tag containing it, =term only matches the whole
+// tag and term* matches tags starting with term. Double quotes keep spaces
+// and keywords literal: ="lower back" or "teacher:adriene"*.
+type tagQuery interface {
+	match(tags []string) bool
+}
+
+type tagMatchMode int
+
+const (
+	tagMatchContains tagMatchMode = iota
+	tagMatchExact
+	tagMatchPrefix
+)
+
+type tagTerm struct {
+	text string
+	mode tagMatchMode
+}
+
+func (t tagTerm) match(tags []string) bool {
+	for _, tag := range tags {
+		tag = strings.ToLower(tag)
+		switch t.mode {
+		case tagMatchExact:
+			if tag == t.text {
+				return true
+			}
+		case tagMatchPrefix:
+			if strings.HasPrefix(tag, t.text) {
+				return true
+			}
+		default:
+			if strings.Contains(tag, t.text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type tagAnd []tagQuery
+
+func (q tagAnd) match(tags []string) bool {
+	for _, sub := range q {
+		if !sub.match(tags) {
+			return false
+		}
+	}
+	return true
+}
+
+type tagOr []tagQuery
+
+func (q tagOr) match(tags []string) bool {
+	for _, sub := range q {
+		if sub.match(tags) {
+			return true
+		}
+	}
+	return false
+}
+
+type tagNot struct {
+	inner tagQuery
+}
+
+func (q tagNot) match(tags []string) bool {
+	return !q.inner.match(tags)
+}
+
+type tagTokenKind int
+
+const (
+	tokTerm tagTokenKind = iota
+	tokAnd
+	tokOr
+	tokNot
+	tokOpen
+	tokClose
+)
+
+type tagToken struct {
+	kind tagTokenKind
+	term tagTerm
+}
+
+// parseTagQuery compiles query. An empty query yields nil.
+func parseTagQuery(query string) (tagQuery, error) {
+	tokens, err := tokenizeTagQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := tagQueryParser{tokens: tokens}
+	q, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, errors.New("unexpected ) in tag query")
+	}
+	return q, nil
+}
+
+func tokenizeTagQuery(query string) ([]tagToken, error) {
+	var tokens []tagToken
+	runes := []rune(query)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tagToken{kind: tokOpen})
+			i++
+		case r == ')':
+			tokens = append(tokens, tagToken{kind: tokClose})
+			i++
+		default:
+			token, next, err := readTagTerm(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// readTagTerm reads a keyword or term starting at runes[start] and returns
+// the index just past it.
+func readTagTerm(runes []rune, start int) (tagToken, int, error) {
+	i := start
+	mode := tagMatchContains
+	if runes[i] == '=' {
+		mode = tagMatchExact
+		i++
+	}
+	var text string
+	quoted := i < len(runes) && runes[i] == '"'
+	if quoted {
+		end := i + 1
+		for end < len(runes) && runes[end] != '"' {
+			end++
+		}
+		if end == len(runes) {
+			return tagToken{}, 0, errors.New("unterminated quote in tag query")
+		}
+		text = string(runes[i+1 : end])
+		i = end + 1
+		if i < len(runes) && runes[i] == '*' {
+			i++
+			mode = tagMatchPrefix
+		}
+	} else {
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) && !strings.ContainsRune(`()"`, runes[end]) {
+			end++
+		}
+		text = string(runes[i:end])
+		i = end
+		if mode == tagMatchContains {
+			switch strings.ToUpper(text) {
+			case "AND":
+				return tagToken{kind: tokAnd}, i, nil
+			case "OR":
+				return tagToken{kind: tokOr}, i, nil
+			case "NOT":
+				return tagToken{kind: tokNot}, i, nil
+			}
+		}
+		if strings.HasSuffix(text, "*") {
+			text = strings.TrimSuffix(text, "*")
+			if mode == tagMatchExact {
+				return tagToken{}, 0, fmt.Errorf("tag %q cannot be both exact and prefix", text)
+			}
+			mode = tagMatchPrefix
+		}
+	}
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text == "" {
+		return tagToken{}, 0, errors.New("empty tag in tag query")
+	}
+	return tagToken{kind: tokTerm, term: tagTerm{text: text, mode: mode}}, i, nil
+}
+
+type tagQueryParser struct {
+	tokens []tagToken
+	pos    int
+}
+
+func (p *tagQueryParser) peek() (tagToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return tagToken{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *tagQueryParser) parseOr() (tagQuery, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	terms := tagOr{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokOr {
+			break
+		}
+		p.pos++
+		next, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, next)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *tagQueryParser) parseAnd() (tagQuery, error) {
+	first, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	terms := tagAnd{first}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokOr || tok.kind == tokClose {
+			break
+		}
+		if tok.kind == tokAnd {
+			p.pos++
+		}
+		next, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, next)
+	}
+	if len(terms) == 1 {
+		return first, nil
+	}
+	return terms, nil
+}
+
+func (p *tagQueryParser) parseNot() (tagQuery, error) {
+	tok, ok := p.peek()
+	if ok && tok.kind == tokNot {
+		p.pos++
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{inner: inner}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *tagQueryParser) parsePrimary() (tagQuery, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, errors.New("tag query ends unexpectedly")
+	}
+	p.pos++
+	switch tok.kind {
+	case tokTerm:
+		return tok.term, nil
+	case tokOpen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing, ok := p.peek(); !ok || closing.kind != tokClose {
+			return nil, errors.New("missing ) in tag query")
+		}
+		p.pos++
+		return inner, nil
+	case tokClose:
+		return nil, errors.New("unexpected ) in tag query")
+	case tokAnd:
+		return nil, errors.New("expected tag before AND")
+	case tokOr:
+		return nil, errors.New("expected tag before OR")
+	}
+	return nil, errors.New("invalid tag query")
+}
@@ -0,0 +1,69 @@
+package app
+
+import "testing"
+
+func TestParseTagQueryMatches(t *testing.T) {
+	tags := []string{"Hatha", "hips", "teacher:adriene", "lower back"}
+	cases := []struct {
+		query string
+		want  bool
+	}{
+		{"hatha", true},
+		{"hip", true},
+		{"=hip", false},
+		{"=hips", true},
+		{"teacher:*", true},
+		{"teacher:a*", true},
+		{"=teacher:adri", false},
+		{"hatha AND (hips OR back) AND NOT advanced", true},
+		{"hatha AND NOT hips", false},
+		{"vinyasa OR hips", true},
+		{"hatha hips", true},
+		{"hatha vinyasa", false},
+		{`="lower back"`, true},
+		{`"lower b"*`, true},
+		{`="lower"`, false},
+		{"not vinyasa", true},
+		{"NOT NOT hips", true},
+		{"(vinyasa OR yin) AND hips", false},
+		{"vinyasa OR hatha AND hips", true},
+	}
+	for _, tc := range cases {
+		query, err := parseTagQuery(tc.query)
+		if err != nil {
+			t.Fatalf("parse %q: %v", tc.query, err)
+		}
+		if got := query.match(tags); got != tc.want {
+			t.Errorf("%q: expected %v, got %v", tc.query, tc.want, got)
+		}
+	}
+}
+
+func TestParseTagQueryErrors(t *testing.T) {
+	for _, query := range []string{"(hips", "hips)", `"lower back`, "hips AND", "OR hips", "NOT", "()", "=", "=hips*"} {
+		if _, err := parseTagQuery(query); err == nil {
+			t.Errorf("expected error for %q", query)
+		}
+	}
+	if query, err := parseTagQuery("   "); err != nil || query != nil {
+		t.Fatalf("expected empty query to compile to nil, got %v %v", query, err)
+	}
+}
+
+func TestApplyFilterInputsRejectsInvalidTagQuery(t *testing.T) {
+	m, err := newModel(Options{Root: t.TempDir()})
+	if err != nil {
+		t.Fatalf("newModel: %v", err)
+	}
+	m.inputs.fields[3].SetValue("hips AND (back")
+	if err := m.applyFilterInputs(); err == nil {
+		t.Fatal("expected parse error")
+	}
+	m.inputs.fields[3].SetValue("hips AND NOT advanced")
+	if err := m.applyFilterInputs(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.passesFilters(video{Tags: []string{"hips", "advanced"}}) || !m.passesFilters(video{Tags: []string{"hips"}}) {
+		t.Fatal("expected compiled query to be applied")
+	}
+}
@@ -1,6 +1,11 @@
 package app
 
-import "time"
+import (
+	"time"
+
+	"codeberg.org/snonux/yoga/internal/probe"
+	"codeberg.org/snonux/yoga/internal/tags"
+)
 
 type video struct {
 	Name     string
@@ -10,4 +15,42 @@ type video struct {
 	Size     int64
 	Err      error
 	Tags     []string
+	// Details are the structured sidecar fields (instructor, style, ...).
+	Details tags.Details
+	// Source is the label of the library root the video lives in.
+	Source string
+	// LastPlayed and PlayCount are derived from the play history.
+	LastPlayed time.Time
+	PlayCount  int
+	// Stream details reported by the prober.
+	Width          int
+	Height         int
+	VideoCodec     string
+	AudioCodec     string
+	AudioLanguages []string
+	Bitrate        int64
+	// match is set on filtered copies while a name filter is active.
+	match nameMatch
+}
+
+func (v *video) applyMedia(media probe.Info) {
+	v.Duration = media.Duration
+	v.Width = media.Width
+	v.Height = media.Height
+	v.VideoCodec = media.VideoCodec
+	v.AudioCodec = media.AudioCodec
+	v.AudioLanguages = media.AudioLanguages
+	v.Bitrate = media.Bitrate
+}
+
+func (v video) mediaInfo() probe.Info {
+	return probe.Info{
+		Duration:       v.Duration,
+		Width:          v.Width,
+		Height:         v.Height,
+		VideoCodec:     v.VideoCodec,
+		AudioCodec:     v.AudioCodec,
+		AudioLanguages: v.AudioLanguages,
+		Bitrate:        v.Bitrate,
+	}
 }
@@ -10,16 +10,7 @@ import (
 )
 
 func videoRow(v video) table.Row {
-	duration := "(unknown)"
-	if v.Duration > 0 {
-		duration = formatDuration(v.Duration)
-	}
-	age := humanizeAge(v.ModTime)
-	tags := formatTags(v.Tags)
-	if v.Err != nil {
-		duration = "!" + v.Err.Error()
-	}
-	return table.Row{v.Name, duration, age, tags}
+	return videoRowFor(v, defaultColumns)
 }
 
 func renderProgressBar(done, total, width int) string {
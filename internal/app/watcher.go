@@ -0,0 +1,125 @@
+package app
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// watchDebounce groups bursts of filesystem events (a download finishing,
+// a directory being copied) into one library update.
+const watchDebounce = 300 * time.Millisecond
+
+var errWatchUnsupported = errors.New("live library updates are not supported on this platform")
+
+type libraryChangeKind int
+
+const (
+	changeAdded libraryChangeKind = iota
+	changeRemoved
+	changeRenamed
+	// changeRescan asks for a full re-index because events were lost.
+	changeRescan
+)
+
+// libraryChange describes one video appearing, disappearing or moving.
+// Removals with dir set cover every video below path.
+type libraryChange struct {
+	kind    libraryChangeKind
+	path    string
+	oldPath string
+	dir     bool
+}
+
+// libraryWatcher forwards filesystem changes below the library root. The
+// platform backend feeds raw changes into it; they reach the Bubble Tea loop
+// in debounced batches through waitForLibraryChangeCmd.
+type libraryWatcher struct {
+	raw       chan libraryChange
+	batches   chan []libraryChange
+	done      chan struct{}
+	closeOnce sync.Once
+	closer    func() error
+}
+
+func newLibraryWatcherChannels(closer func() error) *libraryWatcher {
+	w := &libraryWatcher{
+		raw:     make(chan libraryChange, 64),
+		batches: make(chan []libraryChange),
+		done:    make(chan struct{}),
+		closer:  closer,
+	}
+	go w.coalesce()
+	return w
+}
+
+// send hands a change to the coalescer. It returns false once the watcher
+// has been closed.
+func (w *libraryWatcher) send(change libraryChange) bool {
+	select {
+	case w.raw <- change:
+		return true
+	case <-w.done:
+		return false
+	}
+}
+
+func (w *libraryWatcher) coalesce() {
+	var pending []libraryChange
+	var timer <-chan time.Time
+	for {
+		select {
+		case change := <-w.raw:
+			pending = append(pending, change)
+			timer = time.After(watchDebounce)
+		case <-timer:
+			select {
+			case w.batches <- pending:
+				pending = nil
+				timer = nil
+			case <-w.done:
+				return
+			}
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// Close stops the watcher. It is safe to call more than once.
+func (w *libraryWatcher) Close() error {
+	if w == nil {
+		return nil
+	}
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.done)
+		if w.closer != nil {
+			err = w.closer()
+		}
+	})
+	return err
+}
+
+func startWatcherCmd(roots ...string) tea.Cmd {
+	return func() tea.Msg {
+		watcher, err := newLibraryWatcher(roots...)
+		return watcherStartedMsg{watcher: watcher, err: err}
+	}
+}
+
+func waitForLibraryChangeCmd(w *libraryWatcher) tea.Cmd {
+	if w == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		select {
+		case changes := <-w.batches:
+			return libraryChangedMsg{changes: changes}
+		case <-w.done:
+			return nil
+		}
+	}
+}
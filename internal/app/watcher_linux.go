@@ -0,0 +1,246 @@
+//go:build linux
+
+package app
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_CLOSE_WRITE | unix.IN_DELETE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO
+
+type watchedDir struct {
+	display  string
+	resolved string
+}
+
+// inotifyBackend watches every directory below the root, following
+// symlinked directories the same way traverseVideoPaths does. Paths in the
+// emitted changes use the display path, so they match collectVideoPaths.
+type inotifyBackend struct {
+	fd      int
+	file    *os.File
+	dirs    map[int]watchedDir
+	visited map[string]struct{}
+}
+
+// newLibraryWatcher watches every root that is currently reachable; roots
+// that are missing, such as an unmounted share, are skipped.
+func newLibraryWatcher(roots ...string) (*libraryWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, fmt.Errorf("inotify: %w", err)
+	}
+	b := &inotifyBackend{
+		fd:      fd,
+		file:    os.NewFile(uintptr(fd), "inotify"),
+		dirs:    make(map[int]watchedDir),
+		visited: make(map[string]struct{}),
+	}
+	for _, root := range roots {
+		if _, err := os.Stat(root); err != nil && len(roots) > 1 {
+			continue
+		}
+		if _, err := b.addTree(root); err != nil {
+			b.file.Close()
+			return nil, err
+		}
+	}
+	w := newLibraryWatcherChannels(b.file.Close)
+	go b.run(w)
+	return w, nil
+}
+
+// addTree watches displayPath and everything below it and returns the
+// videos found there.
+func (b *inotifyBackend) addTree(displayPath string) ([]string, error) {
+	var videos []string
+	visit := videoTreeVisitor{
+		dir: func(display, resolved string) error {
+			wd, err := unix.InotifyAddWatch(b.fd, resolved, inotifyMask)
+			if err != nil {
+				return fmt.Errorf("watch %s: %w", display, err)
+			}
+			b.dirs[wd] = watchedDir{display: display, resolved: resolved}
+			return nil
+		},
+		video: func(path string) { videos = append(videos, path) },
+	}
+	err := walkVideoTree(displayPath, displayPath, b.visited, visit)
+	return videos, err
+}
+
+// removeTree drops the watches of displayPath and its subdirectories.
+func (b *inotifyBackend) removeTree(displayPath string) {
+	prefix := displayPath + string(filepath.Separator)
+	for wd, dir := range b.dirs {
+		if dir.display == displayPath || strings.HasPrefix(dir.display, prefix) {
+			_, _ = unix.InotifyRmWatch(b.fd, uint32(wd))
+			b.forget(wd)
+		}
+	}
+}
+
+func (b *inotifyBackend) forget(wd int) {
+	if dir, ok := b.dirs[wd]; ok {
+		delete(b.visited, dir.resolved)
+		delete(b.dirs, wd)
+	}
+}
+
+func (b *inotifyBackend) run(w *libraryWatcher) {
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := b.file.Read(buf)
+		if err != nil {
+			return
+		}
+		for _, change := range b.handle(parseInotifyEvents(buf[:n])) {
+			if !w.send(change) {
+				return
+			}
+		}
+	}
+}
+
+type inotifyEvent struct {
+	wd     int
+	mask   uint32
+	cookie uint32
+	name   string
+}
+
+func parseInotifyEvents(buf []byte) []inotifyEvent {
+	var events []inotifyEvent
+	for offset := 0; offset+unix.SizeofInotifyEvent <= len(buf); {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		start := offset + unix.SizeofInotifyEvent
+		end := start + int(raw.Len)
+		if end > len(buf) {
+			break
+		}
+		name := strings.TrimRight(string(buf[start:end]), "\x00")
+		events = append(events, inotifyEvent{wd: int(raw.Wd), mask: raw.Mask, cookie: raw.Cookie, name: name})
+		offset = end
+	}
+	return events
+}
+
+type pendingMove struct {
+	path string
+	dir  bool
+}
+
+// handle translates one read's worth of events. Renames arrive as a
+// MOVED_FROM/MOVED_TO pair sharing a cookie; a MOVED_FROM without partner
+// means the entry left the library.
+func (b *inotifyBackend) handle(events []inotifyEvent) []libraryChange {
+	var changes []libraryChange
+	moves := make(map[uint32]pendingMove)
+	var order []uint32
+	for _, ev := range events {
+		if ev.mask&unix.IN_Q_OVERFLOW != 0 {
+			changes = append(changes, libraryChange{kind: changeRescan})
+			continue
+		}
+		if ev.mask&unix.IN_IGNORED != 0 {
+			b.forget(ev.wd)
+			continue
+		}
+		dir, ok := b.dirs[ev.wd]
+		if !ok || ev.name == "" {
+			continue
+		}
+		path := filepath.Join(dir.display, ev.name)
+		isDir := ev.mask&unix.IN_ISDIR != 0
+		switch {
+		case ev.mask&unix.IN_MOVED_FROM != 0:
+			moves[ev.cookie] = pendingMove{path: path, dir: isDir}
+			order = append(order, ev.cookie)
+		case ev.mask&unix.IN_MOVED_TO != 0:
+			from, paired := moves[ev.cookie]
+			delete(moves, ev.cookie)
+			switch {
+			case paired && !isDir && isVideo(from.path) && isVideo(path):
+				changes = append(changes, libraryChange{kind: changeRenamed, path: path, oldPath: from.path})
+			default:
+				if paired {
+					changes = append(changes, b.removed(from.path, from.dir)...)
+				}
+				changes = append(changes, b.added(path, isDir)...)
+			}
+		case ev.mask&unix.IN_CREATE != 0:
+			if isDir {
+				changes = append(changes, b.added(path, true)...)
+			} else if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+				// Regular files are picked up once written (IN_CLOSE_WRITE);
+				// symlinks are complete as soon as they exist.
+				changes = append(changes, b.addedSymlink(path)...)
+			}
+		case ev.mask&unix.IN_CLOSE_WRITE != 0:
+			changes = append(changes, b.added(path, false)...)
+		case ev.mask&unix.IN_DELETE != 0:
+			changes = append(changes, b.removed(path, isDir)...)
+		}
+	}
+	for _, cookie := range order {
+		if from, ok := moves[cookie]; ok {
+			changes = append(changes, b.removed(from.path, from.dir)...)
+		}
+	}
+	return changes
+}
+
+func (b *inotifyBackend) added(path string, isDir bool) []libraryChange {
+	if !isDir {
+		if !isVideo(path) {
+			return nil
+		}
+		return []libraryChange{{kind: changeAdded, path: path}}
+	}
+	videos, _ := b.addTree(path)
+	changes := make([]libraryChange, 0, len(videos))
+	for _, video := range videos {
+		changes = append(changes, libraryChange{kind: changeAdded, path: video})
+	}
+	return changes
+}
+
+func (b *inotifyBackend) addedSymlink(path string) []libraryChange {
+	var videos []string
+	visit := videoTreeVisitor{
+		dir: func(display, resolved string) error {
+			wd, err := unix.InotifyAddWatch(b.fd, resolved, inotifyMask)
+			if err == nil {
+				b.dirs[wd] = watchedDir{display: display, resolved: resolved}
+			}
+			return err
+		},
+		video: func(p string) { videos = append(videos, p) },
+	}
+	_ = walkSymlink(path, path, b.visited, visit)
+	changes := make([]libraryChange, 0, len(videos))
+	for _, video := range videos {
+		changes = append(changes, libraryChange{kind: changeAdded, path: video})
+	}
+	return changes
+}
+
+func (b *inotifyBackend) removed(path string, isDir bool) []libraryChange {
+	if isDir {
+		b.removeTree(path)
+		return []libraryChange{{kind: changeRemoved, path: path, dir: true}}
+	}
+	if !isVideo(path) {
+		// A removed symlink may have pointed at a directory.
+		b.removeTree(path)
+		return []libraryChange{{kind: changeRemoved, path: path, dir: true}}
+	}
+	return []libraryChange{{kind: changeRemoved, path: path}}
+}
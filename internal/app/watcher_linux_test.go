@@ -0,0 +1,122 @@
+//go:build linux
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func nextLibraryChanges(t *testing.T, w *libraryWatcher) []libraryChange {
+	t.Helper()
+	select {
+	case changes := <-w.batches:
+		return changes
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for library changes")
+	}
+	return nil
+}
+
+func findChange(changes []libraryChange, kind libraryChangeKind, path string) bool {
+	for _, change := range changes {
+		if change.kind == kind && change.path == path {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLibraryWatcherReportsAddRenameRemove(t *testing.T) {
+	root := t.TempDir()
+	w, err := newLibraryWatcher(root)
+	if err != nil {
+		t.Fatalf("newLibraryWatcher: %v", err)
+	}
+	defer w.Close()
+
+	clip := filepath.Join(root, "clip.mp4")
+	if err := os.WriteFile(clip, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	changes := nextLibraryChanges(t, w)
+	if len(changes) != 1 || !findChange(changes, changeAdded, clip) {
+		t.Fatalf("expected only the video to be added, got %+v", changes)
+	}
+
+	renamed := filepath.Join(root, "flow.mp4")
+	if err := os.Rename(clip, renamed); err != nil {
+		t.Fatalf("rename: %v", err)
+	}
+	changes = nextLibraryChanges(t, w)
+	if len(changes) != 1 || changes[0].kind != changeRenamed || changes[0].oldPath != clip || changes[0].path != renamed {
+		t.Fatalf("expected rename, got %+v", changes)
+	}
+
+	if err := os.Remove(renamed); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	changes = nextLibraryChanges(t, w)
+	if !findChange(changes, changeRemoved, renamed) {
+		t.Fatalf("expected removal, got %+v", changes)
+	}
+}
+
+func TestLibraryWatcherFollowsNewDirectoriesAndSymlinks(t *testing.T) {
+	root := t.TempDir()
+	external := t.TempDir()
+	w, err := newLibraryWatcher(root)
+	if err != nil {
+		t.Fatalf("newLibraryWatcher: %v", err)
+	}
+	defer w.Close()
+
+	if err := os.WriteFile(filepath.Join(external, "nas.mkv"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	link := filepath.Join(root, "nas")
+	if err := os.Symlink(external, link); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	changes := nextLibraryChanges(t, w)
+	if !findChange(changes, changeAdded, filepath.Join(link, "nas.mkv")) {
+		t.Fatalf("expected video behind symlink, got %+v", changes)
+	}
+
+	// Files written into the linked directory later arrive under the link.
+	if err := os.WriteFile(filepath.Join(external, "later.mp4"), []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	changes = nextLibraryChanges(t, w)
+	if !findChange(changes, changeAdded, filepath.Join(link, "later.mp4")) {
+		t.Fatalf("expected display path below symlink, got %+v", changes)
+	}
+
+	sub := filepath.Join(root, "series")
+	if err := os.Mkdir(sub, 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// Whether the directory scan or the new watch sees the file first, it
+	// must be reported exactly under its display path.
+	episode := filepath.Join(sub, "day1.mp4")
+	if err := os.WriteFile(episode, []byte("x"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	changes = nextLibraryChanges(t, w)
+	if !findChange(changes, changeAdded, episode) {
+		t.Fatalf("expected video in new directory, got %+v", changes)
+	}
+
+	if err := os.RemoveAll(sub); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	changes = nextLibraryChanges(t, w)
+	if !findChange(changes, changeRemoved, sub) {
+		t.Fatalf("expected directory removal, got %+v", changes)
+	}
+}
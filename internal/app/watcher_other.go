@@ -0,0 +1,7 @@
+//go:build !linux
+
+package app
+
+func newLibraryWatcher(...string) (*libraryWatcher, error) {
+	return nil, errWatchUnsupported
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Config holds optional user preferences. Command-line flags take precedence
+// over every value read from the configuration file.
+type Config struct {
+	Player     string `json:"player,omitempty"`
+	Fullscreen bool   `json:"fullscreen,omitempty"`
+	Volume     int    `json:"volume,omitempty"`
+	// WeightedRandom makes the random pick favour highly rated videos.
+	WeightedRandom bool `json:"weighted_random,omitempty"`
+	// Columns lists the table columns in display order.
+	Columns []string `json:"columns,omitempty"`
+	// Roots lists the library roots; the first one holds positions,
+	// history and playlists.
+	Roots []Root `json:"roots,omitempty"`
+}
+
+// Root is a library directory with an optional label.
+type Root struct {
+	Label string `json:"label,omitempty"`
+	Path  string `json:"path"`
+}
+
+// DefaultPath returns the location of the user's configuration file.
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "yoga", "config.json"), nil
+}
+
+// Load reads the configuration stored at path. Missing files yield an empty
+// configuration.
+func Load(path string) (Config, error) {
+	var cfg Config
+	if path == "" {
+		return cfg, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return cfg, nil
+}
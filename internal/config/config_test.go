@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("Load missing: %v", err)
+	}
+	if cfg.Player != "" || cfg.Volume != 0 || cfg.Fullscreen {
+		t.Fatalf("expected zero config, got %+v", cfg)
+	}
+}
+
+func TestLoadParsesValues(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"player": "mpv", "fullscreen": true, "volume": 80}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if cfg.Player != "mpv" || !cfg.Fullscreen || cfg.Volume != 80 {
+		t.Fatalf("unexpected config %+v", cfg)
+	}
+}
+
+func TestLoadInvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("not json"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Fatal("expected parse error")
+	}
+}
+
+func TestDefaultPathUsesConfigDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	path, err := DefaultPath()
+	if err != nil {
+		t.Fatalf("DefaultPath: %v", err)
+	}
+	if path != filepath.Join(dir, "yoga", "config.json") {
+		t.Fatalf("unexpected path %s", path)
+	}
+}
@@ -32,6 +32,22 @@ func ResolveRootPath(input, defaultValue string) (string, error) {
 	return abs, nil
 }
 
+// AbsPath expands a leading ~ and makes input absolute without requiring the
+// path to exist. Secondary library roots use it so that an unmounted share
+// does not prevent start-up.
+func AbsPath(input string) (string, error) {
+	value := strings.TrimSpace(input)
+	expanded, err := expandPath(value)
+	if err != nil {
+		return "", fmt.Errorf("cannot expand root path %q: %w", value, err)
+	}
+	abs, err := filepath.Abs(expanded)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve root path %q: %w", expanded, err)
+	}
+	return abs, nil
+}
+
 func normalizeRootInput(input, defaultValue string) (value string, isDefault bool) {
 	trimmed := strings.TrimSpace(input)
 	if trimmed == "" {
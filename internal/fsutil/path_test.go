@@ -110,3 +110,15 @@ func TestEnsureRootExistsErrors(t *testing.T) {
 		t.Fatalf("expected error when creation not allowed")
 	}
 }
+
+func TestAbsPathDoesNotRequireExisting(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	got, err := AbsPath("~/nas/yoga")
+	if err != nil {
+		t.Fatalf("abs path: %v", err)
+	}
+	if want := filepath.Join(home, "nas", "yoga"); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+}
@@ -0,0 +1,170 @@
+package playlist
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const fileExt = ".json"
+
+// Playlist is a named, ordered list of video paths.
+type Playlist struct {
+	Name  string   `json:"name"`
+	Paths []string `json:"paths"`
+}
+
+// Entry describes a single track when exporting a playlist.
+type Entry struct {
+	Path     string
+	Title    string
+	Duration time.Duration
+}
+
+// Dir returns the directory holding the saved playlists of a library.
+func Dir(root string) string {
+	return filepath.Join(root, ".video_playlists")
+}
+
+// ValidateName rejects names that cannot be used as a file name.
+func ValidateName(name string) error {
+	trimmed := strings.TrimSpace(name)
+	if trimmed == "" {
+		return errors.New("playlist name must not be empty")
+	}
+	if trimmed == "." || trimmed == ".." || strings.ContainsAny(trimmed, `/\`) {
+		return fmt.Errorf("invalid playlist name %q", name)
+	}
+	return nil
+}
+
+// Save writes the playlist to dir, replacing an existing one of the same name.
+func Save(dir string, p Playlist) error {
+	if err := ValidateName(p.Name); err != nil {
+		return err
+	}
+	p.Name = strings.TrimSpace(p.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	payload, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, p.Name+fileExt), payload, 0o644)
+}
+
+// Load reads the named playlist from dir.
+func Load(dir, name string) (Playlist, error) {
+	if err := ValidateName(name); err != nil {
+		return Playlist{}, err
+	}
+	data, err := os.ReadFile(filepath.Join(dir, strings.TrimSpace(name)+fileExt))
+	if err != nil {
+		return Playlist{}, err
+	}
+	var p Playlist
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Playlist{}, err
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSpace(name)
+	}
+	return p, nil
+}
+
+// List returns the names of all playlists saved in dir, sorted.
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != fileExt {
+			continue
+		}
+		names = append(names, strings.TrimSuffix(entry.Name(), fileExt))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// WriteM3U writes entries as an extended M3U playlist.
+func WriteM3U(w io.Writer, entries []Entry) error {
+	if _, err := io.WriteString(w, "#EXTM3U\n"); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		seconds := -1
+		if entry.Duration > 0 {
+			seconds = int(entry.Duration.Round(time.Second) / time.Second)
+		}
+		if _, err := fmt.Fprintf(w, "#EXTINF:%d,%s\n%s\n", seconds, entryTitle(entry), entry.Path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type xspfPlaylist struct {
+	XMLName xml.Name    `xml:"playlist"`
+	Version string      `xml:"version,attr"`
+	XMLNS   string      `xml:"xmlns,attr"`
+	Title   string      `xml:"title,omitempty"`
+	Tracks  []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location"`
+	Title    string `xml:"title,omitempty"`
+	Duration int64  `xml:"duration,omitempty"`
+}
+
+// WriteXSPF writes entries as an XSPF playlist titled title.
+func WriteXSPF(w io.Writer, title string, entries []Entry) error {
+	doc := xspfPlaylist{Version: "1", XMLNS: "http://xspf.org/ns/0/", Title: title}
+	for _, entry := range entries {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: fileURI(entry.Path),
+			Title:    entryTitle(entry),
+			Duration: entry.Duration.Milliseconds(),
+		})
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func entryTitle(entry Entry) string {
+	if entry.Title != "" {
+		return entry.Title
+	}
+	return filepath.Base(entry.Path)
+}
+
+func fileURI(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}).String()
+}
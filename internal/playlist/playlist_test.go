@@ -0,0 +1,85 @@
+package playlist
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveLoadAndList(t *testing.T) {
+	dir := t.TempDir()
+	p := Playlist{Name: "morning", Paths: []string{"/videos/a.mp4", "/videos/b.mp4"}}
+	if err := Save(dir, p); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	if err := Save(dir, Playlist{Name: "evening"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	loaded, err := Load(dir, "morning")
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(loaded.Paths) != 2 || loaded.Paths[1] != "/videos/b.mp4" {
+		t.Fatalf("unexpected playlist %+v", loaded)
+	}
+	names, err := List(dir)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if strings.Join(names, ",") != "evening,morning" {
+		t.Fatalf("unexpected names %v", names)
+	}
+}
+
+func TestListMissingDir(t *testing.T) {
+	names, err := List("/no/such/dir")
+	if err != nil || names != nil {
+		t.Fatalf("expected empty list, got %v %v", names, err)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"", "  ", "..", "a/b"} {
+		if err := ValidateName(name); err == nil {
+			t.Fatalf("expected %q to be rejected", name)
+		}
+	}
+	if err := ValidateName("Sunday flow"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestWriteM3U(t *testing.T) {
+	var buf bytes.Buffer
+	entries := []Entry{
+		{Path: "/videos/a.mp4", Title: "Sun flow", Duration: 90 * time.Second},
+		{Path: "/videos/b.mp4"},
+	}
+	if err := WriteM3U(&buf, entries); err != nil {
+		t.Fatalf("WriteM3U: %v", err)
+	}
+	want := "#EXTM3U\n#EXTINF:90,Sun flow\n/videos/a.mp4\n#EXTINF:-1,b.mp4\n/videos/b.mp4\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected m3u:\n%s", buf.String())
+	}
+}
+
+func TestWriteXSPF(t *testing.T) {
+	var buf bytes.Buffer
+	entries := []Entry{{Path: "/videos/hip flow.mp4", Title: "Hips & back", Duration: 2 * time.Second}}
+	if err := WriteXSPF(&buf, "morning", entries); err != nil {
+		t.Fatalf("WriteXSPF: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		`<playlist version="1" xmlns="http://xspf.org/ns/0/">`,
+		"<location>file:///videos/hip%20flow.mp4</location>",
+		"<title>Hips &amp; back</title>",
+		"<duration>2000</duration>",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %s in:\n%s", want, out)
+		}
+	}
+}
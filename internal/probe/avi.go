@@ -0,0 +1,173 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+type riffChunk struct {
+	id      string
+	listID  string // set for LIST chunks
+	dataPos int64
+	size    int64
+}
+
+func readRIFFChunk(r io.ReadSeeker, pos, limit int64) (riffChunk, error) {
+	header := make([]byte, 8)
+	if err := readAt(r, pos, header); err != nil {
+		return riffChunk{}, err
+	}
+	chunk := riffChunk{
+		id:      string(header[0:4]),
+		dataPos: pos + 8,
+		size:    int64(binary.LittleEndian.Uint32(header[4:8])),
+	}
+	if chunk.dataPos+chunk.size > limit {
+		chunk.size = limit - chunk.dataPos
+	}
+	if chunk.id == "LIST" || chunk.id == "RIFF" {
+		if chunk.size < 4 {
+			return riffChunk{}, fmt.Errorf("avi: truncated %s chunk", chunk.id)
+		}
+		kind := make([]byte, 4)
+		if err := readAt(r, chunk.dataPos, kind); err != nil {
+			return riffChunk{}, err
+		}
+		chunk.listID = string(kind)
+	}
+	return chunk, nil
+}
+
+// next returns the offset of the chunk following c; chunks are word aligned.
+func (c riffChunk) next() int64 {
+	return c.dataPos + c.size + c.size%2
+}
+
+func probeAVI(r io.ReadSeeker, size int64) (Info, error) {
+	riff, err := readRIFFChunk(r, 0, size)
+	if err != nil {
+		return Info{}, err
+	}
+	end := riff.dataPos + riff.size
+	for pos := riff.dataPos + 4; pos+8 <= end; {
+		chunk, err := readRIFFChunk(r, pos, end)
+		if err != nil {
+			return Info{}, err
+		}
+		if chunk.id == "LIST" && chunk.listID == "hdrl" {
+			return parseHDRL(r, chunk)
+		}
+		pos = chunk.next()
+	}
+	return Info{}, errors.New("avi: hdrl list not found")
+}
+
+func parseHDRL(r io.ReadSeeker, hdrl riffChunk) (Info, error) {
+	end := hdrl.dataPos + hdrl.size
+	var info Info
+	var microSecPerFrame, totalFrames, extendedFrames uint32
+	found := false
+	for pos := hdrl.dataPos + 4; pos+8 <= end; {
+		chunk, err := readRIFFChunk(r, pos, end)
+		if err != nil {
+			return Info{}, err
+		}
+		switch {
+		case chunk.id == "avih":
+			header := make([]byte, 40)
+			if err := readAt(r, chunk.dataPos, header); err != nil {
+				return Info{}, fmt.Errorf("avi: avih: %w", err)
+			}
+			microSecPerFrame = binary.LittleEndian.Uint32(header[0:4])
+			totalFrames = binary.LittleEndian.Uint32(header[16:20])
+			info.Width = int(binary.LittleEndian.Uint32(header[32:36]))
+			info.Height = int(binary.LittleEndian.Uint32(header[36:40]))
+			found = true
+		case chunk.id == "LIST" && chunk.listID == "strl":
+			// Stream details are best effort; the duration is what matters.
+			_ = parseSTRL(r, chunk, &info)
+		case chunk.id == "LIST" && chunk.listID == "odml":
+			// OpenDML files larger than 1 GiB record the real frame count in
+			// the dmlh header because avih only covers the first RIFF chunk.
+			frames, err := readDMLH(r, chunk)
+			if err == nil {
+				extendedFrames = frames
+			}
+		}
+		pos = chunk.next()
+	}
+	if !found {
+		return Info{}, errors.New("avi: avih header not found")
+	}
+	if extendedFrames > totalFrames {
+		totalFrames = extendedFrames
+	}
+	info.Duration = time.Duration(uint64(microSecPerFrame)*uint64(totalFrames)) * time.Microsecond
+	return info, nil
+}
+
+// parseSTRL reads the stream header (strh) and format (strf) chunks to find
+// the codec of the first video and audio stream.
+func parseSTRL(r io.ReadSeeker, strl riffChunk, info *Info) error {
+	end := strl.dataPos + strl.size
+	streamType := ""
+	for pos := strl.dataPos + 4; pos+8 <= end; {
+		chunk, err := readRIFFChunk(r, pos, end)
+		if err != nil {
+			return err
+		}
+		switch chunk.id {
+		case "strh":
+			buf := make([]byte, 8)
+			if err := readAt(r, chunk.dataPos, buf); err != nil {
+				return err
+			}
+			streamType = string(buf[0:4])
+		case "strf":
+			switch streamType {
+			case "vids":
+				// BITMAPINFOHEADER: biCompression follows size, width,
+				// height, planes and bit count.
+				buf := make([]byte, 4)
+				if err := readAt(r, chunk.dataPos+16, buf); err != nil {
+					return err
+				}
+				if info.VideoCodec == "" {
+					info.VideoCodec = aviVideoCodecName(string(buf))
+				}
+			case "auds":
+				buf := make([]byte, 2)
+				if err := readAt(r, chunk.dataPos, buf); err != nil {
+					return err
+				}
+				if info.AudioCodec == "" {
+					info.AudioCodec = aviAudioCodecName(binary.LittleEndian.Uint16(buf))
+				}
+			}
+		}
+		pos = chunk.next()
+	}
+	return nil
+}
+
+func readDMLH(r io.ReadSeeker, odml riffChunk) (uint32, error) {
+	end := odml.dataPos + odml.size
+	for pos := odml.dataPos + 4; pos+8 <= end; {
+		chunk, err := readRIFFChunk(r, pos, end)
+		if err != nil {
+			return 0, err
+		}
+		if chunk.id == "dmlh" {
+			buf := make([]byte, 4)
+			if err := readAt(r, chunk.dataPos, buf); err != nil {
+				return 0, err
+			}
+			return binary.LittleEndian.Uint32(buf), nil
+		}
+		pos = chunk.next()
+	}
+	return 0, errors.New("avi: dmlh not found")
+}
@@ -0,0 +1,106 @@
+package probe
+
+import "strings"
+
+// Codec names follow ffprobe's codec_name so that natively probed and
+// ffprobe-probed files display the same values.
+
+var mp4Codecs = map[string]string{
+	"avc1": "h264",
+	"avc3": "h264",
+	"hvc1": "hevc",
+	"hev1": "hevc",
+	"av01": "av1",
+	"vp09": "vp9",
+	"mp4v": "mpeg4",
+	"mp4a": "aac",
+	"ac-3": "ac3",
+	"ec-3": "eac3",
+	"opus": "opus",
+	"fLaC": "flac",
+	".mp3": "mp3",
+	"apcn": "prores",
+	"apch": "prores",
+	"jpeg": "mjpeg",
+}
+
+var matroskaCodecs = map[string]string{
+	"V_MPEG4/ISO/AVC":  "h264",
+	"V_MPEGH/ISO/HEVC": "hevc",
+	"V_AV1":            "av1",
+	"V_VP8":            "vp8",
+	"V_VP9":            "vp9",
+	"V_MPEG4/ISO/ASP":  "mpeg4",
+	"V_MPEG2":          "mpeg2video",
+	"V_THEORA":         "theora",
+	"A_AAC":            "aac",
+	"A_AC3":            "ac3",
+	"A_EAC3":           "eac3",
+	"A_DTS":            "dts",
+	"A_FLAC":           "flac",
+	"A_MPEG/L3":        "mp3",
+	"A_OPUS":           "opus",
+	"A_VORBIS":         "vorbis",
+	"A_PCM/INT/LIT":    "pcm_s16le",
+}
+
+var aviVideoCodecs = map[string]string{
+	"XVID": "mpeg4",
+	"DIVX": "mpeg4",
+	"DX50": "mpeg4",
+	"FMP4": "mpeg4",
+	"MP4V": "mpeg4",
+	"H264": "h264",
+	"X264": "h264",
+	"AVC1": "h264",
+	"HEVC": "hevc",
+	"MJPG": "mjpeg",
+	"WMV3": "wmv3",
+}
+
+var aviAudioCodecs = map[uint16]string{
+	0x0001: "pcm_s16le",
+	0x0050: "mp2",
+	0x0055: "mp3",
+	0x00FF: "aac",
+	0x0161: "wmav2",
+	0x1610: "aac",
+	0x2000: "ac3",
+	0x2001: "dts",
+}
+
+func mp4CodecName(fourcc string) string {
+	if name, ok := mp4Codecs[fourcc]; ok {
+		return name
+	}
+	return strings.TrimSpace(strings.ToLower(fourcc))
+}
+
+func matroskaCodecName(id string) string {
+	if name, ok := matroskaCodecs[id]; ok {
+		return name
+	}
+	// A_AAC/MPEG4/LC and friends carry the profile after the codec.
+	for prefix, name := range matroskaCodecs {
+		if strings.HasPrefix(id, prefix+"/") {
+			return name
+		}
+	}
+	_, rest, found := strings.Cut(id, "_")
+	if !found {
+		return strings.ToLower(id)
+	}
+	return strings.ToLower(rest)
+}
+
+func aviVideoCodecName(fourcc string) string {
+	upper := strings.ToUpper(strings.TrimRight(fourcc, "\x00 "))
+	if name, ok := aviVideoCodecs[upper]; ok {
+		return name
+	}
+	return strings.ToLower(upper)
+}
+
+func aviAudioCodecName(tag uint16) string {
+	return aviAudioCodecs[tag]
+}
@@ -0,0 +1,82 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FFprobeTimeout bounds a single ffprobe invocation.
+const FFprobeTimeout = 15 * time.Second
+
+type ffprobeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+		Tags      struct {
+			Language string `json:"language"`
+		} `json:"tags"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+		BitRate  string `json:"bit_rate"`
+	} `json:"format"`
+}
+
+// FFprobe runs the external ffprobe binary on path. It handles any format
+// ffmpeg understands and is used when Probe returns an error.
+func FFprobe(path string) (Info, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), FFprobeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error",
+		"-show_entries", "format=duration,bit_rate:stream=codec_type,codec_name,width,height:stream_tags=language",
+		"-of", "json", path)
+	out, err := cmd.Output()
+	if err != nil {
+		return Info{}, err
+	}
+	return parseFFprobeOutput(out)
+}
+
+func parseFFprobeOutput(data []byte) (Info, error) {
+	var out ffprobeOutput
+	if err := json.Unmarshal(data, &out); err != nil {
+		return Info{}, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+	raw := strings.TrimSpace(out.Format.Duration)
+	if raw == "" || raw == "N/A" {
+		return Info{}, errors.New("empty duration")
+	}
+	seconds, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{Duration: time.Duration(seconds * float64(time.Second))}
+	if bitrate, err := strconv.ParseInt(out.Format.BitRate, 10, 64); err == nil {
+		info.Bitrate = bitrate
+	}
+	for _, stream := range out.Streams {
+		switch stream.CodecType {
+		case "video":
+			if info.VideoCodec == "" {
+				info.VideoCodec = stream.CodecName
+				info.Width = stream.Width
+				info.Height = stream.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = stream.CodecName
+			}
+			info.addAudioLanguage(stream.Tags.Language)
+		}
+	}
+	return info, nil
+}
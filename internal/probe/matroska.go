@@ -0,0 +1,328 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	ebmlIDHeader        = 0x1A45DFA3
+	ebmlIDSegment       = 0x18538067
+	ebmlIDInfo          = 0x1549A966
+	ebmlIDTimecodeScale = 0x2AD7B1
+	ebmlIDDuration      = 0x4489
+	ebmlIDCluster       = 0x1F43B675
+	ebmlIDTracks        = 0x1654AE6B
+	ebmlIDTrackEntry    = 0xAE
+	ebmlIDTrackType     = 0x83
+	ebmlIDCodecID       = 0x86
+	ebmlIDLanguage      = 0x22B59C
+	ebmlIDLanguageBCP47 = 0x22B59D
+	ebmlIDVideo         = 0xE0
+	ebmlIDPixelWidth    = 0xB0
+	ebmlIDPixelHeight   = 0xBA
+
+	matroskaTrackVideo = 1
+	matroskaTrackAudio = 2
+
+	defaultTimecodeScale = 1000000 // nanoseconds per tick
+	ebmlUnknownSize      = -1
+)
+
+type ebmlElement struct {
+	id      uint32
+	dataPos int64
+	size    int64
+}
+
+func (e ebmlElement) end(limit int64) int64 {
+	if e.size == ebmlUnknownSize {
+		return limit
+	}
+	return e.dataPos + e.size
+}
+
+func probeMatroska(r io.ReadSeeker, size int64) (Info, error) {
+	for pos := int64(0); pos < size; {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			return Info{}, err
+		}
+		if el.id == ebmlIDSegment {
+			return probeSegment(r, el.dataPos, el.end(size))
+		}
+		if el.size == ebmlUnknownSize {
+			return Info{}, errors.New("matroska: unknown-size element before segment")
+		}
+		pos = el.end(size)
+	}
+	return Info{}, errors.New("matroska: segment not found")
+}
+
+func probeSegment(r io.ReadSeeker, start, end int64) (Info, error) {
+	var info Info
+	foundInfo, foundTracks := false, false
+	for pos := start; pos < end && !(foundInfo && foundTracks); {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			if foundInfo {
+				break
+			}
+			return Info{}, err
+		}
+		switch el.id {
+		case ebmlIDInfo:
+			duration, err := parseSegmentInfo(r, el.dataPos, el.end(end))
+			if err != nil {
+				return Info{}, err
+			}
+			info.Duration = duration
+			foundInfo = true
+		case ebmlIDTracks:
+			// Track details are best effort; the duration is what matters.
+			_ = parseTracks(r, el.dataPos, el.end(end), &info)
+			foundTracks = true
+		case ebmlIDCluster:
+			// Info and Tracks precede the media data in every sane file.
+			pos = end
+			continue
+		}
+		if el.size == ebmlUnknownSize {
+			break
+		}
+		pos = el.end(end)
+	}
+	if !foundInfo {
+		return Info{}, errors.New("matroska: segment info not found")
+	}
+	return info, nil
+}
+
+func parseSegmentInfo(r io.ReadSeeker, start, end int64) (time.Duration, error) {
+	scale := uint64(defaultTimecodeScale)
+	var ticks float64
+	for pos := start; pos < end; {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			return 0, err
+		}
+		switch el.id {
+		case ebmlIDTimecodeScale:
+			value, err := readEBMLUint(r, el)
+			if err != nil {
+				return 0, err
+			}
+			if value > 0 {
+				scale = value
+			}
+		case ebmlIDDuration:
+			value, err := readEBMLFloat(r, el)
+			if err != nil {
+				return 0, err
+			}
+			ticks = value
+		}
+		pos = el.end(end)
+	}
+	return time.Duration(ticks * float64(scale)), nil
+}
+
+type matroskaTrack struct {
+	kind     uint64
+	codec    string
+	language string
+	width    int
+	height   int
+}
+
+func parseTracks(r io.ReadSeeker, start, end int64, info *Info) error {
+	for pos := start; pos < end; {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			return err
+		}
+		if el.id == ebmlIDTrackEntry {
+			track, err := parseTrackEntry(r, el.dataPos, el.end(end))
+			if err != nil {
+				return err
+			}
+			applyMatroskaTrack(info, track)
+		}
+		pos = el.end(end)
+	}
+	return nil
+}
+
+func applyMatroskaTrack(info *Info, track matroskaTrack) {
+	switch track.kind {
+	case matroskaTrackVideo:
+		if info.VideoCodec == "" {
+			info.VideoCodec = matroskaCodecName(track.codec)
+			info.Width = track.width
+			info.Height = track.height
+		}
+	case matroskaTrackAudio:
+		if info.AudioCodec == "" {
+			info.AudioCodec = matroskaCodecName(track.codec)
+		}
+		info.addAudioLanguage(track.language)
+	}
+}
+
+func parseTrackEntry(r io.ReadSeeker, start, end int64) (matroskaTrack, error) {
+	// Tracks without a Language element are English per the specification.
+	track := matroskaTrack{language: "eng"}
+	bcp47 := ""
+	for pos := start; pos < end; {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			return matroskaTrack{}, err
+		}
+		switch el.id {
+		case ebmlIDTrackType:
+			track.kind, err = readEBMLUint(r, el)
+		case ebmlIDCodecID:
+			track.codec, err = readEBMLString(r, el)
+		case ebmlIDLanguage:
+			track.language, err = readEBMLString(r, el)
+		case ebmlIDLanguageBCP47:
+			bcp47, err = readEBMLString(r, el)
+		case ebmlIDVideo:
+			err = parseTrackVideo(r, el.dataPos, el.end(end), &track)
+		}
+		if err != nil {
+			return matroskaTrack{}, err
+		}
+		pos = el.end(end)
+	}
+	if bcp47 != "" {
+		track.language = bcp47
+	}
+	return track, nil
+}
+
+func parseTrackVideo(r io.ReadSeeker, start, end int64, track *matroskaTrack) error {
+	for pos := start; pos < end; {
+		el, err := readEBMLElement(r, pos)
+		if err != nil {
+			return err
+		}
+		switch el.id {
+		case ebmlIDPixelWidth, ebmlIDPixelHeight:
+			value, err := readEBMLUint(r, el)
+			if err != nil {
+				return err
+			}
+			if el.id == ebmlIDPixelWidth {
+				track.width = int(value)
+			} else {
+				track.height = int(value)
+			}
+		}
+		pos = el.end(end)
+	}
+	return nil
+}
+
+func readEBMLElement(r io.ReadSeeker, pos int64) (ebmlElement, error) {
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return ebmlElement{}, err
+	}
+	id, idLen, err := readVint(r, true)
+	if err != nil {
+		return ebmlElement{}, fmt.Errorf("matroska: element id: %w", err)
+	}
+	size, sizeLen, err := readVint(r, false)
+	if err != nil {
+		return ebmlElement{}, fmt.Errorf("matroska: element size: %w", err)
+	}
+	el := ebmlElement{id: uint32(id), dataPos: pos + int64(idLen+sizeLen), size: int64(size)}
+	if size == vintUnknown(sizeLen) {
+		el.size = ebmlUnknownSize
+	}
+	return el, nil
+}
+
+// readVint reads an EBML variable-length integer. IDs keep their length
+// marker bit, sizes do not.
+func readVint(r io.Reader, keepMarker bool) (uint64, int, error) {
+	first := make([]byte, 1)
+	if _, err := io.ReadFull(r, first); err != nil {
+		return 0, 0, err
+	}
+	length := 1
+	for mask := byte(0x80); length <= 8 && first[0]&mask == 0; mask >>= 1 {
+		length++
+	}
+	if length > 8 {
+		return 0, 0, errors.New("invalid variable-length integer")
+	}
+	value := uint64(first[0])
+	if !keepMarker {
+		value &= uint64(0xFF >> length)
+	}
+	rest := make([]byte, length-1)
+	if _, err := io.ReadFull(r, rest); err != nil {
+		return 0, 0, err
+	}
+	for _, b := range rest {
+		value = value<<8 | uint64(b)
+	}
+	return value, length, nil
+}
+
+func vintUnknown(length int) uint64 {
+	return 1<<(7*uint(length)) - 1
+}
+
+func readEBMLPayload(r io.ReadSeeker, el ebmlElement, max int64) ([]byte, error) {
+	if el.size < 0 || el.size > max {
+		return nil, fmt.Errorf("matroska: element 0x%X has unexpected size %d", el.id, el.size)
+	}
+	buf := make([]byte, el.size)
+	if err := readAt(r, el.dataPos, buf); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+func readEBMLUint(r io.ReadSeeker, el ebmlElement) (uint64, error) {
+	buf, err := readEBMLPayload(r, el, 8)
+	if err != nil {
+		return 0, err
+	}
+	var value uint64
+	for _, b := range buf {
+		value = value<<8 | uint64(b)
+	}
+	return value, nil
+}
+
+func readEBMLString(r io.ReadSeeker, el ebmlElement) (string, error) {
+	buf, err := readEBMLPayload(r, el, 256)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(buf), "\x00"), nil
+}
+
+func readEBMLFloat(r io.ReadSeeker, el ebmlElement) (float64, error) {
+	buf, err := readEBMLPayload(r, el, 8)
+	if err != nil {
+		return 0, err
+	}
+	switch len(buf) {
+	case 0:
+		return 0, nil
+	case 4:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(buf))), nil
+	case 8:
+		return math.Float64frombits(binary.BigEndian.Uint64(buf)), nil
+	}
+	return 0, fmt.Errorf("matroska: invalid float size %d", len(buf))
+}
@@ -0,0 +1,223 @@
+package probe
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+type mp4Box struct {
+	kind       string
+	payloadPos int64
+	payloadLen int64
+}
+
+// readMP4Box reads the box header at pos. limit is the end of the enclosing
+// box (or the file).
+func readMP4Box(r io.ReadSeeker, pos, limit int64) (mp4Box, error) {
+	header := make([]byte, 8)
+	if err := readAt(r, pos, header); err != nil {
+		return mp4Box{}, err
+	}
+	size := int64(binary.BigEndian.Uint32(header[0:4]))
+	box := mp4Box{kind: string(header[4:8])}
+	headerLen := int64(8)
+	switch size {
+	case 0:
+		size = limit - pos
+	case 1:
+		large := make([]byte, 8)
+		if err := readAt(r, pos+8, large); err != nil {
+			return mp4Box{}, err
+		}
+		size = int64(binary.BigEndian.Uint64(large))
+		headerLen = 16
+	}
+	if size < headerLen || pos+size > limit {
+		return mp4Box{}, fmt.Errorf("mp4: invalid size for box %q", box.kind)
+	}
+	box.payloadPos = pos + headerLen
+	box.payloadLen = size - headerLen
+	return box, nil
+}
+
+// findMP4Box returns the first child of the given kind within [start, end).
+func findMP4Box(r io.ReadSeeker, start, end int64, kind string) (mp4Box, error) {
+	for pos := start; pos+8 <= end; {
+		box, err := readMP4Box(r, pos, end)
+		if err != nil {
+			return mp4Box{}, err
+		}
+		if box.kind == kind {
+			return box, nil
+		}
+		pos = box.payloadPos + box.payloadLen
+	}
+	return mp4Box{}, fmt.Errorf("mp4: %s box not found", kind)
+}
+
+func probeMP4(r io.ReadSeeker, size int64) (Info, error) {
+	moov, err := findMP4Box(r, 0, size, "moov")
+	if err != nil {
+		return Info{}, err
+	}
+	moovEnd := moov.payloadPos + moov.payloadLen
+	mvhd, err := findMP4Box(r, moov.payloadPos, moovEnd, "mvhd")
+	if err != nil {
+		return Info{}, err
+	}
+	duration, err := parseMVHD(r, mvhd)
+	if err != nil {
+		return Info{}, err
+	}
+	info := Info{Duration: duration}
+	for pos := moov.payloadPos; pos+8 <= moovEnd; {
+		box, err := readMP4Box(r, pos, moovEnd)
+		if err != nil {
+			return Info{}, err
+		}
+		if box.kind == "trak" {
+			// Track details are best effort; the duration is what matters.
+			_ = parseMP4Track(r, box, &info)
+		}
+		pos = box.payloadPos + box.payloadLen
+	}
+	return info, nil
+}
+
+// parseMP4Track reads trak/mdia/{mdhd,hdlr,minf/stbl/stsd} and records the
+// first video and audio track's codec plus the audio language.
+func parseMP4Track(r io.ReadSeeker, trak mp4Box, info *Info) error {
+	mdia, err := findMP4Box(r, trak.payloadPos, trak.payloadPos+trak.payloadLen, "mdia")
+	if err != nil {
+		return err
+	}
+	mdiaEnd := mdia.payloadPos + mdia.payloadLen
+	hdlr, err := findMP4Box(r, mdia.payloadPos, mdiaEnd, "hdlr")
+	if err != nil {
+		return err
+	}
+	handler := make([]byte, 4)
+	if err := readAt(r, hdlr.payloadPos+8, handler); err != nil {
+		return err
+	}
+	kind := string(handler)
+	if kind != "vide" && kind != "soun" {
+		return nil
+	}
+	entry, err := mp4SampleEntry(r, mdia)
+	if err != nil {
+		return err
+	}
+	codec := make([]byte, 4)
+	if err := readAt(r, entry.payloadPos-4, codec); err != nil {
+		return err
+	}
+	if kind == "vide" {
+		if info.VideoCodec != "" {
+			return nil
+		}
+		info.VideoCodec = mp4CodecName(string(codec))
+		// VisualSampleEntry: 6 reserved, 2 data ref index, 16 pre-defined,
+		// then 16-bit width and height.
+		dims := make([]byte, 4)
+		if err := readAt(r, entry.payloadPos+24, dims); err != nil {
+			return err
+		}
+		info.Width = int(binary.BigEndian.Uint16(dims[0:2]))
+		info.Height = int(binary.BigEndian.Uint16(dims[2:4]))
+		return nil
+	}
+	if info.AudioCodec == "" {
+		info.AudioCodec = mp4CodecName(string(codec))
+	}
+	if mdhd, err := findMP4Box(r, mdia.payloadPos, mdiaEnd, "mdhd"); err == nil {
+		if lang, err := parseMDHDLanguage(r, mdhd); err == nil {
+			info.addAudioLanguage(lang)
+		}
+	}
+	return nil
+}
+
+func mp4SampleEntry(r io.ReadSeeker, mdia mp4Box) (mp4Box, error) {
+	box := mdia
+	for _, kind := range []string{"minf", "stbl", "stsd"} {
+		child, err := findMP4Box(r, box.payloadPos, box.payloadPos+box.payloadLen, kind)
+		if err != nil {
+			return mp4Box{}, err
+		}
+		box = child
+	}
+	// stsd: version+flags (4), entry count (4), then the sample entries.
+	return readMP4Box(r, box.payloadPos+8, box.payloadPos+box.payloadLen)
+}
+
+// parseMDHDLanguage decodes the packed ISO 639-2 language code. QuickTime
+// files may store a Macintosh language number instead, which is ignored.
+func parseMDHDLanguage(r io.ReadSeeker, box mp4Box) (string, error) {
+	version := make([]byte, 1)
+	if err := readAt(r, box.payloadPos, version); err != nil {
+		return "", err
+	}
+	offset := int64(20)
+	if version[0] == 1 {
+		offset = 32
+	}
+	buf := make([]byte, 2)
+	if err := readAt(r, box.payloadPos+offset, buf); err != nil {
+		return "", err
+	}
+	packed := binary.BigEndian.Uint16(buf)
+	if packed < 0x400 {
+		return "", errors.New("mp4: macintosh language code")
+	}
+	lang := []byte{
+		byte(packed>>10&0x1F) + 0x60,
+		byte(packed>>5&0x1F) + 0x60,
+		byte(packed&0x1F) + 0x60,
+	}
+	return string(lang), nil
+}
+
+func parseMVHD(r io.ReadSeeker, box mp4Box) (time.Duration, error) {
+	version := make([]byte, 1)
+	if err := readAt(r, box.payloadPos, version); err != nil {
+		return 0, err
+	}
+	var timescale, units uint64
+	if version[0] == 1 {
+		buf := make([]byte, 12)
+		// version+flags (4), creation (8), modification (8)
+		if err := readAt(r, box.payloadPos+20, buf); err != nil {
+			return 0, err
+		}
+		timescale = uint64(binary.BigEndian.Uint32(buf[0:4]))
+		units = binary.BigEndian.Uint64(buf[4:12])
+	} else {
+		buf := make([]byte, 8)
+		// version+flags (4), creation (4), modification (4)
+		if err := readAt(r, box.payloadPos+12, buf); err != nil {
+			return 0, err
+		}
+		timescale = uint64(binary.BigEndian.Uint32(buf[0:4]))
+		units = uint64(binary.BigEndian.Uint32(buf[4:8]))
+	}
+	if timescale == 0 {
+		return 0, errors.New("mp4: zero timescale")
+	}
+	return scaleDuration(units, timescale), nil
+}
+
+func scaleDuration(units, timescale uint64) time.Duration {
+	return time.Duration(float64(units) / float64(timescale) * float64(time.Second))
+}
+
+func readAt(r io.ReadSeeker, pos int64, buf []byte) error {
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return err
+	}
+	_, err := io.ReadFull(r, buf)
+	return err
+}
@@ -0,0 +1,111 @@
+package probe
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// ErrUnsupported reports a container format the native parsers do not
+// understand. Callers are expected to fall back to an external prober.
+var ErrUnsupported = errors.New("unsupported container format")
+
+// Info describes the media properties read from a container header. Fields
+// the container does not declare are left at their zero value.
+type Info struct {
+	Duration   time.Duration
+	Width      int
+	Height     int
+	VideoCodec string
+	AudioCodec string
+	// AudioLanguages lists the distinct language codes of the audio tracks
+	// in track order, typically ISO 639-2 ("eng", "deu").
+	AudioLanguages []string
+	// Bitrate is the overall bitrate in bits per second.
+	Bitrate int64
+}
+
+func (info *Info) addAudioLanguage(lang string) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" || lang == "und" {
+		return
+	}
+	for _, existing := range info.AudioLanguages {
+		if existing == lang {
+			return
+		}
+	}
+	info.AudioLanguages = append(info.AudioLanguages, lang)
+}
+
+// Probe reads the container header of the file at path. MP4/M4V/MOV,
+// Matroska/WebM and AVI files are supported; anything else yields
+// ErrUnsupported.
+func Probe(path string) (Info, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Info{}, err
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		return Info{}, err
+	}
+	return probeReader(f, stat.Size())
+}
+
+func probeReader(r io.ReadSeeker, size int64) (Info, error) {
+	head := make([]byte, 12)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return Info{}, fmt.Errorf("read header: %w", err)
+	}
+	head = head[:n]
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return Info{}, err
+	}
+	var info Info
+	switch {
+	case isMatroska(head):
+		info, err = probeMatroska(r, size)
+	case isAVI(head):
+		info, err = probeAVI(r, size)
+	case isMP4(head):
+		info, err = probeMP4(r, size)
+	default:
+		return Info{}, ErrUnsupported
+	}
+	if err != nil {
+		return Info{}, err
+	}
+	if info.Duration <= 0 {
+		return Info{}, errors.New("container does not declare a duration")
+	}
+	if info.Bitrate == 0 && size > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration.Seconds())
+	}
+	return info, nil
+}
+
+func isMatroska(head []byte) bool {
+	return bytes.HasPrefix(head, []byte{0x1A, 0x45, 0xDF, 0xA3})
+}
+
+func isAVI(head []byte) bool {
+	return len(head) >= 12 && string(head[0:4]) == "RIFF" && string(head[8:12]) == "AVI "
+}
+
+func isMP4(head []byte) bool {
+	if len(head) < 8 {
+		return false
+	}
+	switch string(head[4:8]) {
+	case "ftyp", "moov", "mdat", "free", "skip", "wide", "pnot":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,386 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func box(kind string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(out[0:4], uint32(8+len(body)))
+	copy(out[4:8], kind)
+	return append(out, body...)
+}
+
+func mvhdV0(timescale, duration uint32) []byte {
+	payload := make([]byte, 100)
+	binary.BigEndian.PutUint32(payload[12:16], timescale)
+	binary.BigEndian.PutUint32(payload[16:20], duration)
+	return box("mvhd", payload)
+}
+
+func mvhdV1(timescale uint32, duration uint64) []byte {
+	payload := make([]byte, 112)
+	payload[0] = 1
+	binary.BigEndian.PutUint32(payload[20:24], timescale)
+	binary.BigEndian.PutUint64(payload[24:32], duration)
+	return box("mvhd", payload)
+}
+
+func ebml(id uint32, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	var out []byte
+	switch {
+	case id > 0xFFFFFF:
+		out = binary.BigEndian.AppendUint32(out, id)
+	case id > 0xFFFF:
+		out = append(out, byte(id>>16), byte(id>>8), byte(id))
+	case id > 0xFF:
+		out = append(out, byte(id>>8), byte(id))
+	default:
+		out = append(out, byte(id))
+	}
+	// Always use an 8-byte size to exercise the widest vint form.
+	size := make([]byte, 8)
+	binary.BigEndian.PutUint64(size, uint64(len(body)))
+	size[0] = 0x01
+	out = append(out, size...)
+	return append(out, body...)
+}
+
+func ebmlFloat64(id uint32, value float64) []byte {
+	return ebml(id, binary.BigEndian.AppendUint64(nil, math.Float64bits(value)))
+}
+
+func ebmlFloat32(id uint32, value float32) []byte {
+	return ebml(id, binary.BigEndian.AppendUint32(nil, math.Float32bits(value)))
+}
+
+func riff(id string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	out := []byte(id)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	return out
+}
+
+func riffList(kind string, children ...[]byte) []byte {
+	return riff("LIST", append([][]byte{[]byte(kind)}, children...)...)
+}
+
+func avih(microSecPerFrame, totalFrames uint32) []byte {
+	payload := make([]byte, 56)
+	binary.LittleEndian.PutUint32(payload[0:4], microSecPerFrame)
+	binary.LittleEndian.PutUint32(payload[16:20], totalFrames)
+	return riff("avih", payload)
+}
+
+func writeFixture(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func TestProbeMP4(t *testing.T) {
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("isom\x00\x00\x02\x00isomiso2mp41")),
+		box("mdat", make([]byte, 64)),
+		box("moov", mvhdV0(1000, 90500), box("trak")),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.mp4", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 90500*time.Millisecond {
+		t.Fatalf("expected 1m30.5s, got %v", info.Duration)
+	}
+}
+
+func TestProbeMOVVersion1WithoutFtyp(t *testing.T) {
+	data := bytes.Join([][]byte{
+		box("wide"),
+		box("moov", mvhdV1(600, 600*3600)),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.mov", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != time.Hour {
+		t.Fatalf("expected 1h, got %v", info.Duration)
+	}
+}
+
+func TestProbeMP4LargeSizeBox(t *testing.T) {
+	mdat := make([]byte, 16+8)
+	binary.BigEndian.PutUint32(mdat[0:4], 1)
+	copy(mdat[4:8], "mdat")
+	binary.BigEndian.PutUint64(mdat[8:16], uint64(len(mdat)))
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("M4V ")),
+		mdat,
+		box("moov", mvhdV0(90000, 90000*42)),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.m4v", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 42*time.Second {
+		t.Fatalf("expected 42s, got %v", info.Duration)
+	}
+}
+
+func TestProbeMP4MissingMoov(t *testing.T) {
+	data := box("ftyp", []byte("isom"))
+	if _, err := Probe(writeFixture(t, "clip.mp4", data)); err == nil {
+		t.Fatalf("expected error for missing moov")
+	}
+}
+
+func TestProbeMatroska(t *testing.T) {
+	data := bytes.Join([][]byte{
+		ebml(ebmlIDHeader, ebml(0x4282, []byte("matroska"))),
+		ebml(ebmlIDSegment,
+			ebml(0x114D9B74, make([]byte, 12)),
+			ebml(ebmlIDInfo,
+				ebml(ebmlIDTimecodeScale, []byte{0x0F, 0x42, 0x40}),
+				ebmlFloat64(ebmlIDDuration, 125000),
+			),
+			ebml(ebmlIDCluster, make([]byte, 16)),
+		),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.mkv", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 125*time.Second {
+		t.Fatalf("expected 2m5s, got %v", info.Duration)
+	}
+}
+
+func TestProbeWebMDefaultScaleAndFloat32(t *testing.T) {
+	data := bytes.Join([][]byte{
+		ebml(ebmlIDHeader, ebml(0x4282, []byte("webm"))),
+		ebml(ebmlIDSegment, ebml(ebmlIDInfo, ebmlFloat32(ebmlIDDuration, 1500))),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.webm", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 1500*time.Millisecond {
+		t.Fatalf("expected 1.5s, got %v", info.Duration)
+	}
+}
+
+func TestProbeMatroskaWithoutDuration(t *testing.T) {
+	data := bytes.Join([][]byte{
+		ebml(ebmlIDHeader),
+		ebml(ebmlIDSegment, ebml(ebmlIDInfo, ebml(ebmlIDTimecodeScale, []byte{0x0F, 0x42, 0x40}))),
+	}, nil)
+	if _, err := Probe(writeFixture(t, "live.webm", data)); err == nil {
+		t.Fatalf("expected error when duration is missing")
+	}
+}
+
+func TestProbeAVI(t *testing.T) {
+	data := riff("RIFF", []byte("AVI "),
+		riffList("hdrl", avih(40000, 1500), riffList("strl", riff("strh", make([]byte, 56)))),
+		riffList("movi", riff("00dc", []byte{1, 2, 3})),
+	)
+	info, err := Probe(writeFixture(t, "clip.avi", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != time.Minute {
+		t.Fatalf("expected 1m, got %v", info.Duration)
+	}
+}
+
+func TestProbeAVIPrefersOpenDMLFrameCount(t *testing.T) {
+	data := riff("RIFF", []byte("AVI "),
+		riffList("hdrl", avih(40000, 100), riffList("odml", riff("dmlh", binary.LittleEndian.AppendUint32(make([]byte, 0, 248), 3000)))),
+	)
+	info, err := Probe(writeFixture(t, "big.avi", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 2*time.Minute {
+		t.Fatalf("expected 2m, got %v", info.Duration)
+	}
+}
+
+func TestProbeUnsupported(t *testing.T) {
+	_, err := Probe(writeFixture(t, "clip.wmv", []byte{0x30, 0x26, 0xB2, 0x75, 0x8E, 0x66, 0xCF, 0x11}))
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestProbeMissingFile(t *testing.T) {
+	if _, err := Probe(filepath.Join(t.TempDir(), "missing.mp4")); !os.IsNotExist(err) {
+		t.Fatalf("expected not-exist error, got %v", err)
+	}
+}
+
+func TestProbeTruncatedHeader(t *testing.T) {
+	data := box("moov", mvhdV0(1000, 1000))
+	if _, err := Probe(writeFixture(t, "cut.mp4", data[:20])); err == nil {
+		t.Fatalf("expected error for truncated file")
+	}
+}
+
+func mp4Track(handler, codec string, width, height uint16, lang string) []byte {
+	hdlr := make([]byte, 24)
+	copy(hdlr[8:12], handler)
+	mdhd := make([]byte, 24)
+	if lang != "" {
+		packed := uint16(lang[0]-0x60)<<10 | uint16(lang[1]-0x60)<<5 | uint16(lang[2]-0x60)
+		binary.BigEndian.PutUint16(mdhd[20:22], packed)
+	}
+	entry := make([]byte, 78)
+	binary.BigEndian.PutUint16(entry[24:26], width)
+	binary.BigEndian.PutUint16(entry[26:28], height)
+	stsd := append(make([]byte, 8), box(codec, entry)...)
+	binary.BigEndian.PutUint32(stsd[4:8], 1)
+	return box("trak", box("tkhd", make([]byte, 84)), box("mdia",
+		box("mdhd", mdhd),
+		box("hdlr", hdlr),
+		box("minf", box("stbl", box("stsd", stsd))),
+	))
+}
+
+func TestProbeMP4Tracks(t *testing.T) {
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("isom")),
+		box("moov",
+			mvhdV0(1000, 10000),
+			mp4Track("vide", "avc1", 1920, 1080, "und"),
+			mp4Track("soun", "mp4a", 0, 0, "eng"),
+			mp4Track("soun", "ac-3", 0, 0, "deu"),
+			mp4Track("soun", "mp4a", 0, 0, "eng"),
+		),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.mp4", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Width != 1920 || info.Height != 1080 {
+		t.Fatalf("expected 1920x1080, got %dx%d", info.Width, info.Height)
+	}
+	if info.VideoCodec != "h264" || info.AudioCodec != "aac" {
+		t.Fatalf("unexpected codecs %q/%q", info.VideoCodec, info.AudioCodec)
+	}
+	if len(info.AudioLanguages) != 2 || info.AudioLanguages[0] != "eng" || info.AudioLanguages[1] != "deu" {
+		t.Fatalf("unexpected languages %v", info.AudioLanguages)
+	}
+	if want := int64(len(data)) * 8 / 10; info.Bitrate != want {
+		t.Fatalf("expected bitrate %d, got %d", want, info.Bitrate)
+	}
+}
+
+func TestProbeMatroskaTracks(t *testing.T) {
+	data := bytes.Join([][]byte{
+		ebml(ebmlIDHeader),
+		ebml(ebmlIDSegment,
+			ebml(ebmlIDInfo, ebmlFloat64(ebmlIDDuration, 60000)),
+			ebml(ebmlIDTracks,
+				ebml(ebmlIDTrackEntry,
+					ebml(ebmlIDTrackType, []byte{matroskaTrackVideo}),
+					ebml(ebmlIDCodecID, []byte("V_VP9")),
+					ebml(ebmlIDVideo,
+						ebml(ebmlIDPixelWidth, []byte{0x05, 0x00}),
+						ebml(ebmlIDPixelHeight, []byte{0x02, 0xD0}),
+					),
+				),
+				ebml(ebmlIDTrackEntry,
+					ebml(ebmlIDTrackType, []byte{matroskaTrackAudio}),
+					ebml(ebmlIDCodecID, []byte("A_AAC/MPEG4/LC")),
+				),
+				ebml(ebmlIDTrackEntry,
+					ebml(ebmlIDTrackType, []byte{matroskaTrackAudio}),
+					ebml(ebmlIDCodecID, []byte("A_OPUS")),
+					ebml(ebmlIDLanguage, []byte("fre")),
+				),
+			),
+		),
+	}, nil)
+	info, err := Probe(writeFixture(t, "clip.mkv", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != time.Minute || info.Width != 1280 || info.Height != 720 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if info.VideoCodec != "vp9" || info.AudioCodec != "aac" {
+		t.Fatalf("unexpected codecs %q/%q", info.VideoCodec, info.AudioCodec)
+	}
+	if len(info.AudioLanguages) != 2 || info.AudioLanguages[0] != "eng" || info.AudioLanguages[1] != "fre" {
+		t.Fatalf("unexpected languages %v", info.AudioLanguages)
+	}
+}
+
+func TestProbeAVIStreams(t *testing.T) {
+	header := make([]byte, 56)
+	binary.LittleEndian.PutUint32(header[0:4], 40000)
+	binary.LittleEndian.PutUint32(header[16:20], 250)
+	binary.LittleEndian.PutUint32(header[32:36], 640)
+	binary.LittleEndian.PutUint32(header[36:40], 480)
+	bitmap := make([]byte, 40)
+	copy(bitmap[16:20], "XVID")
+	wave := make([]byte, 18)
+	binary.LittleEndian.PutUint16(wave[0:2], 0x55)
+	data := riff("RIFF", []byte("AVI "),
+		riffList("hdrl",
+			riff("avih", header),
+			riffList("strl", riff("strh", append([]byte("vidsXVID"), make([]byte, 48)...)), riff("strf", bitmap)),
+			riffList("strl", riff("strh", append([]byte("auds"), make([]byte, 52)...)), riff("strf", wave)),
+		),
+	)
+	info, err := Probe(writeFixture(t, "clip.avi", data))
+	if err != nil {
+		t.Fatalf("Probe: %v", err)
+	}
+	if info.Duration != 10*time.Second || info.Width != 640 || info.Height != 480 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if info.VideoCodec != "mpeg4" || info.AudioCodec != "mp3" {
+		t.Fatalf("unexpected codecs %q/%q", info.VideoCodec, info.AudioCodec)
+	}
+}
+
+func TestParseFFprobeOutput(t *testing.T) {
+	out := []byte(`{"streams":[{"codec_type":"video","codec_name":"wmv3","width":854,"height":480},` +
+		`{"codec_type":"audio","codec_name":"wmav2","tags":{"language":"ENG"}}],` +
+		`"format":{"duration":"12.500000","bit_rate":"800000"}}`)
+	info, err := parseFFprobeOutput(out)
+	if err != nil {
+		t.Fatalf("parseFFprobeOutput: %v", err)
+	}
+	if info.Duration != 12500*time.Millisecond || info.Bitrate != 800000 {
+		t.Fatalf("unexpected info %+v", info)
+	}
+	if info.Width != 854 || info.Height != 480 || info.VideoCodec != "wmv3" || info.AudioCodec != "wmav2" {
+		t.Fatalf("unexpected streams %+v", info)
+	}
+	if len(info.AudioLanguages) != 1 || info.AudioLanguages[0] != "eng" {
+		t.Fatalf("unexpected languages %v", info.AudioLanguages)
+	}
+}
+
+func TestParseFFprobeOutputMissingDuration(t *testing.T) {
+	if _, err := parseFFprobeOutput([]byte(`{"format":{"duration":"N/A"}}`)); err == nil {
+		t.Fatalf("expected error for missing duration")
+	}
+}
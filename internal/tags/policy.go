@@ -0,0 +1,74 @@
+package tags
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// PolicyFile is the name of the tag policy file in the library root.
+const PolicyFile = ".video_tag_policy.json"
+
+// Policy normalises tags across the library when they are saved. The zero
+// value keeps tags as typed.
+type Policy struct {
+	// FoldCase lower-cases every tag.
+	FoldCase bool `json:"fold_case,omitempty"`
+	// Aliases maps tags to their canonical form, such as "hip" to "hips".
+	// Keys match regardless of case.
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+// LoadPolicy reads a policy file. A missing file yields the zero policy.
+func LoadPolicy(path string) (Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return Policy{}, nil
+		}
+		return Policy{}, err
+	}
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return Policy{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	aliases := make(map[string]string, len(policy.Aliases))
+	for from, to := range policy.Aliases {
+		aliases[strings.ToLower(strings.TrimSpace(from))] = strings.TrimSpace(to)
+	}
+	policy.Aliases = aliases
+	return policy, nil
+}
+
+// Canonical returns the form of tag the policy stores.
+func (p Policy) Canonical(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if alias, ok := p.Aliases[strings.ToLower(tag)]; ok && alias != "" {
+		tag = alias
+	}
+	if p.FoldCase {
+		tag = strings.ToLower(tag)
+	}
+	return tag
+}
+
+// Normalize maps every tag to its canonical form and drops the duplicates
+// this creates, keeping the order of first occurrence.
+func (p Policy) Normalize(values []string) []string {
+	var out []string
+	seen := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		tag := p.Canonical(value)
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		out = append(out, tag)
+	}
+	return out
+}
@@ -1,14 +1,44 @@
 package tags
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 )
 
+// SchemaVersion is the version written to sidecar files. Version 0 is the
+// legacy form, a plain JSON array of tags.
+const SchemaVersion = 1
+
+// MaxLevel is the upper bound of the difficulty, intensity and rating scales.
+const MaxLevel = 5
+
+// Metadata is the content of a video's sidecar file.
+type Metadata struct {
+	Version int      `json:"version"`
+	Tags    []string `json:"tags"`
+	Details
+}
+
+// Details holds the structured fields besides the tag list. Zero values mean
+// "not set"; the levels range from 1 to MaxLevel.
+type Details struct {
+	Instructor string   `json:"instructor,omitempty"`
+	Style      string   `json:"style,omitempty"`
+	Difficulty int      `json:"difficulty,omitempty"`
+	Focus      []string `json:"focus,omitempty"`
+	Props      []string `json:"props,omitempty"`
+	Intensity  int      `json:"intensity,omitempty"`
+	Rating     int      `json:"rating,omitempty"`
+	Favorite   bool     `json:"favorite,omitempty"`
+	Notes      string   `json:"notes,omitempty"`
+}
+
 // PathFor returns the path to the tag metadata file for the given video path.
 func PathFor(videoPath string) string {
 	ext := filepath.Ext(videoPath)
@@ -20,30 +50,100 @@ func PathFor(videoPath string) string {
 
 // Load reads the tags associated with a video. Missing files yield an empty slice.
 func Load(videoPath string) ([]string, error) {
-	metadataPath := PathFor(videoPath)
-	data, err := os.ReadFile(metadataPath)
+	md, err := LoadMetadata(videoPath)
+	if err != nil {
+		return nil, err
+	}
+	return md.Tags, nil
+}
+
+// errUnsupportedVersion marks sidecars written by a newer schema.
+var errUnsupportedVersion = errors.New("unsupported sidecar version")
+
+// Save persists the tags for a video to its metadata file, keeping the
+// other fields already stored there. A sidecar that does not parse is
+// replaced, as before the versioned schema; one that cannot be read or was
+// written by a newer version is left alone.
+func Save(videoPath string, tagValues []string) error {
+	md, err := LoadMetadata(videoPath)
+	var pathErr *os.PathError
+	switch {
+	case err == nil:
+	case errors.As(err, &pathErr), errors.Is(err, errUnsupportedVersion):
+		return err
+	default:
+		md = Metadata{}
+	}
+	md.Tags = tagValues
+	return SaveMetadata(videoPath, md)
+}
+
+// LoadMetadata reads a sidecar in either the legacy array form or the
+// versioned object form. Missing files yield empty metadata.
+func LoadMetadata(videoPath string) (Metadata, error) {
+	data, err := os.ReadFile(PathFor(videoPath))
 	if err != nil {
 		if errors.Is(err, os.ErrNotExist) {
-			return nil, nil
+			return Metadata{}, nil
 		}
-		return nil, err
+		return Metadata{}, err
 	}
-	var parsed []string
-	if err := json.Unmarshal(data, &parsed); err != nil {
-		return nil, err
+	return parseMetadata(data)
+}
+
+func parseMetadata(data []byte) (Metadata, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		var legacy []string
+		if err := json.Unmarshal(trimmed, &legacy); err != nil {
+			return Metadata{}, err
+		}
+		return Metadata{Tags: sanitize(legacy)}, nil
+	}
+	var md Metadata
+	if err := json.Unmarshal(trimmed, &md); err != nil {
+		return Metadata{}, err
+	}
+	if md.Version > SchemaVersion {
+		return Metadata{}, fmt.Errorf("%w %d", errUnsupportedVersion, md.Version)
 	}
-	return sanitize(parsed), nil
+	md.Tags = sanitize(md.Tags)
+	md.Focus = sanitize(md.Focus)
+	md.Props = sanitize(md.Props)
+	return md, nil
 }
 
-// Save persists the tags for a video to its metadata file.
-func Save(videoPath string, tagValues []string) error {
-	metadataPath := PathFor(videoPath)
-	cleaned := sanitize(tagValues)
-	payload, err := json.MarshalIndent(cleaned, "", "  ")
+// SaveMetadata validates md and writes it in the current schema.
+func SaveMetadata(videoPath string, md Metadata) error {
+	if err := md.Validate(); err != nil {
+		return err
+	}
+	md.Version = SchemaVersion
+	md.Tags = sanitize(md.Tags)
+	md.Focus = sanitize(md.Focus)
+	md.Props = sanitize(md.Props)
+	md.Instructor = strings.TrimSpace(md.Instructor)
+	md.Style = strings.TrimSpace(md.Style)
+	md.Notes = strings.TrimSpace(md.Notes)
+	payload, err := json.MarshalIndent(md, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(metadataPath, payload, 0o644)
+	return os.WriteFile(PathFor(videoPath), payload, 0o644)
+}
+
+// Validate checks that the levels are within 0 (unset) and MaxLevel.
+func (d Details) Validate() error {
+	levels := []struct {
+		name  string
+		value int
+	}{{"difficulty", d.Difficulty}, {"intensity", d.Intensity}, {"rating", d.Rating}}
+	for _, level := range levels {
+		if level.value < 0 || level.value > MaxLevel {
+			return fmt.Errorf("%s must be between 0 (unset) and %d", level.name, MaxLevel)
+		}
+	}
+	return nil
 }
 
 func sanitize(raw []string) []string {
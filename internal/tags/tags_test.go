@@ -45,3 +45,133 @@ func TestLoadMissingFile(t *testing.T) {
 		t.Fatalf("expected nil tags for missing file, got %v", tags)
 	}
 }
+
+func TestLoadMetadataReadsLegacyArray(t *testing.T) {
+	dir := t.TempDir()
+	videoPath := filepath.Join(dir, "clip.mp4")
+	if err := os.WriteFile(PathFor(videoPath), []byte(`["yin", " hips "]`), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	md, err := LoadMetadata(videoPath)
+	if err != nil {
+		t.Fatalf("LoadMetadata: %v", err)
+	}
+	if md.Version != 0 || len(md.Tags) != 2 || md.Tags[0] != "hips" {
+		t.Fatalf("unexpected legacy metadata %+v", md)
+	}
+}
+
+func TestSaveMetadataRoundTrip(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "clip.mp4")
+	in := Metadata{
+		Tags: []string{"hips"},
+		Details: Details{
+			Instructor: " Ada ", Style: "yin", Difficulty: 2, Focus: []string{"hips", "back"},
+			Props: []string{"block"}, Intensity: 1, Rating: 5, Notes: "knee friendly",
+		},
+	}
+	if err := SaveMetadata(videoPath, in); err != nil {
+		t.Fatalf("SaveMetadata: %v", err)
+	}
+	md, err := LoadMetadata(videoPath)
+	if err != nil {
+		t.Fatalf("LoadMetadata: %v", err)
+	}
+	if md.Version != SchemaVersion || md.Instructor != "Ada" || md.Difficulty != 2 || md.Rating != 5 {
+		t.Fatalf("unexpected metadata %+v", md)
+	}
+	if len(md.Focus) != 2 || md.Focus[0] != "back" || md.Props[0] != "block" || md.Notes != "knee friendly" {
+		t.Fatalf("unexpected lists %+v", md)
+	}
+}
+
+func TestSaveKeepsDetails(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := SaveMetadata(videoPath, Metadata{Tags: []string{"old"}, Details: Details{Rating: 4}}); err != nil {
+		t.Fatalf("SaveMetadata: %v", err)
+	}
+	if err := Save(videoPath, []string{"new"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	md, err := LoadMetadata(videoPath)
+	if err != nil {
+		t.Fatalf("LoadMetadata: %v", err)
+	}
+	if len(md.Tags) != 1 || md.Tags[0] != "new" || md.Rating != 4 {
+		t.Fatalf("expected tags replaced and rating kept, got %+v", md)
+	}
+}
+
+func TestSaveReplacesCorruptSidecar(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(PathFor(videoPath), []byte(`{"tags": ["hips",`), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	if err := Save(videoPath, []string{"hips"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	md, err := LoadMetadata(videoPath)
+	if err != nil || len(md.Tags) != 1 || md.Tags[0] != "hips" {
+		t.Fatalf("expected the sidecar repaired, got %+v (%v)", md, err)
+	}
+
+	if err := os.WriteFile(PathFor(videoPath), []byte(`{"version": 99, "tags": []}`), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	if err := Save(videoPath, []string{"hips"}); err == nil {
+		t.Fatal("expected a newer sidecar to be kept")
+	}
+}
+
+func TestLoadMetadataRejectsNewerVersion(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "clip.mp4")
+	if err := os.WriteFile(PathFor(videoPath), []byte(`{"version": 99, "tags": []}`), 0o644); err != nil {
+		t.Fatalf("write sidecar: %v", err)
+	}
+	if _, err := LoadMetadata(videoPath); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+}
+
+func TestSaveMetadataValidatesLevels(t *testing.T) {
+	videoPath := filepath.Join(t.TempDir(), "clip.mp4")
+	err := SaveMetadata(videoPath, Metadata{Details: Details{Intensity: 6}})
+	if err == nil || err.Error() != "intensity must be between 0 (unset) and 5" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if _, err := os.Stat(PathFor(videoPath)); !os.IsNotExist(err) {
+		t.Fatal("expected no sidecar for invalid metadata")
+	}
+}
+
+func TestPolicyNormalize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), PolicyFile)
+	if err := os.WriteFile(path, []byte(`{"fold_case": true, "aliases": {"Hip": "hips"}}`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	policy, err := LoadPolicy(path)
+	if err != nil {
+		t.Fatalf("LoadPolicy: %v", err)
+	}
+	got := policy.Normalize([]string{"HIP", "Hips", "Morning", " "})
+	if len(got) != 2 || got[0] != "hips" || got[1] != "morning" {
+		t.Fatalf("unexpected normalized tags %v", got)
+	}
+	if got := (Policy{}).Normalize([]string{"Hip", "hip"}); len(got) != 2 {
+		t.Fatalf("expected zero policy to keep tags, got %v", got)
+	}
+}
+
+func TestLoadPolicyMissingAndInvalid(t *testing.T) {
+	dir := t.TempDir()
+	if policy, err := LoadPolicy(filepath.Join(dir, PolicyFile)); err != nil || policy.FoldCase {
+		t.Fatalf("expected zero policy for missing file, got %+v (%v)", policy, err)
+	}
+	path := filepath.Join(dir, "broken.json")
+	if err := os.WriteFile(path, []byte(`{`), 0o644); err != nil {
+		t.Fatalf("write policy: %v", err)
+	}
+	if _, err := LoadPolicy(path); err == nil {
+		t.Fatal("expected parse error")
+	}
+}